@@ -0,0 +1,175 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vikas-gouda/go-restraunt-mangement/config"
+	"github.com/vikas-gouda/go-restraunt-mangement/database"
+	"github.com/vikas-gouda/go-restraunt-mangement/events"
+	"github.com/vikas-gouda/go-restraunt-mangement/gateway"
+	"github.com/vikas-gouda/go-restraunt-mangement/gateway/mock"
+	"github.com/vikas-gouda/go-restraunt-mangement/helpers"
+	"github.com/vikas-gouda/go-restraunt-mangement/middleware"
+	"github.com/vikas-gouda/go-restraunt-mangement/printing"
+	"github.com/vikas-gouda/go-restraunt-mangement/routes"
+	"github.com/vikas-gouda/go-restraunt-mangement/store"
+	"github.com/vikas-gouda/go-restraunt-mangement/store/memstore"
+	"github.com/vikas-gouda/go-restraunt-mangement/store/mongostore"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// App holds everything the server needs so it can be started and stopped
+// explicitly instead of through package level state.
+type App struct {
+	Config config.Config
+	Client *mongo.Client
+	Store  *store.Store
+	Events *events.Broker
+	// Payments is nil when no payment provider is configured.
+	Payments gateway.Provider
+	Printing *printing.Queue
+	Router   *gin.Engine
+	Server   *http.Server
+}
+
+func New(cfg config.Config) (*App, error) {
+	a := &App{Config: cfg}
+
+	helpers.SECRET_KEY = cfg.Secret_key
+
+	if err := a.openStore(); err != nil {
+		return nil, err
+	}
+
+	a.Events = events.NewBroker(cfg.Event_buffer)
+
+	if cfg.Payment_provider == config.PaymentProviderMock {
+		log.Println("Using the mock payment provider")
+		a.Payments = gateway.WithTimeout(mock.New(cfg.Payment_webhook_secret), cfg.Payment_timeout)
+	}
+
+	a.Printing = printing.NewQueue(cfg.Print_networks.Send, cfg.Print_attempts, 2*time.Second, cfg.Print_timeout)
+
+	a.Router = a.routes()
+	a.Server = &http.Server{
+		Addr:         ":" + cfg.Port,
+		Handler:      a.Router,
+		ReadTimeout:  cfg.Read_timeout,
+		WriteTimeout: cfg.Write_timeout,
+	}
+
+	return a, nil
+}
+
+func (a *App) openStore() error {
+	if a.Config.Store == config.StoreMemory {
+		log.Println("Using the in-memory store")
+		a.Store = memstore.New()
+		return nil
+	}
+
+	var c, cancel = context.WithTimeout(context.Background(), a.Config.DB_timeout)
+	defer cancel()
+
+	client, err := database.DBinstance(c, a.Config.DB_uri)
+	if err != nil {
+		return err
+	}
+
+	s, err := mongostore.New(c, client.Database(a.Config.DB_name))
+	if err != nil {
+		client.Disconnect(context.Background())
+		return err
+	}
+
+	a.Client = client
+	a.Store = s
+
+	return nil
+}
+
+func (a *App) routes() *gin.Engine {
+	router := gin.New()
+	router.Use(gin.Logger())
+	router.Use(gin.Recovery())
+
+	routes.HealthRoutes(router, a.Store)
+	routes.UserRoutes(router, a.Store)
+	routes.WebhookRoutes(router, a.Store, a.Payments)
+	routes.KitchenRoutes(router, a.Store, a.Events)
+	router.Use(middleware.Authentication(a.Store.Sessions))
+	router.Use(middleware.Idempotency(a.Store.Idempotency, a.Config.Idempotency_ttl))
+
+	routes.RestaurantRoutes(router, a.Store)
+	routes.FoodRoutes(router, a.Store)
+	routes.MenuRoutes(router, a.Store)
+	routes.TableRoutes(router, a.Store)
+	routes.OrderRoutes(router, a.Store, a.Events)
+	routes.OrderItemRoutes(router, a.Store, a.Events, a.Printing)
+	routes.InvoiceRoutes(router, a.Store, a.Payments)
+	routes.TaxRoutes(router, a.Store)
+	routes.PromotionRoutes(router, a.Store)
+	routes.ShiftRoutes(router, a.Store)
+	routes.ReportRoutes(router, a.Store)
+	routes.PrinterRoutes(router, a.Store, a.Printing, a.Config.Print_networks)
+
+	return router
+}
+
+// Run serves until the process gets SIGINT or SIGTERM, then drains the
+// in-flight requests and closes the database connection.
+func (a *App) Run() error {
+	stop, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		log.Printf("Listening on %s", a.Server.Addr)
+		serveErr <- a.Server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		a.Close(context.Background())
+		return err
+	case <-stop.Done():
+		log.Println("Shutting down")
+	}
+
+	var c, cancelShutdown = context.WithTimeout(context.Background(), a.Config.Shutdown_timeout)
+	defer cancelShutdown()
+
+	// Live feeds never finish on their own; end them so Shutdown can drain.
+	a.Events.Close()
+
+	err := a.Server.Shutdown(c)
+	if closeErr := a.Close(c); err == nil {
+		err = closeErr
+	}
+
+	if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return err
+}
+
+func (a *App) Close(c context.Context) error {
+	if a.Printing != nil {
+		a.Printing.Close()
+	}
+
+	if a.Client == nil {
+		return nil
+	}
+
+	return a.Client.Disconnect(c)
+}
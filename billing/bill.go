@@ -0,0 +1,89 @@
+package billing
+
+import (
+	"github.com/vikas-gouda/go-restraunt-mangement/models"
+	"github.com/vikas-gouda/go-restraunt-mangement/money"
+)
+
+// Bill is what one invoice asks for: its lines, the discounts on them and
+// the tax breakdown of what is left.
+type Bill struct {
+	Lines       []models.OrderLine
+	Items_total money.Money
+	Discounts   []models.DiscountLine
+	Discount    money.Money
+	models.TaxBreakdown
+	Gratuity_basis_points int64
+	Gratuity              money.Money
+}
+
+// Compute bills the lines include accepts; nil includes every line.
+// Discounts are worked out on the whole order first, so a check carries the
+// share of an order discount that falls on its items.
+func Compute(lines []models.OrderLine, discounts []models.OrderDiscount, rates []models.TaxRate, currency string, rounding money.Rounding, include func(models.OrderLine) bool) Bill {
+	partial := include != nil
+	if !partial {
+		include = func(models.OrderLine) bool { return true }
+	}
+
+	discounted, applied := Discounts(lines, discounts, currency, rounding)
+
+	bill := Bill{
+		Lines:       []models.OrderLine{},
+		Items_total: money.Zero(currency),
+		Discounts:   []models.DiscountLine{},
+		Discount:    money.Zero(currency),
+	}
+
+	billed := []models.OrderLine{}
+	for i, line := range lines {
+		if !include(line) {
+			continue
+		}
+
+		bill.Lines = append(bill.Lines, line)
+		billed = append(billed, discounted[i])
+		if line.Status != models.ItemVoided {
+			bill.Items_total = bill.Items_total.Add(line.Amount)
+		}
+	}
+
+	for _, discount := range applied {
+		line := discount.Line(lines, currency, include)
+		if partial && line.Amount.IsZero() {
+			continue
+		}
+
+		bill.Discounts = append(bill.Discounts, line)
+		bill.Discount = bill.Discount.Add(line.Amount)
+	}
+
+	// Discounts come off before tax, so tax is charged on what is paid.
+	bill.TaxBreakdown = Taxes(billed, rates, currency, rounding)
+	bill.Gratuity = money.Zero(currency)
+	return bill
+}
+
+// AddGratuity puts a service charge of basisPoints on what the items come to
+// after discounts. It is not taxed.
+func (bill *Bill) AddGratuity(basisPoints int64, rounding money.Rounding) {
+	bill.Gratuity_basis_points = basisPoints
+	bill.Gratuity = bill.Items_total.Sub(bill.Discount).Percent(basisPoints, rounding)
+	bill.Total = bill.Total.Add(bill.Gratuity)
+}
+
+// Shares is what the given shares of an even split come to. The total is
+// split to the minor unit, the first shares taking the units left over, so
+// the shares always add up to it.
+func Shares(total money.Money, parts int, shares []int) money.Money {
+	split := total.Split(parts)
+
+	due := money.Zero(total.Currency)
+	for _, share := range shares {
+		if share >= 1 && share <= len(split) {
+			due = due.Add(split[share-1])
+		}
+	}
+
+	return due
+}
@@ -0,0 +1,162 @@
+package billing
+
+import (
+	"sort"
+
+	"github.com/vikas-gouda/go-restraunt-mangement/models"
+	"github.com/vikas-gouda/go-restraunt-mangement/money"
+)
+
+// Discounts takes the discounts of an order off its lines. It returns the
+// lines with what is left to pay in Amount, ready for Taxes, and for every
+// discount what it takes off each line.
+//
+// Item discounts go first, then order discounts are shared out over what the
+// items still cost in proportion to it. No line ever drops below zero.
+func Discounts(lines []models.OrderLine, discounts []models.OrderDiscount, currency string, rounding money.Rounding) ([]models.OrderLine, []Discount) {
+	discounted := make([]models.OrderLine, len(lines))
+	copy(discounted, lines)
+
+	for i := range discounted {
+		if discounted[i].Status == models.ItemVoided {
+			discounted[i].Amount = money.Zero(currency)
+		}
+	}
+
+	ordered := []models.OrderDiscount{}
+	for _, scope := range []string{models.ScopeItem, models.ScopeOrder} {
+		for _, discount := range discounts {
+			if discount.Scope == scope {
+				ordered = append(ordered, discount)
+			}
+		}
+	}
+
+	applied := []Discount{}
+
+	for _, discount := range ordered {
+		var amounts []money.Money
+		if discount.Scope == models.ScopeItem {
+			amounts = itemDiscount(discounted, discount.DiscountRule, currency, rounding)
+		} else {
+			amounts = orderDiscount(discounted, discount.DiscountRule, currency, rounding)
+		}
+
+		for i, amount := range amounts {
+			if amount.Cmp(discounted[i].Amount) > 0 {
+				amounts[i] = discounted[i].Amount
+			}
+			discounted[i].Amount = discounted[i].Amount.Sub(amounts[i])
+		}
+
+		applied = append(applied, Discount{OrderDiscount: discount, Lines: amounts})
+	}
+
+	return discounted, applied
+}
+
+// Discount is an order discount with what it takes off every line.
+type Discount struct {
+	models.OrderDiscount
+	Lines []money.Money
+}
+
+// Line shows the discount as it takes off the lines include accepts.
+func (discount Discount) Line(lines []models.OrderLine, currency string, include func(models.OrderLine) bool) models.DiscountLine {
+	total := money.Zero(currency)
+	for i, amount := range discount.Lines {
+		if include(lines[i]) {
+			total = total.Add(amount)
+		}
+	}
+
+	return models.DiscountLine{
+		Discount_id:  discount.Discount_id,
+		Promotion_id: discount.Promotion_id,
+		Code:         discount.Code,
+		Name:         discount.Name,
+		Reason:       discount.Reason,
+		Amount:       total,
+	}
+}
+
+func itemDiscount(lines []models.OrderLine, rule models.DiscountRule, currency string, rounding money.Rounding) []money.Money {
+	amounts := make([]money.Money, len(lines))
+	for i := range amounts {
+		amounts[i] = money.Zero(currency)
+	}
+
+	if rule.Kind == models.DiscountBuyXGetY {
+		return buyXGetY(lines, rule, amounts)
+	}
+
+	for i, line := range lines {
+		if line.Status == models.ItemVoided || !rule.Targets(line.Food_id) {
+			continue
+		}
+
+		switch rule.Kind {
+		case models.DiscountPercent:
+			amounts[i] = line.Amount.Percent(rule.Basis_points, rounding)
+		case models.DiscountAmount:
+			amounts[i] = rule.Amount.Mul(int64(line.Quantity))
+		}
+	}
+
+	return amounts
+}
+
+// buyXGetY lines up every unit of the targeted items from dear to cheap and
+// makes the last Free_quantity of every Buy_quantity + Free_quantity free.
+func buyXGetY(lines []models.OrderLine, rule models.DiscountRule, amounts []money.Money) []money.Money {
+	type unit struct {
+		line  int
+		price money.Money
+	}
+
+	units := []unit{}
+	for i, line := range lines {
+		if line.Status == models.ItemVoided || line.Quantity < 1 || !rule.Targets(line.Food_id) {
+			continue
+		}
+
+		price := line.Amount.Ratio(1, int64(line.Quantity), money.HalfUp)
+		for n := 0; n < line.Quantity; n++ {
+			units = append(units, unit{line: i, price: price})
+		}
+	}
+
+	sort.SliceStable(units, func(a, b int) bool { return units[a].price.Cmp(units[b].price) > 0 })
+
+	group := rule.Buy_quantity + rule.Free_quantity
+	for n, u := range units {
+		if n%group >= rule.Buy_quantity {
+			amounts[u.line] = amounts[u.line].Add(u.price)
+		}
+	}
+
+	return amounts
+}
+
+func orderDiscount(lines []models.OrderLine, rule models.DiscountRule, currency string, rounding money.Rounding) []money.Money {
+	weights := make([]int64, len(lines))
+	left := money.Zero(currency)
+	for i, line := range lines {
+		weights[i] = line.Amount.Amount
+		left = left.Add(line.Amount)
+	}
+
+	total := money.Zero(currency)
+	switch rule.Kind {
+	case models.DiscountPercent:
+		total = left.Percent(rule.Basis_points, rounding)
+	case models.DiscountAmount:
+		total = rule.Amount
+	}
+
+	if total.Cmp(left) > 0 {
+		total = left
+	}
+
+	return total.Allocate(weights)
+}
@@ -0,0 +1,125 @@
+package billing
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/vikas-gouda/go-restraunt-mangement/models"
+	"github.com/vikas-gouda/go-restraunt-mangement/money"
+)
+
+func TestDiscounts(t *testing.T) {
+	burger := models.OrderLine{Food_id: "burger", Quantity: 1, Amount: usd(1000)}
+	fries := models.OrderLine{Food_id: "fries", Quantity: 2, Amount: usd(600)}
+	voidedBurger := burger
+	voidedBurger.Status = models.ItemVoided
+
+	percent := func(scope string, basisPoints int64, foods ...string) models.OrderDiscount {
+		return models.OrderDiscount{DiscountRule: models.DiscountRule{Kind: models.DiscountPercent, Scope: scope, Basis_points: basisPoints, Food_ids: foods}}
+	}
+	amount := func(scope string, minor int64, foods ...string) models.OrderDiscount {
+		return models.OrderDiscount{DiscountRule: models.DiscountRule{Kind: models.DiscountAmount, Scope: scope, Amount: usd(minor), Food_ids: foods}}
+	}
+	buyOneGetOne := func(foods ...string) models.OrderDiscount {
+		return models.OrderDiscount{DiscountRule: models.DiscountRule{Kind: models.DiscountBuyXGetY, Scope: models.ScopeItem, Buy_quantity: 1, Free_quantity: 1, Food_ids: foods}}
+	}
+
+	for _, test := range []struct {
+		name      string
+		lines     []models.OrderLine
+		discounts []models.OrderDiscount
+		// taken is what every discount takes off each line, in the order
+		// they are applied.
+		taken [][]int64
+		left  []int64
+	}{
+		{
+			name:      "percent off an item",
+			lines:     []models.OrderLine{burger, fries},
+			discounts: []models.OrderDiscount{percent(models.ScopeItem, 1000, "burger")},
+			taken:     [][]int64{{100, 0}},
+			left:      []int64{900, 600},
+		},
+		{
+			name:      "amount off every unit",
+			lines:     []models.OrderLine{burger, fries},
+			discounts: []models.OrderDiscount{amount(models.ScopeItem, 50, "fries")},
+			taken:     [][]int64{{0, 100}},
+			left:      []int64{1000, 500},
+		},
+		{
+			name:      "item never below zero",
+			lines:     []models.OrderLine{burger, fries},
+			discounts: []models.OrderDiscount{amount(models.ScopeItem, 2000)},
+			taken:     [][]int64{{1000, 600}},
+			left:      []int64{0, 0},
+		},
+		{
+			name:      "second of a pair free",
+			lines:     []models.OrderLine{burger, fries},
+			discounts: []models.OrderDiscount{buyOneGetOne("fries")},
+			taken:     [][]int64{{0, 300}},
+			left:      []int64{1000, 300},
+		},
+		{
+			name:      "cheapest units are the free ones",
+			lines:     []models.OrderLine{burger, fries},
+			discounts: []models.OrderDiscount{buyOneGetOne()},
+			taken:     [][]int64{{0, 300}},
+			left:      []int64{1000, 300},
+		},
+		{
+			name:      "order percent shared by amount",
+			lines:     []models.OrderLine{burger, fries},
+			discounts: []models.OrderDiscount{percent(models.ScopeOrder, 1000)},
+			taken:     [][]int64{{100, 60}},
+			left:      []int64{900, 540},
+		},
+		{
+			name:      "order amount capped at the bill",
+			lines:     []models.OrderLine{burger, fries},
+			discounts: []models.OrderDiscount{amount(models.ScopeOrder, 2000)},
+			taken:     [][]int64{{1000, 600}},
+			left:      []int64{0, 0},
+		},
+		{
+			name:      "item discounts before order discounts",
+			lines:     []models.OrderLine{burger, fries},
+			discounts: []models.OrderDiscount{percent(models.ScopeOrder, 1000), percent(models.ScopeItem, 1000, "burger")},
+			taken:     [][]int64{{100, 0}, {90, 60}},
+			left:      []int64{810, 540},
+		},
+		{
+			name:      "voided lines get nothing",
+			lines:     []models.OrderLine{voidedBurger, fries},
+			discounts: []models.OrderDiscount{percent(models.ScopeOrder, 1000), percent(models.ScopeItem, 1000)},
+			taken:     [][]int64{{0, 60}, {0, 54}},
+			left:      []int64{0, 486},
+		},
+	} {
+		discounted, applied := Discounts(test.lines, test.discounts, "USD", money.HalfUp)
+
+		taken := [][]int64{}
+		for _, discount := range applied {
+			taken = append(taken, amountsOf(discount.Lines))
+		}
+
+		left := []money.Money{}
+		for _, line := range discounted {
+			left = append(left, line.Amount)
+		}
+
+		if !reflect.DeepEqual(taken, test.taken) || !reflect.DeepEqual(amountsOf(left), test.left) {
+			t.Errorf("%s: took %v leaving %v, want %v leaving %v", test.name, taken, amountsOf(left), test.taken, test.left)
+		}
+	}
+}
+
+func amountsOf(amounts []money.Money) []int64 {
+	minor := make([]int64, len(amounts))
+	for i, amount := range amounts {
+		minor[i] = amount.Amount
+	}
+
+	return minor
+}
@@ -0,0 +1,115 @@
+// Package billing works out what a guest owes for an order.
+package billing
+
+import (
+	"strings"
+
+	"github.com/vikas-gouda/go-restraunt-mangement/models"
+	"github.com/vikas-gouda/go-restraunt-mangement/money"
+)
+
+// Taxes breaks the lines of an order down into subtotal, tax per rate and
+// total. Voided lines are left out.
+//
+// Lines charged the same set of rates are added up before anything is
+// rounded, so rounding happens once per group and rate rather than per line.
+// Inclusive rates are carved out of the gross amount together and shared
+// between them by rate; exclusive rates are charged on what is left.
+func Taxes(lines []models.OrderLine, rates []models.TaxRate, currency string, rounding money.Rounding) models.TaxBreakdown {
+	type group struct {
+		rates []int
+		gross money.Money
+	}
+
+	groups := map[string]*group{}
+	keys := []string{}
+
+	for _, line := range lines {
+		if line.Status == models.ItemVoided {
+			continue
+		}
+
+		applied := []int{}
+		key := strings.Builder{}
+		for i, rate := range rates {
+			if rate.Applies(line.Tax_category) {
+				applied = append(applied, i)
+				key.WriteString(rate.Tax_rate_id)
+				key.WriteByte(',')
+			}
+		}
+
+		g, ok := groups[key.String()]
+		if !ok {
+			g = &group{rates: applied, gross: money.Zero(currency)}
+			groups[key.String()] = g
+			keys = append(keys, key.String())
+		}
+		g.gross = g.gross.Add(line.Amount)
+	}
+
+	taxable := make([]money.Money, len(rates))
+	taxes := make([]money.Money, len(rates))
+	applied := make([]bool, len(rates))
+	for i := range rates {
+		taxable[i] = money.Zero(currency)
+		taxes[i] = money.Zero(currency)
+	}
+
+	breakdown := models.TaxBreakdown{
+		Subtotal: money.Zero(currency),
+		Taxes:    []models.TaxLine{},
+		Tax:      money.Zero(currency),
+	}
+
+	for _, key := range keys {
+		g := groups[key]
+
+		inclusive := []int{}
+		weights := []int64{}
+		var inclusivePoints int64
+		for _, i := range g.rates {
+			applied[i] = true
+			if rates[i].Inclusive {
+				inclusive = append(inclusive, i)
+				weights = append(weights, rates[i].Basis_points)
+				inclusivePoints += rates[i].Basis_points
+			}
+		}
+
+		net := g.gross.Ratio(10000, 10000+inclusivePoints, rounding)
+		for j, share := range g.gross.Sub(net).Allocate(weights) {
+			taxes[inclusive[j]] = taxes[inclusive[j]].Add(share)
+		}
+
+		for _, i := range g.rates {
+			taxable[i] = taxable[i].Add(net)
+		}
+
+		breakdown.Subtotal = breakdown.Subtotal.Add(net)
+	}
+
+	for i, rate := range rates {
+		if !applied[i] {
+			continue
+		}
+
+		if !rate.Inclusive {
+			taxes[i] = taxable[i].Percent(rate.Basis_points, rounding)
+		}
+
+		breakdown.Taxes = append(breakdown.Taxes, models.TaxLine{
+			Tax_rate_id:  rate.Tax_rate_id,
+			Name:         rate.Name,
+			Category:     rate.Category,
+			Basis_points: rate.Basis_points,
+			Inclusive:    rate.Inclusive,
+			Taxable:      taxable[i],
+			Amount:       taxes[i],
+		})
+		breakdown.Tax = breakdown.Tax.Add(taxes[i])
+	}
+
+	breakdown.Total = breakdown.Subtotal.Add(breakdown.Tax)
+	return breakdown
+}
@@ -0,0 +1,122 @@
+package billing
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/vikas-gouda/go-restraunt-mangement/models"
+	"github.com/vikas-gouda/go-restraunt-mangement/money"
+)
+
+func usd(amount int64) money.Money {
+	return money.New(amount, "USD")
+}
+
+func line(amount int64, category string) models.OrderLine {
+	return models.OrderLine{Quantity: 1, Amount: usd(amount), Tax_category: category}
+}
+
+func rate(id string, category string, basisPoints int64, inclusive bool) models.TaxRate {
+	return models.TaxRate{Tax_rate_id: id, Name: id, Category: category, Basis_points: basisPoints, Inclusive: inclusive}
+}
+
+func TestTaxes(t *testing.T) {
+	voided := line(5000, "FOOD")
+	voided.Status = models.ItemVoided
+
+	for _, test := range []struct {
+		name     string
+		lines    []models.OrderLine
+		rates    []models.TaxRate
+		rounding money.Rounding
+		subtotal int64
+		taxes    map[string]int64
+	}{
+		{
+			name:     "added on top",
+			lines:    []models.OrderLine{line(1000, "FOOD")},
+			rates:    []models.TaxRate{rate("vat", "FOOD", 1000, false)},
+			subtotal: 1000,
+			taxes:    map[string]int64{"vat": 100},
+		},
+		{
+			name:     "other category is not taxed",
+			lines:    []models.OrderLine{line(1000, "ALCOHOL")},
+			rates:    []models.TaxRate{rate("vat", "FOOD", 1000, false)},
+			subtotal: 1000,
+			taxes:    map[string]int64{},
+		},
+		{
+			name:     "rate without category taxes everything",
+			lines:    []models.OrderLine{line(1000, "FOOD"), line(500, "ALCOHOL"), line(200, "")},
+			rates:    []models.TaxRate{rate("city", "", 200, false), rate("alcohol", "ALCOHOL", 2000, false)},
+			subtotal: 1700,
+			taxes:    map[string]int64{"city": 34, "alcohol": 100},
+		},
+		{
+			name:     "carved out of the price",
+			lines:    []models.OrderLine{line(1200, "FOOD")},
+			rates:    []models.TaxRate{rate("vat", "FOOD", 2000, true)},
+			subtotal: 1000,
+			taxes:    map[string]int64{"vat": 200},
+		},
+		{
+			name:     "inclusive rates shared by rate",
+			lines:    []models.OrderLine{line(1150, "FOOD")},
+			rates:    []models.TaxRate{rate("state", "FOOD", 1000, true), rate("city", "FOOD", 500, true)},
+			subtotal: 1000,
+			taxes:    map[string]int64{"state": 100, "city": 50},
+		},
+		{
+			name:     "exclusive on the net of inclusive",
+			lines:    []models.OrderLine{line(1100, "FOOD")},
+			rates:    []models.TaxRate{rate("vat", "FOOD", 1000, true), rate("service", "FOOD", 500, false)},
+			subtotal: 1000,
+			taxes:    map[string]int64{"vat": 100, "service": 50},
+		},
+		{
+			name:     "rounded once per group, not per line",
+			lines:    []models.OrderLine{line(5, "FOOD"), line(5, "FOOD"), line(5, "FOOD")},
+			rates:    []models.TaxRate{rate("vat", "FOOD", 1000, false)},
+			subtotal: 15,
+			taxes:    map[string]int64{"vat": 2},
+		},
+		{
+			name:     "half even",
+			lines:    []models.OrderLine{line(25, "FOOD")},
+			rates:    []models.TaxRate{rate("vat", "FOOD", 1000, false)},
+			rounding: money.HalfEven,
+			subtotal: 25,
+			taxes:    map[string]int64{"vat": 2},
+		},
+		{
+			name:     "voided lines are left out",
+			lines:    []models.OrderLine{line(1000, "FOOD"), voided},
+			rates:    []models.TaxRate{rate("vat", "FOOD", 1000, false)},
+			subtotal: 1000,
+			taxes:    map[string]int64{"vat": 100},
+		},
+	} {
+		rounding := test.rounding
+		if rounding == "" {
+			rounding = money.HalfUp
+		}
+
+		breakdown := Taxes(test.lines, test.rates, "USD", rounding)
+
+		taxes := map[string]int64{}
+		var tax int64
+		for _, taxLine := range breakdown.Taxes {
+			taxes[taxLine.Tax_rate_id] = taxLine.Amount.Amount
+			tax += taxLine.Amount.Amount
+		}
+
+		if !reflect.DeepEqual(taxes, test.taxes) || breakdown.Subtotal != usd(test.subtotal) {
+			t.Errorf("%s: subtotal %v and taxes %v, want %d and %v", test.name, breakdown.Subtotal, taxes, test.subtotal, test.taxes)
+		}
+
+		if breakdown.Tax != usd(tax) || breakdown.Total != usd(test.subtotal+tax) {
+			t.Errorf("%s: tax %v and total %v do not add up", test.name, breakdown.Tax, breakdown.Total)
+		}
+	}
+}
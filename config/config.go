@@ -0,0 +1,279 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/vikas-gouda/go-restraunt-mangement/printing"
+)
+
+type Config struct {
+	Port             string
+	Store            string
+	DB_uri           string
+	DB_name          string
+	DB_timeout       time.Duration
+	Secret_key       string
+	Read_timeout     time.Duration
+	Write_timeout    time.Duration
+	Shutdown_timeout time.Duration
+	Event_buffer     int
+	Idempotency_ttl  time.Duration
+	// Payment_provider takes card and wallet payments; with none they are
+	// only recorded.
+	Payment_provider       string
+	Payment_webhook_secret string
+	Payment_timeout        time.Duration
+	// A print job is tried Print_attempts times before it is marked failed,
+	// each time giving the printer Print_timeout to take it.
+	Print_attempts int
+	Print_timeout  time.Duration
+	// Printers can only be set up and printed to on Print_networks.
+	Print_networks printing.Networks
+}
+
+const (
+	StoreMongo  = "mongo"
+	StoreMemory = "memory"
+)
+
+const (
+	PaymentProviderNone = "none"
+	PaymentProviderMock = "mock"
+)
+
+func Default() Config {
+	return Config{
+		Port:             "8000",
+		Store:            StoreMongo,
+		DB_name:          "restaurant",
+		DB_timeout:       10 * time.Second,
+		Read_timeout:     15 * time.Second,
+		Write_timeout:    30 * time.Second,
+		Shutdown_timeout: 20 * time.Second,
+		Event_buffer:     1000,
+		Idempotency_ttl:  24 * time.Hour,
+		Payment_provider: PaymentProviderNone,
+		Payment_timeout:  30 * time.Second,
+		Print_attempts:   5,
+		Print_timeout:    10 * time.Second,
+		Print_networks:   printing.LAN,
+	}
+}
+
+// Load builds the configuration from, in increasing order of precedence, the
+// defaults, an optional env file (-config, CONFIG_FILE or ./.env), the process
+// environment and the command line flags.
+func Load(args []string) (Config, error) {
+	cfg := Default()
+
+	fs := flag.NewFlagSet("server", flag.ContinueOnError)
+	file := fs.String("config", os.Getenv("CONFIG_FILE"), "path to an env file with the settings")
+	port := fs.String("port", "", "port to listen on")
+	store := fs.String("store", "", "backing store, mongo or memory")
+	dbUri := fs.String("db-uri", "", "MongoDB connection string")
+	dbName := fs.String("db-name", "", "MongoDB database name")
+	dbTimeout := fs.Duration("db-timeout", 0, "timeout for connecting to MongoDB")
+	readTimeout := fs.Duration("read-timeout", 0, "HTTP read timeout")
+	writeTimeout := fs.Duration("write-timeout", 0, "HTTP write timeout")
+	shutdownTimeout := fs.Duration("shutdown-timeout", 0, "how long to wait for in-flight requests on shutdown")
+	idempotencyTtl := fs.Duration("idempotency-ttl", 0, "how long responses are kept for Idempotency-Key retries")
+	paymentProvider := fs.String("payment-provider", "", "payment provider for card and wallet payments, none or mock")
+	paymentTimeout := fs.Duration("payment-timeout", 0, "how long to wait for the payment provider")
+	printAttempts := fs.Int("print-attempts", 0, "how often a print job is tried before it is marked failed")
+	printTimeout := fs.Duration("print-timeout", 0, "how long a printer gets to take a print job")
+	printNetworks := fs.String("print-networks", "", "comma separated IP ranges and addresses printers may be on")
+	eventBuffer := fs.Int("event-buffer", 0, "number of kitchen feed events kept for reconnecting clients")
+
+	if err := fs.Parse(args); err != nil {
+		return cfg, err
+	}
+
+	values := map[string]string{}
+
+	if *file != "" {
+		fileValues, err := godotenv.Read(*file)
+		if err != nil {
+			return cfg, fmt.Errorf("reading config file %s: %w", *file, err)
+		}
+		values = fileValues
+	} else if fileValues, err := godotenv.Read(".env"); err == nil {
+		values = fileValues
+	}
+
+	for _, key := range []string{"PORT", "STORE", "DB_URI", "DB_NAME", "DB_TIMEOUT", "SECRET_KEY", "READ_TIMEOUT", "WRITE_TIMEOUT", "SHUTDOWN_TIMEOUT", "EVENT_BUFFER", "IDEMPOTENCY_TTL", "PAYMENT_PROVIDER", "PAYMENT_WEBHOOK_SECRET", "PAYMENT_TIMEOUT", "PRINT_ATTEMPTS", "PRINT_TIMEOUT", "PRINT_NETWORKS"} {
+		if value, ok := os.LookupEnv(key); ok {
+			values[key] = value
+		}
+	}
+
+	if *printNetworks != "" {
+		values["PRINT_NETWORKS"] = *printNetworks
+	}
+
+	if err := cfg.apply(values); err != nil {
+		return cfg, err
+	}
+
+	setString(&cfg.Port, *port)
+	setString(&cfg.Store, *store)
+	setString(&cfg.DB_uri, *dbUri)
+	setString(&cfg.DB_name, *dbName)
+	setDuration(&cfg.DB_timeout, *dbTimeout)
+	setDuration(&cfg.Read_timeout, *readTimeout)
+	setDuration(&cfg.Write_timeout, *writeTimeout)
+	setDuration(&cfg.Shutdown_timeout, *shutdownTimeout)
+	setDuration(&cfg.Idempotency_ttl, *idempotencyTtl)
+	setString(&cfg.Payment_provider, *paymentProvider)
+	setDuration(&cfg.Payment_timeout, *paymentTimeout)
+	setDuration(&cfg.Print_timeout, *printTimeout)
+	if *eventBuffer != 0 {
+		cfg.Event_buffer = *eventBuffer
+	}
+	if *printAttempts != 0 {
+		cfg.Print_attempts = *printAttempts
+	}
+
+	return cfg, cfg.Validate()
+}
+
+func (cfg *Config) apply(values map[string]string) error {
+	setString(&cfg.Port, values["PORT"])
+	setString(&cfg.Store, values["STORE"])
+	setString(&cfg.DB_uri, values["DB_URI"])
+	setString(&cfg.DB_name, values["DB_NAME"])
+	setString(&cfg.Secret_key, values["SECRET_KEY"])
+	setString(&cfg.Payment_provider, values["PAYMENT_PROVIDER"])
+	setString(&cfg.Payment_webhook_secret, values["PAYMENT_WEBHOOK_SECRET"])
+
+	if values["EVENT_BUFFER"] != "" {
+		n, err := strconv.Atoi(values["EVENT_BUFFER"])
+		if err != nil {
+			return fmt.Errorf("EVENT_BUFFER: %w", err)
+		}
+		cfg.Event_buffer = n
+	}
+
+	if values["PRINT_ATTEMPTS"] != "" {
+		n, err := strconv.Atoi(values["PRINT_ATTEMPTS"])
+		if err != nil {
+			return fmt.Errorf("PRINT_ATTEMPTS: %w", err)
+		}
+		cfg.Print_attempts = n
+	}
+
+	if values["PRINT_NETWORKS"] != "" {
+		networks, err := printing.ParseNetworks(values["PRINT_NETWORKS"])
+		if err != nil {
+			return fmt.Errorf("PRINT_NETWORKS: %w", err)
+		}
+		cfg.Print_networks = networks
+	}
+
+	durations := map[string]*time.Duration{
+		"DB_TIMEOUT":       &cfg.DB_timeout,
+		"READ_TIMEOUT":     &cfg.Read_timeout,
+		"WRITE_TIMEOUT":    &cfg.Write_timeout,
+		"SHUTDOWN_TIMEOUT": &cfg.Shutdown_timeout,
+		"IDEMPOTENCY_TTL":  &cfg.Idempotency_ttl,
+		"PAYMENT_TIMEOUT":  &cfg.Payment_timeout,
+		"PRINT_TIMEOUT":    &cfg.Print_timeout,
+	}
+
+	for key, target := range durations {
+		if values[key] == "" {
+			continue
+		}
+
+		d, err := time.ParseDuration(values[key])
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		*target = d
+	}
+
+	return nil
+}
+
+func (cfg Config) Validate() error {
+	var errs []error
+
+	port, err := strconv.Atoi(cfg.Port)
+	if err != nil || port < 1 || port > 65535 {
+		errs = append(errs, fmt.Errorf("PORT must be a number between 1 and 65535, got %q", cfg.Port))
+	}
+
+	switch cfg.Store {
+	case StoreMongo:
+		if cfg.DB_uri == "" {
+			errs = append(errs, errors.New("DB_URI is required when using the mongo store"))
+		}
+		if cfg.DB_name == "" {
+			errs = append(errs, errors.New("DB_NAME must not be empty"))
+		}
+	case StoreMemory:
+	default:
+		errs = append(errs, fmt.Errorf("STORE must be %q or %q, got %q", StoreMongo, StoreMemory, cfg.Store))
+	}
+
+	if cfg.Secret_key == "" {
+		errs = append(errs, errors.New("SECRET_KEY is required"))
+	}
+
+	timeouts := map[string]time.Duration{
+		"DB_TIMEOUT":       cfg.DB_timeout,
+		"READ_TIMEOUT":     cfg.Read_timeout,
+		"WRITE_TIMEOUT":    cfg.Write_timeout,
+		"SHUTDOWN_TIMEOUT": cfg.Shutdown_timeout,
+		"IDEMPOTENCY_TTL":  cfg.Idempotency_ttl,
+		"PAYMENT_TIMEOUT":  cfg.Payment_timeout,
+		"PRINT_TIMEOUT":    cfg.Print_timeout,
+	}
+
+	for _, key := range []string{"DB_TIMEOUT", "READ_TIMEOUT", "WRITE_TIMEOUT", "SHUTDOWN_TIMEOUT", "IDEMPOTENCY_TTL", "PAYMENT_TIMEOUT", "PRINT_TIMEOUT"} {
+		if timeouts[key] <= 0 {
+			errs = append(errs, fmt.Errorf("%s must be positive", key))
+		}
+	}
+
+	switch cfg.Payment_provider {
+	case PaymentProviderNone:
+	case PaymentProviderMock:
+		if cfg.Payment_webhook_secret == "" {
+			errs = append(errs, errors.New("PAYMENT_WEBHOOK_SECRET is required when using a payment provider"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("PAYMENT_PROVIDER must be %q or %q, got %q", PaymentProviderNone, PaymentProviderMock, cfg.Payment_provider))
+	}
+
+	if cfg.Event_buffer < 1 {
+		errs = append(errs, errors.New("EVENT_BUFFER must be at least 1"))
+	}
+
+	if cfg.Print_attempts < 1 {
+		errs = append(errs, errors.New("PRINT_ATTEMPTS must be at least 1"))
+	}
+
+	if len(cfg.Print_networks) == 0 {
+		errs = append(errs, errors.New("PRINT_NETWORKS must name at least one range"))
+	}
+
+	return errors.Join(errs...)
+}
+
+func setString(target *string, value string) {
+	if value != "" {
+		*target = value
+	}
+}
+
+func setDuration(target *time.Duration, value time.Duration) {
+	if value != 0 {
+		*target = value
+	}
+}
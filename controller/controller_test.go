@@ -0,0 +1,244 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vikas-gouda/go-restraunt-mangement/events"
+	"github.com/vikas-gouda/go-restraunt-mangement/gateway/mock"
+	"github.com/vikas-gouda/go-restraunt-mangement/models"
+	"github.com/vikas-gouda/go-restraunt-mangement/money"
+	"github.com/vikas-gouda/go-restraunt-mangement/printing"
+	"github.com/vikas-gouda/go-restraunt-mangement/store"
+	"github.com/vikas-gouda/go-restraunt-mangement/store/memstore"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const testPassword = "secret123"
+
+// testPasswordHash is hashed once; bcrypt at this cost takes about a second.
+var testPasswordHash = sync.OnceValue(func() string { return HashPassword(testPassword) })
+
+// testApp serves the handlers from an in-memory store. Requests are made as
+// the user in as, without tokens; routes and their checks are tested in the
+// routes package.
+type testApp struct {
+	t          *testing.T
+	s          *store.Store
+	payments   *mock.Provider
+	router     *gin.Engine
+	restaurant models.Restaurant
+	owner      models.User
+	as         models.User
+}
+
+func newTestApp(t *testing.T) *testApp {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	a := &testApp{t: t, s: memstore.New(), payments: mock.New("webhook-secret")}
+
+	a.restaurant = models.Restaurant{Name: "Cafe", Code: "CAFE"}
+	currency, rounding := a.restaurant.Money()
+	a.restaurant.Currency = currency
+	a.restaurant.Rounding = string(rounding)
+	a.restaurant.ID = primitive.NewObjectID()
+	a.restaurant.Restaurant_id = a.restaurant.ID.Hex()
+	if err := a.s.Restaurants.Create(context.Background(), a.restaurant); err != nil {
+		t.Fatal(err)
+	}
+
+	a.owner = a.user(models.RoleOwner, a.restaurant.Restaurant_id)
+	a.as = a.owner
+
+	broker := events.NewBroker(100)
+	queue := printing.NewQueue(printing.Send, 1, time.Millisecond, time.Second)
+	t.Cleanup(func() {
+		broker.Close()
+		queue.Close()
+	})
+
+	r := gin.New()
+	r.Use(func(ctx *gin.Context) {
+		ctx.Set("uid", a.as.User_id)
+		ctx.Set("role", a.as.Role)
+		ctx.Set("restaurant_id", a.as.Restaurant_id)
+	})
+
+	r.POST("/users/signup", SignUp(a.s))
+	r.GET("/users", GetUsers(a.s))
+	r.GET("/users/:user_id", GetUser(a.s))
+	r.POST("/users", CreateUser(a.s))
+	r.PATCH("/users/:user_id/role", UpdateUserRole(a.s))
+	r.PATCH("/users/:user_id/restaurant", UpdateUserRestaurant(a.s))
+	r.DELETE("/users/:user_id/sessions", RevokeUserSessions(a.s))
+	r.GET("/foods", GetFoods(a.s))
+	r.POST("/foods", CreateFood(a.s))
+	r.PATCH("/foods/:food_id", UpdateFood(a.s))
+	r.POST("/taxRates", CreateTaxRate(a.s))
+	r.PATCH("/taxRates/:tax_rate_id", UpdateTaxRate(a.s))
+	r.PATCH("/restaurants/:restaurant_id", UpdateRestaurant(a.s))
+	r.POST("/orderItems", CreateOrderItem(a.s, broker, queue))
+	r.PATCH("/orderItems/:order_item_id", UpdateOrderItem(a.s))
+	r.POST("/orderItems/:order_item_id/void", VoidOrderItem(a.s, broker))
+	r.POST("/orders/:order_id/discounts", ApplyDiscount(a.s))
+	r.DELETE("/orders/:order_id/discounts/:discount_id", RemoveDiscount(a.s))
+	r.POST("/orders/:order_id/split", SplitOrder(a.s))
+	r.POST("/orders/:order_id/merge", MergeInvoices(a.s))
+	r.POST("/invoices", CreateInvoice(a.s))
+	r.GET("/invoices/:invoice_id", GetInvoice(a.s))
+	r.POST("/invoices/:invoice_id/void", VoidInvoice(a.s))
+	r.POST("/invoices/:invoice_id/payments", CreatePayment(a.s, a.payments))
+	r.POST("/payments/:payment_id/refunds", RefundPayment(a.s, a.payments))
+	r.POST("/webhooks/payments/mock", PaymentWebhook(a.s, a.payments))
+	a.router = r
+
+	return a
+}
+
+// user stores a user of role with testPassword.
+func (a *testApp) user(role string, restaurantId string) models.User {
+	a.t.Helper()
+
+	id := primitive.NewObjectID()
+	user := models.User{
+		ID:            id,
+		First_name:    role,
+		Password:      testPasswordHash(),
+		Email:         id.Hex() + "@example.com",
+		Phone:         id.Hex(),
+		Role:          role,
+		User_id:       id.Hex(),
+		Restaurant_id: restaurantId,
+	}
+	if err := a.s.Users.Create(context.Background(), user); err != nil {
+		a.t.Fatal(err)
+	}
+
+	return user
+}
+
+// food stores a food of the test restaurant at price minor units.
+func (a *testApp) food(name string, price int64, taxCategory string) models.Food {
+	a.t.Helper()
+
+	menuId := primitive.NewObjectID().Hex()
+	food := models.Food{
+		ID:            primitive.NewObjectID(),
+		Name:          name,
+		Price:         money.New(price, a.restaurant.Currency),
+		Food_image:    "https://example.com/" + name + ".png",
+		Tax_category:  taxCategory,
+		Menu_id:       &menuId,
+		Restaurant_id: a.restaurant.Restaurant_id,
+	}
+	food.Food_id = food.ID.Hex()
+	if err := a.s.Foods.Create(context.Background(), food); err != nil {
+		a.t.Fatal(err)
+	}
+
+	return food
+}
+
+// table stores a table of the test restaurant for guests.
+func (a *testApp) table(guests int) models.Table {
+	a.t.Helper()
+
+	table := models.Table{ID: primitive.NewObjectID(), Number_of_guests: guests, Table_number: 7, Restaurant_id: a.restaurant.Restaurant_id}
+	table.Table_id = table.ID.Hex()
+	if err := a.s.Tables.Create(context.Background(), table); err != nil {
+		a.t.Fatal(err)
+	}
+
+	return table
+}
+
+// order opens an order at table with one item per food and quantity.
+func (a *testApp) order(table models.Table, items ...orderedItem) (models.Order, []models.OrderItem) {
+	a.t.Helper()
+
+	pack := OrderItemPack{Table_id: table.Table_id}
+	for _, item := range items {
+		foodId := item.food.Food_id
+		pack.Oder_items = append(pack.Oder_items, models.OrderItem{Food_id: &foodId, Quantity: item.quantity, Seat: item.seat})
+	}
+
+	var created struct {
+		Order       models.Order       `json:"order"`
+		Order_items []models.OrderItem `json:"order_items"`
+	}
+	a.expect(a.do(http.MethodPost, "/orderItems", pack), http.StatusOK, &created)
+
+	return created.Order, created.Order_items
+}
+
+type orderedItem struct {
+	food     models.Food
+	quantity int
+	seat     int
+}
+
+// invoice makes out one invoice for the whole order.
+func (a *testApp) invoice(order models.Order) models.Invoice {
+	a.t.Helper()
+
+	var invoice models.Invoice
+	a.expect(a.do(http.MethodPost, "/invoices", gin.H{"order_id": order.Order_id}), http.StatusOK, &invoice)
+
+	return invoice
+}
+
+// view fetches an invoice as billed now.
+func (a *testApp) view(invoiceId string) InvoiceViewFormat {
+	a.t.Helper()
+
+	var invoiceView InvoiceViewFormat
+	a.expect(a.do(http.MethodGet, "/invoices/"+invoiceId, nil), http.StatusOK, &invoiceView)
+
+	return invoiceView
+}
+
+func (a *testApp) do(method string, path string, body interface{}) *httptest.ResponseRecorder {
+	a.t.Helper()
+
+	var payload bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&payload).Encode(body); err != nil {
+			a.t.Fatal(err)
+		}
+	}
+
+	req := httptest.NewRequest(method, path, &payload)
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	a.router.ServeHTTP(w, req)
+	return w
+}
+
+// expect fails the test unless the response has status, and decodes its
+// body into out when given.
+func (a *testApp) expect(w *httptest.ResponseRecorder, status int, out interface{}) {
+	a.t.Helper()
+
+	if w.Code != status {
+		a.t.Fatalf("got %d, want %d: %s", w.Code, status, w.Body.String())
+	}
+
+	if out != nil {
+		if err := json.Unmarshal(w.Body.Bytes(), out); err != nil {
+			a.t.Fatalf("decoding %s: %v", w.Body.String(), err)
+		}
+	}
+}
+
+func usd(amount int64) money.Money {
+	return money.New(amount, "USD")
+}
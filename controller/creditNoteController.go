@@ -0,0 +1,369 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vikas-gouda/go-restraunt-mangement/events"
+	"github.com/vikas-gouda/go-restraunt-mangement/gateway"
+	"github.com/vikas-gouda/go-restraunt-mangement/models"
+	"github.com/vikas-gouda/go-restraunt-mangement/money"
+	"github.com/vikas-gouda/go-restraunt-mangement/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func GetInvoiceCreditNotes(s *store.Store) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		allCreditNotes, err := s.CreditNotes.ListByInvoice(c, ctx.GetString("restaurant_id"), ctx.Param("invoice_id"))
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, allCreditNotes)
+	}
+}
+
+func GetCreditNote(s *store.Store) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		creditNote, err := s.CreditNotes.Get(c, ctx.GetString("restaurant_id"), ctx.Param("credit_note_id"))
+		if errors.Is(err, store.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "credit note was not found"})
+			return
+		}
+
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching the credit note"})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, creditNote)
+	}
+}
+
+// RefundPayment gives back some or all of a payment, through the provider
+// when it was charged through one, and makes a credit note on its invoice.
+func RefundPayment(s *store.Store, provider gateway.Provider) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var request models.RefundRequest
+
+		if err := ctx.BindJSON(&request); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		validationErr := validate.Struct(request)
+		if validationErr != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		restaurantId := ctx.GetString("restaurant_id")
+
+		payment, err := s.Payments.Get(c, restaurantId, ctx.Param("payment_id"))
+		if err != nil {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "payment was not found"})
+			return
+		}
+
+		if status, body := checkApprover(c, s, restaurantId, request.Manager_id, request.Manager_password); status != http.StatusOK {
+			ctx.JSON(status, body)
+			return
+		}
+
+		if payment.Transaction_id != "" && (provider == nil || provider.Name() != payment.Provider) {
+			ctx.JSON(http.StatusConflict, gin.H{"error": "payment was taken through " + payment.Provider + ", which is not set up to refund it"})
+			return
+		}
+
+		currency := payment.Amount.Currency
+		amount := payment.Amount.Sub(payment.Refunded)
+		tip := payment.Tip.Sub(payment.Tip_refunded)
+
+		// Asking for only one of them leaves the other alone.
+		if request.Amount != nil || request.Tip != nil {
+			amount, tip = money.Zero(currency), money.Zero(currency)
+			if request.Amount != nil {
+				amount = *request.Amount
+			}
+			if request.Tip != nil {
+				tip = *request.Tip
+			}
+		}
+
+		for _, part := range []*money.Money{&amount, &tip} {
+			if part.Currency == "" {
+				part.Currency = currency
+			}
+
+			if part.Currency != currency {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": "refunds must be in " + currency})
+				return
+			}
+
+			if part.IsNegative() {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": "refund amounts can not be negative"})
+				return
+			}
+		}
+
+		if amount.IsZero() && tip.IsZero() {
+			ctx.JSON(http.StatusConflict, gin.H{"error": "nothing is left to refund on this payment"})
+			return
+		}
+
+		invoice, err := s.Invoices.Get(c, restaurantId, payment.Invoice_id)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching the invoice"})
+			return
+		}
+
+		invoiceView, err := viewInvoice(c, s, invoice)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while billing the invoice"})
+			return
+		}
+
+		// Giving back an overpayment leaves what is due alone; only the rest
+		// is credited.
+		credited := amount
+		if invoiceView.Balance.IsNegative() {
+			credited = amount.Add(invoiceView.Balance)
+		}
+		if credited.IsNegative() {
+			credited = money.Zero(currency)
+		}
+
+		payment, refunded, err := s.Payments.Refund(c, restaurantId, payment.Payment_id, amount, tip)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record the refund"})
+			return
+		}
+
+		if !refunded {
+			ctx.JSON(http.StatusConflict, gin.H{"error": "refund is more than is left of the payment"})
+			return
+		}
+
+		var creditNote models.CreditNote
+
+		if payment.Transaction_id != "" {
+			result, err := provider.Refund(c, payment.Transaction_id, amount.Add(tip))
+			if status, body := gatewayError(err, result); status != http.StatusOK {
+				s.Payments.Refund(context.Background(), restaurantId, payment.Payment_id, amount.Neg(), tip.Neg())
+				ctx.JSON(status, body)
+				return
+			}
+
+			creditNote.Provider = payment.Provider
+			creditNote.Transaction_id = payment.Transaction_id
+			creditNote.Provider_status = result.Status
+		}
+
+		creditNote.Kind = models.CreditRefund
+		creditNote.Reason = request.Reason
+		creditNote.Note = request.Note
+		creditNote.Amount = amount
+		creditNote.Tip = tip
+		creditNote.Credited = credited
+		creditNote.Tender = payment.Tender
+		creditNote.Approved_by = request.Manager_id
+		creditNote.Invoice_id = invoice.Invoice_id
+		creditNote.Order_id = payment.Order_id
+		creditNote.Payment_id = payment.Payment_id
+
+		// The money has gone back by now, so from here on failures are
+		// reported but not undone.
+		invoice, err = s.Invoices.AddRefund(c, restaurantId, invoice.Invoice_id, amount, credited)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "refund was given but the invoice was not updated"})
+			return
+		}
+
+		creditNote, err = createCreditNote(c, s, ctx, creditNote)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "refund was given but the credit note was not saved"})
+			return
+		}
+
+		invoiceView, err = settleInvoice(c, s, invoice)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "refund was given but the invoice status was not updated"})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"credit_note": creditNote, "payment": payment, "invoice": invoiceView})
+	}
+}
+
+// VoidOrderItem takes an item off an open order with a manager's approval.
+// The bill drops it straight away; the credit note keeps what it was worth.
+func VoidOrderItem(s *store.Store, broker *events.Broker) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var request models.VoidRequest
+
+		if err := ctx.BindJSON(&request); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		validationErr := validate.Struct(request)
+		if validationErr != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		restaurantId := ctx.GetString("restaurant_id")
+		role := ctx.GetString("role")
+
+		orderItem, err := s.OrderItems.Get(c, restaurantId, ctx.Param("order_item_id"))
+		if err != nil {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "order item not found"})
+			return
+		}
+
+		order, err := s.Orders.Get(c, restaurantId, *orderItem.Order_id)
+		if err != nil {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "order not found"})
+			return
+		}
+
+		if order.Closed() {
+			ctx.JSON(http.StatusConflict, gin.H{"error": "order is " + order.CurrentStatus() + " and can no longer be changed"})
+			return
+		}
+
+		from := orderItem.CurrentStatus()
+		if !models.CanTransitionItem(from, models.ItemVoided) {
+			ctx.JSON(http.StatusConflict, gin.H{"error": "order item can not move from " + from + " to " + models.ItemVoided})
+			return
+		}
+
+		if !models.CanMoveItemTo(role, models.ItemVoided) {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": "a " + role + " can not move an order item to " + models.ItemVoided})
+			return
+		}
+
+		if status, body := checkApprover(c, s, restaurantId, request.Manager_id, request.Manager_password); status != http.StatusOK {
+			ctx.JSON(status, body)
+			return
+		}
+
+		invoice, billed, err := invoiceOfItem(c, s, restaurantId, order.Order_id, orderItem.Order_item_id)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching the invoices of the order"})
+			return
+		}
+
+		orderItem, status, body := moveOrderItem(c, s, broker, order, orderItem, from, models.ItemVoided, ctx.GetString("uid"), role)
+		if status != http.StatusOK {
+			ctx.JSON(status, body)
+			return
+		}
+
+		creditNote := models.CreditNote{
+			Kind:          models.CreditItemVoid,
+			Reason:        request.Reason,
+			Note:          request.Note,
+			Amount:        orderItem.LineTotal(),
+			Tip:           money.Zero(orderItem.Line_total.Currency),
+			Credited:      money.Zero(orderItem.Line_total.Currency),
+			Approved_by:   request.Manager_id,
+			Order_id:      order.Order_id,
+			Order_item_id: orderItem.Order_item_id,
+		}
+		if billed {
+			creditNote.Invoice_id = invoice.Invoice_id
+		}
+
+		creditNote, err = createCreditNote(c, s, ctx, creditNote)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "order item was voided but the credit note was not saved"})
+			return
+		}
+
+		// Less is due now, so what was paid may already cover it.
+		if billed && invoice.Settled() {
+			if _, err := settleInvoice(c, s, invoice); err != nil {
+				ctx.JSON(http.StatusInternalServerError, gin.H{"error": "order item was voided but the invoice status was not updated"})
+				return
+			}
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"order_item": orderItem, "credit_note": creditNote})
+	}
+}
+
+func createCreditNote(c context.Context, s *store.Store, ctx *gin.Context, creditNote models.CreditNote) (models.CreditNote, error) {
+	creditNote.Created_by = ctx.GetString("uid")
+	creditNote.Role = ctx.GetString("role")
+	creditNote.Created_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	creditNote.ID = primitive.NewObjectID()
+	creditNote.Credit_note_id = creditNote.ID.Hex()
+	creditNote.Restaurant_id = ctx.GetString("restaurant_id")
+
+	return creditNote, s.CreditNotes.Create(c, creditNote)
+}
+
+// checkApprover makes sure userId is a manager or an owner of the restaurant
+// and password is theirs, so nobody approves their own refund by quoting a
+// manager's id. Anything but 200 is the response to give instead.
+func checkApprover(c context.Context, s *store.Store, restaurantId string, userId string, password string) (int, gin.H) {
+	user, err := s.Users.Get(c, userId)
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		return http.StatusInternalServerError, gin.H{"error": "Error while fetching the manager"}
+	}
+
+	if err != nil || user.Restaurant_id != restaurantId || (user.Role != models.RoleOwner && user.Role != models.RoleManager) {
+		return http.StatusForbidden, gin.H{"error": "manager_id must be a manager of this restaurant"}
+	}
+
+	if valid, _ := VerifyPassword(password, user.Password); !valid {
+		return http.StatusForbidden, gin.H{"error": "manager_password is incorrect"}
+	}
+
+	return http.StatusOK, nil
+}
+
+// invoiceOfItem finds the invoice, not voided, that bills an item. Even
+// shares all bill the whole order; the first one is returned.
+func invoiceOfItem(c context.Context, s *store.Store, restaurantId string, orderId string, orderItemId string) (models.Invoice, bool, error) {
+	invoices, err := s.Invoices.ListByOrder(c, restaurantId, orderId)
+	if err != nil {
+		return models.Invoice{}, false, err
+	}
+
+	for _, invoice := range invoices {
+		if invoice.Voided() {
+			continue
+		}
+
+		switch invoice.Mode() {
+		case models.SplitItems, models.SplitSeat:
+			for _, id := range invoice.Order_item_ids {
+				if id == orderItemId {
+					return invoice, true, nil
+				}
+			}
+		default:
+			return invoice, true, nil
+		}
+	}
+
+	return models.Invoice{}, false, nil
+}
@@ -2,30 +2,27 @@ package controller
 
 import (
 	"context"
-	"log"
-	"math"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
-	"github.com/vikas-gouda/go-restraunt-mangement/database"
 	"github.com/vikas-gouda/go-restraunt-mangement/models"
-	"go.mongodb.org/mongo-driver/bson"
+	"github.com/vikas-gouda/go-restraunt-mangement/money"
+	"github.com/vikas-gouda/go-restraunt-mangement/store"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-var foodCollection *mongo.Collection = database.OpenCollection(database.Client, "food")
-var menuCollection *mongo.Collection = database.OpenCollection(database.Client, "menu")
-
 var validate = validator.New()
 
-func GetFoods() gin.HandlerFunc {
+func GetFoods(s *store.Store) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
 
 		recordPerPagge, err := strconv.Atoi((ctx.Query("recordPerPage")))
 		if err != nil || recordPerPagge < 1 {
@@ -38,61 +35,33 @@ func GetFoods() gin.HandlerFunc {
 		}
 
 		startIndex := (page - 1) * recordPerPagge
-		startIndex, err = strconv.Atoi(ctx.Query("startIndex"))
-
-		matchStage := bson.D{
-			{"$match", bson.D{{}}},
-		}
-
-		groupStage := bson.D{
-			{"$group", bson.D{
-				{"_id", bson.D{{"_id", "null"}}},
-				{"total_count", bson.D{{"$sum", "1"}}},
-				{"data", bson.D{{"$push", "$ROOT"}}},
-			}},
-		}
-
-		projectStage := bson.D{
-			{
-				"$project", bson.D{
-					{"_id", 0},
-					{"total_count", 1},
-					{"food_items", bson.D{{"$slice", []interface{}{"$data", startIndex, recordPerPagge}}}},
-				},
-			},
+		if index, err := strconv.Atoi(ctx.Query("startIndex")); err == nil && index >= 0 {
+			startIndex = index
 		}
 
-		result, err := foodCollection.Aggregate(c, mongo.Pipeline{
-			matchStage, groupStage, projectStage,
-		})
-		defer cancel()
+		total, foods, err := s.Foods.List(c, ctx.GetString("restaurant_id"), startIndex, recordPerPagge)
 		if err != nil {
 			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "error occured while listing food items"})
 			return
 		}
 
-		var allFoods []bson.M
-
-		if err = result.All(c, &allFoods); err != nil {
-			log.Fatal(err.Error())
-			return
-		}
-
-		ctx.JSON(http.StatusOK, allFoods[0])
+		ctx.JSON(http.StatusOK, gin.H{"total_count": total, "food_items": foods})
 
 	}
 }
 
-func GetFood() gin.HandlerFunc {
+func GetFood(s *store.Store) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
 
 		foodId := ctx.Param("food_id")
 
-		var food models.Food
-
-		err := foodCollection.FindOne(c, bson.M{"food_id": foodId}).Decode(&food)
-		defer cancel()
+		food, err := s.Foods.Get(c, ctx.GetString("restaurant_id"), foodId)
+		if errors.Is(err, store.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "food was not found"})
+			return
+		}
 
 		if err != nil {
 			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error occured while the food id"})
@@ -103,11 +72,11 @@ func GetFood() gin.HandlerFunc {
 	}
 }
 
-func CreateFood() gin.HandlerFunc {
+func CreateFood(s *store.Store) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
 
-		var menu models.Menu
 		var food models.Food
 
 		if err := ctx.BindJSON(&food); err != nil {
@@ -121,38 +90,56 @@ func CreateFood() gin.HandlerFunc {
 			return
 		}
 
-		err := menuCollection.FindOne(c, bson.M{"menu_id": food.Menu_id}).Decode(&menu)
-		defer cancel()
-
+		_, err := s.Menus.Get(c, ctx.GetString("restaurant_id"), *food.Menu_id)
 		if err != nil {
 			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "menu was not found"})
+			return
+		}
+
+		currency, _, err := restaurantMoney(c, s, ctx.GetString("restaurant_id"))
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching the restaurant"})
+			return
+		}
+
+		if err := checkPrice(&food.Price, currency); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if food.Modifier_groups == nil {
+			food.Modifier_groups = []models.ModifierGroup{}
+		}
+
+		food.Tax_category = strings.ToUpper(food.Tax_category)
+
+		if err := models.PrepareModifierGroups(food.Modifier_groups, currency); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
 		}
 
 		food.Created_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
 		food.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
 		food.ID = primitive.NewObjectID()
 		food.Food_id = food.ID.Hex()
+		food.Restaurant_id = ctx.GetString("restaurant_id")
 
-		food.Price = toFixed(*&food.Price, 2)
-
-		result, insertErr := foodCollection.InsertOne(c, food)
-		if insertErr != nil {
+		if err := s.Foods.Create(c, food); err != nil {
 			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Food was not created"})
 			return
 		}
 
-		defer cancel()
-		ctx.JSON(http.StatusOK, result)
+		ctx.JSON(http.StatusOK, food)
 
 	}
 }
 
-func UpdateFood() gin.HandlerFunc {
+func UpdateFood(s *store.Store) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
 
 		var food models.Food
-		var menu models.Menu
 
 		if err := ctx.BindJSON(&food); err != nil {
 			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -160,61 +147,95 @@ func UpdateFood() gin.HandlerFunc {
 		}
 
 		foodId := ctx.Param("food_id")
-		menuId := ctx.Param("menu_id")
+		restaurantId := ctx.GetString("restaurant_id")
+
+		// A food can only be moved to a menu of the same restaurant.
+		if food.Menu_id != nil {
+			_, err := s.Menus.Get(c, restaurantId, *food.Menu_id)
+			if err != nil {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": "Menu doesnt exist"})
+				return
+			}
+		}
 
-		err := menuCollection.FindOne(c, bson.M{"menu_id": menuId}).Decode(&menu)
+		foundFood, err := s.Foods.Get(c, restaurantId, foodId)
 		if err != nil {
-			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Menu doesnt exist"})
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "food was not found"})
 			return
 		}
 
-		var updateObj primitive.D
-
 		if food.Name != "" {
-			updateObj = append(updateObj, bson.E{"name", food.Name})
+			foundFood.Name = food.Name
 		}
 
-		if food.Price != 0.0 {
-			updateObj = append(updateObj, bson.E{"food_price", food.Price})
+		if !food.Price.IsZero() {
+			if err := checkPrice(&food.Price, foundFood.Price.Currency); err != nil {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			foundFood.Price = food.Price
 		}
 
 		if food.Food_image != "" {
-			updateObj = append(updateObj, bson.E{"food_image", food.Food_image})
+			foundFood.Food_image = food.Food_image
 		}
 
 		if food.Menu_id != nil {
-			updateObj = append(updateObj, bson.E{"menu_id", food.Menu_id})
+			foundFood.Menu_id = food.Menu_id
 		}
 
-		food.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
-		updateObj = append(updateObj, bson.E{"updated_at", food.Updated_at})
+		if food.Tax_category != "" {
+			validationErr := validate.Var(food.Tax_category, "max=30")
+			if validationErr != nil {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+				return
+			}
 
-		upsert := true
-		filter := bson.M{"food_id": foodId}
+			foundFood.Tax_category = strings.ToUpper(food.Tax_category)
+		}
 
-		opt := options.UpdateOptions{
-			Upsert: &upsert,
+		// The groups are replaced as a whole; send [] to remove them all.
+		if food.Modifier_groups != nil {
+			validationErr := validate.Var(food.Modifier_groups, "dive")
+			if validationErr != nil {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+				return
+			}
+
+			if err := models.PrepareModifierGroups(food.Modifier_groups, foundFood.Price.Currency); err != nil {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			foundFood.Modifier_groups = food.Modifier_groups
 		}
 
-		result, err := foodCollection.UpdateOne(c, filter, bson.D{
-			{"$set", updateObj},
-		}, &opt)
+		foundFood.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
 
-		if err != nil {
+		if err := s.Foods.Update(c, foundFood); err != nil {
 			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update the food"})
 			return
 		}
 
-		defer cancel()
-		ctx.JSON(http.StatusOK, result)
+		ctx.JSON(http.StatusOK, foundFood)
 	}
 }
 
-func round(num float64) int {
-	return int(num + math.Copysign(0.5, num))
-}
+// checkPrice fills in the currency when the client left it out and makes
+// sure the price is positive and in the restaurant's currency.
+func checkPrice(price *money.Money, currency string) error {
+	if price.Currency == "" {
+		price.Currency = currency
+	}
+
+	if price.Currency != currency {
+		return fmt.Errorf("prices must be in %s", currency)
+	}
+
+	if price.Amount <= 0 {
+		return errors.New("price must be more than zero")
+	}
 
-func toFixed(num float64, precision int) float64 {
-	output := math.Pow(10, float64(precision))
-	return float64(round(num*output)) / output
+	return nil
 }
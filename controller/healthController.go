@@ -0,0 +1,43 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vikas-gouda/go-restraunt-mangement/store"
+	"github.com/vikas-gouda/go-restraunt-mangement/version"
+)
+
+func Healthz() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.JSON(http.StatusOK, gin.H{"status": "ok"})
+	}
+}
+
+func Readyz(s *store.Store) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		// Probes have short deadlines of their own, so don't hang around.
+		var c, cancel = context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+
+		if err := s.Health.Ping(c); err != nil {
+			ctx.JSON(http.StatusServiceUnavailable, gin.H{"status": "unavailable", "error": "database is not reachable"})
+			return
+		}
+
+		if err := s.Health.CheckIndexes(c); err != nil {
+			ctx.JSON(http.StatusServiceUnavailable, gin.H{"status": "unavailable", "error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"status": "ready"})
+	}
+}
+
+func Version() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.JSON(http.StatusOK, version.Get())
+	}
+}
@@ -2,137 +2,188 @@ package controller
 
 import (
 	"context"
-	"log"
+	"errors"
+	"fmt"
 	"net/http"
+	"sort"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/vikas-gouda/go-restraunt-mangement/database"
+	"github.com/vikas-gouda/go-restraunt-mangement/billing"
 	"github.com/vikas-gouda/go-restraunt-mangement/models"
-	"go.mongodb.org/mongo-driver/bson"
+	"github.com/vikas-gouda/go-restraunt-mangement/money"
+	"github.com/vikas-gouda/go-restraunt-mangement/store"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type InvoiceViewFormat struct {
-	Invoice_id       string
-	Payment_method   string
-	Order_id         string
-	Payment_status   *string
-	Payment_due      interface{}
-	Table_number     interface{}
-	Payment_due_date time.Time
-	Order_details    interface{}
+	Invoice_id            string
+	Invoice_number        string
+	Payment_method        string
+	Order_id              string
+	Payment_status        *string
+	Payment_due           interface{}
+	Table_number          interface{}
+	Payment_due_date      time.Time
+	Split_mode            string
+	Seat                  int
+	Parts                 int
+	Shares                []int
+	Voided_at             *time.Time
+	Void_reason           string
+	Paid                  money.Money
+	Tips                  money.Money
+	Credited              money.Money
+	Balance               money.Money
+	Payments              []models.Payment
+	Credit_notes          []models.CreditNote
+	Order_details         interface{}
+	Items_total           money.Money
+	Discounts             []models.DiscountLine
+	Discount              money.Money
+	Subtotal              money.Money
+	Taxes                 []models.TaxLine
+	Tax                   money.Money
+	Gratuity_basis_points int64
+	Gratuity              money.Money
+	Total                 money.Money
 }
 
-var InvoiceCollection *mongo.Collection = database.OpenCollection(database.Client, "invoice")
-
-func GetInvoices() gin.HandlerFunc {
+func GetInvoices(s *store.Store) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
-
-		result, err := InvoiceCollection.Find(context.Background(), bson.M{})
 		defer cancel()
+
+		allInvoices, err := s.Invoices.List(c, ctx.GetString("restaurant_id"))
 		if err != nil {
 			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
 
-		var allInvoices []bson.M
-		if err := result.All(c, &allInvoices); err != nil {
-			log.Fatal(err)
-			return
-		}
-
 		ctx.JSON(http.StatusOK, allInvoices)
 	}
 }
 
-func GetInvoice() gin.HandlerFunc {
+func GetInvoice(s *store.Store) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
 
 		invoiceId := ctx.Param("invoice_id")
 
-		var invoice models.Invoice
-
-		err := InvoiceCollection.FindOne(c, bson.M{"invoice_id": invoiceId}).Decode(&invoice)
-		defer cancel()
+		invoice, err := s.Invoices.Get(c, ctx.GetString("restaurant_id"), invoiceId)
+		if errors.Is(err, store.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "invoice not found"})
+			return
+		}
 
 		if err != nil {
 			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error occurred while listing invoice item"})
+			return
 		}
 
-		var invoiceView InvoiceViewFormat
-
-		allOrderItems, err := ItemsByOrder(*invoice.Order_id)
-		invoiceView.Order_id = *invoice.Order_id
-		invoiceView.Payment_due_date = invoice.Payment_due_date
-
-		invoiceView.Payment_method = "null"
-		if invoice.Payment_method != "" {
-			invoiceView.Payment_method = invoice.Payment_method
+		invoiceView, err := viewInvoice(c, s, invoice)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error occurred while billing the order"})
+			return
 		}
 
-		invoiceView.Invoice_id = invoice.Invoice_id
-		invoiceView.Payment_status = &invoice.Payment_status
-		invoiceView.Payment_due = allOrderItems[0]["payment_due"]
-		invoiceView.Table_number = allOrderItems[0]["table_number"]
-		invoiceView.Order_details = allOrderItems[0]["order_items"]
-
 		ctx.JSON(http.StatusOK, invoiceView)
 	}
 }
 
-func CreateInvoice() gin.HandlerFunc {
+func CreateInvoice(s *store.Store) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
 
 		var invoice models.Invoice
-		var order models.Invoice
 
 		if err := ctx.BindJSON(&invoice); err != nil {
 			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
 
+		// Nothing is paid on a new invoice, whatever the client says.
+		invoice.Payment_status = models.InvoicePending
+		invoice.Payment_method = ""
+		invoice.Paid = money.Money{}
+
 		validationErr := validate.Struct(invoice)
 		if validationErr != nil {
 			ctx.JSON(http.StatusInternalServerError, gin.H{"error": validationErr.Error()})
 			return
 		}
 
-		err := orderCollection.FindOne(c, bson.M{"oder_id": invoice.Order_id}).Decode(&order)
+		if invoice.Order_id == nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "order_id is required"})
+			return
+		}
+
+		order, err := s.Orders.Get(c, ctx.GetString("restaurant_id"), *invoice.Order_id)
 		if err != nil {
 			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "order not found"})
 			return
 		}
 
+		if order.CurrentStatus() == models.OrderCancelled {
+			ctx.JSON(http.StatusConflict, gin.H{"error": "order is CANCELLED and can not be billed"})
+			return
+		}
+
+		openInvoices, err := openInvoicesOf(c, s, order)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while listing the invoices of the order"})
+			return
+		}
+
+		if len(openInvoices) > 0 {
+			ctx.JSON(http.StatusConflict, gin.H{"error": "the order is invoiced already, split or merge its invoices instead"})
+			return
+		}
+
 		invoice.Payment_due_date, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
 		invoice.Created_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
 		invoice.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
 
 		invoice.ID = primitive.NewObjectID()
 		invoice.Invoice_id = invoice.ID.Hex()
+		invoice.Restaurant_id = ctx.GetString("restaurant_id")
+		invoice.Split_mode = models.SplitFull
 
-		result, insertErr := InvoiceCollection.InsertOne(c, order)
-		if insertErr != nil {
-			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while inserting"})
+		invoices := []models.Invoice{invoice}
+		if err := billInvoices(c, s, invoice.Restaurant_id, order.Order_id, invoices); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error occurred while billing the order"})
 			return
 		}
-		defer cancel()
 
-		ctx.JSON(http.StatusOK, result)
+		numbering, err := invoiceNumbering(c, s, invoice.Restaurant_id, invoice.Created_at)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching the restaurant"})
+			return
+		}
+
+		createdInvoices, created, err := s.Invoices.Replace(c, invoice.Restaurant_id, order.Order_id, order.Billing_version, nil, invoice.Created_at, invoices, numbering)
+		if err != nil {
+			ctx.JSON(replaceError(err, "Error while inserting"))
+			return
+		}
+
+		if !created {
+			ctx.JSON(http.StatusConflict, gin.H{"error": "the order was invoiced meanwhile"})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, createdInvoices[0])
 	}
 }
 
-func UpdateInvoice() gin.HandlerFunc {
+func UpdateInvoice(s *store.Store) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
 
 		var invoice models.Invoice
-		var order models.Order
 
 		if err := ctx.BindJSON(&invoice); err != nil {
 			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -140,49 +191,651 @@ func UpdateInvoice() gin.HandlerFunc {
 		}
 
 		invoiceId := ctx.Param("invoice_id")
-		orderId := ctx.Param("order_id")
 
-		err := orderCollection.FindOne(c, bson.M{"order_id": orderId}).Decode(&order)
+		foundInvoice, err := s.Invoices.Get(c, ctx.GetString("restaurant_id"), invoiceId)
 		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "order not found"})
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "invoice not found"})
 			return
 		}
 
-		var updateObj primitive.D
-
-		if invoice.Payment_method != "" {
-			updateObj = append(updateObj, bson.E{"payment_method", invoice.Payment_method})
+		if foundInvoice.Voided() {
+			ctx.JSON(http.StatusConflict, gin.H{"error": "invoice was voided and can no longer be changed"})
+			return
 		}
 
-		if invoice.Payment_status == "" {
-			invoice.Payment_status = "PENDING"
-			updateObj = append(updateObj, bson.E{"payment_status", invoice.Payment_status})
+		// Both follow from the payments taken on the invoice.
+		if invoice.Payment_method != "" || invoice.Payment_status != "" {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "payments are recorded with POST /invoices/" + invoiceId + "/payments"})
+			return
 		}
 
 		if (invoice.Payment_due_date != time.Time{}) {
-			updateObj = append(updateObj, bson.E{"payment_due_date", invoice.Payment_due_date})
+			foundInvoice.Payment_due_date = invoice.Payment_due_date
 		}
 
-		invoice.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
-		updateObj = append(updateObj, bson.E{"updated_at", invoice.Updated_at})
+		validationErr := validate.Struct(foundInvoice)
+		if validationErr != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
 
-		upsert := true
-		filter := bson.M{"invoice_id": invoiceId}
-		opt := options.UpdateOptions{
-			Upsert: &upsert,
+		foundInvoice.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+
+		if err := s.Invoices.Update(c, foundInvoice); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update the Invoice"})
+			return
 		}
 
-		result, err := InvoiceCollection.UpdateOne(c, filter, bson.D{
-			{"$set", updateObj},
-		}, &opt)
+		ctx.JSON(http.StatusOK, foundInvoice)
 
+	}
+}
+
+// GetOrderInvoices lists every invoice of an order, the voided ones included.
+func GetOrderInvoices(s *store.Store) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		allInvoices, err := s.Invoices.ListByOrder(c, ctx.GetString("restaurant_id"), ctx.Param("order_id"))
 		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update the Invoice"})
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
 
+		ctx.JSON(http.StatusOK, allInvoices)
+	}
+}
+
+// SplitOrder replaces the unpaid invoices of an order with one invoice per
+// check. Every item that is not voided ends up on exactly one of them.
+func SplitOrder(s *store.Store) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
 		defer cancel()
-		ctx.JSON(http.StatusOK, result)
 
+		var request models.SplitRequest
+
+		if err := ctx.BindJSON(&request); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		validationErr := validate.Struct(request)
+		if validationErr != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		restaurantId := ctx.GetString("restaurant_id")
+
+		order, err := s.Orders.Get(c, restaurantId, ctx.Param("order_id"))
+		if err != nil {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "order not found"})
+			return
+		}
+
+		if order.CurrentStatus() == models.OrderCancelled {
+			ctx.JSON(http.StatusConflict, gin.H{"error": "order is CANCELLED and can not be billed"})
+			return
+		}
+
+		openInvoices, err := openInvoicesOf(c, s, order)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while listing the invoices of the order"})
+			return
+		}
+
+		voidIds := []string{}
+		for _, invoice := range openInvoices {
+			if invoice.Settled() {
+				ctx.JSON(http.StatusConflict, gin.H{"error": "part of the order is paid already and can not be split again"})
+				return
+			}
+			voidIds = append(voidIds, invoice.Invoice_id)
+		}
+
+		orderItems, err := s.OrderItems.ListByOrder(c, restaurantId, order.Order_id)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while listing the order items"})
+			return
+		}
+
+		billable := map[string]models.OrderItem{}
+		for _, orderItem := range orderItems {
+			if orderItem.CurrentStatus() != models.ItemVoided {
+				billable[orderItem.Order_item_id] = orderItem
+			}
+		}
+
+		if len(billable) == 0 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "the order has nothing to bill"})
+			return
+		}
+
+		template := models.Invoice{
+			Order_id:       &order.Order_id,
+			Payment_status: models.InvoicePending,
+			Split_mode:     request.Mode,
+			Restaurant_id:  restaurantId,
+		}
+		template.Payment_due_date, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		template.Created_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		template.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+
+		invoices := []models.Invoice{}
+
+		switch request.Mode {
+		case models.SplitItems:
+			if err := checkPartition(request.Checks, billable); err != nil {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			for _, check := range request.Checks {
+				invoice := template
+				invoice.Order_item_ids = check
+				invoices = append(invoices, invoice)
+			}
+
+		case models.SplitSeat:
+			seats := map[int][]string{}
+			seatOrder := []int{}
+			for _, orderItem := range orderItems {
+				if _, ok := billable[orderItem.Order_item_id]; !ok {
+					continue
+				}
+				if _, ok := seats[orderItem.Seat]; !ok {
+					seatOrder = append(seatOrder, orderItem.Seat)
+				}
+				seats[orderItem.Seat] = append(seats[orderItem.Seat], orderItem.Order_item_id)
+			}
+
+			if len(seatOrder) < 2 {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": "the items are all on one seat"})
+				return
+			}
+
+			sort.Ints(seatOrder)
+			for _, seat := range seatOrder {
+				invoice := template
+				invoice.Seat = seat
+				invoice.Order_item_ids = seats[seat]
+				invoices = append(invoices, invoice)
+			}
+
+		case models.SplitEven:
+			if request.Parts == 0 {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": "parts is required to split evenly"})
+				return
+			}
+
+			for part := 1; part <= request.Parts; part++ {
+				invoice := template
+				invoice.Parts = request.Parts
+				invoice.Shares = []int{part}
+				invoices = append(invoices, invoice)
+			}
+		}
+
+		for i := range invoices {
+			invoices[i].ID = primitive.NewObjectID()
+			invoices[i].Invoice_id = invoices[i].ID.Hex()
+		}
+
+		if err := billInvoices(c, s, restaurantId, order.Order_id, invoices); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error occurred while billing the order"})
+			return
+		}
+
+		numbering, err := invoiceNumbering(c, s, restaurantId, template.Created_at)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching the restaurant"})
+			return
+		}
+
+		invoices, replaced, err := s.Invoices.Replace(c, restaurantId, order.Order_id, order.Billing_version, voidIds, template.Created_at, invoices, numbering)
+		if err != nil {
+			ctx.JSON(replaceError(err, "Failed to split the order"))
+			return
+		}
+
+		if !replaced {
+			ctx.JSON(http.StatusConflict, gin.H{"error": "the invoices of the order changed meanwhile, try again"})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, invoices)
 	}
 }
+
+// MergeInvoices voids unpaid checks of an order and puts what they covered on
+// one invoice. Merging every open check gives back a single full invoice.
+func MergeInvoices(s *store.Store) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var request models.MergeRequest
+
+		if err := ctx.BindJSON(&request); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		validationErr := validate.Struct(request)
+		if validationErr != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		restaurantId := ctx.GetString("restaurant_id")
+
+		order, err := s.Orders.Get(c, restaurantId, ctx.Param("order_id"))
+		if err != nil {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "order not found"})
+			return
+		}
+
+		if order.CurrentStatus() == models.OrderCancelled {
+			ctx.JSON(http.StatusConflict, gin.H{"error": "order is CANCELLED and can not be billed"})
+			return
+		}
+
+		openInvoices, err := openInvoicesOf(c, s, order)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while listing the invoices of the order"})
+			return
+		}
+
+		byId := map[string]models.Invoice{}
+		for _, invoice := range openInvoices {
+			byId[invoice.Invoice_id] = invoice
+		}
+
+		merged := models.Invoice{
+			Order_id:       &order.Order_id,
+			Payment_status: models.InvoicePending,
+			Restaurant_id:  restaurantId,
+		}
+
+		seen := map[string]bool{}
+		for _, id := range request.Invoice_ids {
+			invoice, ok := byId[id]
+			if !ok || seen[id] {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": "invoice " + id + " is not an open invoice of the order"})
+				return
+			}
+			seen[id] = true
+
+			if invoice.Settled() {
+				ctx.JSON(http.StatusConflict, gin.H{"error": "invoice " + id + " is paid already"})
+				return
+			}
+
+			mode := invoice.Mode()
+			if mode == models.SplitSeat {
+				mode = models.SplitItems
+			}
+
+			switch {
+			case mode == models.SplitFull:
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": "invoice " + id + " is not a split check"})
+				return
+			case merged.Split_mode != "" && merged.Split_mode != mode:
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": "even shares and item checks can not be merged"})
+				return
+			}
+
+			merged.Split_mode = mode
+			merged.Parts = invoice.Parts
+			merged.Order_item_ids = append(merged.Order_item_ids, invoice.Order_item_ids...)
+			merged.Shares = append(merged.Shares, invoice.Shares...)
+		}
+
+		sort.Ints(merged.Shares)
+
+		if len(request.Invoice_ids) == len(openInvoices) {
+			merged.Split_mode = models.SplitFull
+			merged.Parts = 0
+			merged.Shares = nil
+			merged.Order_item_ids = nil
+		}
+
+		merged.Payment_due_date, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		merged.Created_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		merged.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		merged.ID = primitive.NewObjectID()
+		merged.Invoice_id = merged.ID.Hex()
+
+		mergedInvoices := []models.Invoice{merged}
+		if err := billInvoices(c, s, restaurantId, order.Order_id, mergedInvoices); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error occurred while billing the order"})
+			return
+		}
+
+		numbering, err := invoiceNumbering(c, s, restaurantId, merged.Created_at)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching the restaurant"})
+			return
+		}
+
+		mergedInvoices, replaced, err := s.Invoices.Replace(c, restaurantId, order.Order_id, order.Billing_version, request.Invoice_ids, merged.Created_at, mergedInvoices, numbering)
+		if err != nil {
+			ctx.JSON(replaceError(err, "Failed to merge the invoices"))
+			return
+		}
+
+		if !replaced {
+			ctx.JSON(http.StatusConflict, gin.H{"error": "the invoices of the order changed meanwhile, try again"})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, mergedInvoices[0])
+	}
+}
+
+// VoidInvoice cancels an invoice nothing was paid on. It stays, voided, so
+// its number is still accounted for; the order can be invoiced again.
+func VoidInvoice(s *store.Store) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var request models.InvoiceVoidRequest
+
+		if err := ctx.BindJSON(&request); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		validationErr := validate.Struct(request)
+		if validationErr != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		restaurantId := ctx.GetString("restaurant_id")
+		invoiceId := ctx.Param("invoice_id")
+
+		if _, err := s.Invoices.Get(c, restaurantId, invoiceId); err != nil {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "invoice not found"})
+			return
+		}
+
+		voidedAt, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+
+		invoice, voided, err := s.Invoices.Void(c, restaurantId, invoiceId, voidedAt, request.Reason, ctx.GetString("uid"))
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to void the invoice"})
+			return
+		}
+
+		if !voided {
+			ctx.JSON(http.StatusConflict, gin.H{"error": "invoice is voided already or was paid or refunded, refund it instead"})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, invoice)
+	}
+}
+
+// invoiceNumbering returns how the restaurant numbers invoices made at at.
+func invoiceNumbering(c context.Context, s *store.Store, restaurantId string, at time.Time) (models.InvoiceNumbering, error) {
+	restaurant, err := s.Restaurants.Get(c, restaurantId)
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		return models.InvoiceNumbering{}, err
+	}
+
+	return restaurant.InvoiceNumbering(at), nil
+}
+
+// replaceError is the response to invoices that could not be stored, message
+// saying what failed.
+func replaceError(err error, message string) (int, gin.H) {
+	if errors.Is(err, store.ErrNoTransactions) {
+		return http.StatusServiceUnavailable, gin.H{"error": "invoices are only numbered on a database with transactions, run MongoDB as a replica set"}
+	}
+
+	return http.StatusInternalServerError, gin.H{"error": message}
+}
+
+// openInvoicesOf returns the invoices of an order that are not voided.
+func openInvoicesOf(c context.Context, s *store.Store, order models.Order) ([]models.Invoice, error) {
+	allInvoices, err := s.Invoices.ListByOrder(c, order.Restaurant_id, order.Order_id)
+	if err != nil {
+		return nil, err
+	}
+
+	openInvoices := []models.Invoice{}
+	for _, invoice := range allInvoices {
+		if !invoice.Voided() {
+			openInvoices = append(openInvoices, invoice)
+		}
+	}
+
+	return openInvoices, nil
+}
+
+// checkPartition makes sure the checks share the billable items out so that
+// each of them is on exactly one check.
+func checkPartition(checks [][]string, billable map[string]models.OrderItem) error {
+	if len(checks) < 2 {
+		return errors.New("at least two checks are needed to split by item")
+	}
+
+	seen := map[string]bool{}
+	for _, check := range checks {
+		if len(check) == 0 {
+			return errors.New("a check can not be empty")
+		}
+
+		for _, id := range check {
+			if _, ok := billable[id]; !ok {
+				return fmt.Errorf("order item %s is not a billable item of the order", id)
+			}
+			if seen[id] {
+				return fmt.Errorf("order item %s is on more than one check", id)
+			}
+			seen[id] = true
+		}
+	}
+
+	if len(seen) != len(billable) {
+		return errors.New("every item of the order has to be on a check")
+	}
+
+	return nil
+}
+
+// orderBill takes down the order of an invoice as it would be billed now.
+func orderBill(c context.Context, s *store.Store, restaurantId string, orderId string) (models.InvoiceBill, error) {
+	var snapshot models.InvoiceBill
+
+	allOrderItems, err := ItemsByOrder(c, s, restaurantId, orderId)
+	if err != nil {
+		return snapshot, err
+	}
+
+	order, err := s.Orders.Get(c, restaurantId, orderId)
+	if err != nil {
+		return snapshot, err
+	}
+
+	restaurant, err := s.Restaurants.Get(c, restaurantId)
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		return snapshot, err
+	}
+
+	taxRates, err := s.TaxRates.List(c, restaurantId)
+	if err != nil {
+		return snapshot, err
+	}
+
+	currency, rounding := restaurant.Money()
+
+	snapshot.Currency = currency
+	snapshot.Rounding = string(rounding)
+	snapshot.Table_number = allOrderItems.Table_number
+	snapshot.Order_lines = allOrderItems.Order_items
+	snapshot.Order_discounts = order.Discounts
+	snapshot.Tax_rates = taxRates
+	snapshot.Gratuity_basis_points = restaurant.AutoGratuity(allOrderItems.Number_of_guests)
+
+	return snapshot, nil
+}
+
+// billInvoices makes out the bill of invoices about to be made for an order
+// and keeps it on them.
+func billInvoices(c context.Context, s *store.Store, restaurantId string, orderId string, invoices []models.Invoice) error {
+	snapshot, err := orderBill(c, s, restaurantId, orderId)
+	if err != nil {
+		return err
+	}
+
+	for i := range invoices {
+		bill, due := computeBill(invoices[i], snapshot)
+
+		invoiceBill := snapshot
+		invoiceBill.Items_total = bill.Items_total
+		invoiceBill.Discount = bill.Discount
+		invoiceBill.Subtotal = bill.Subtotal
+		invoiceBill.Taxes = bill.Taxes
+		invoiceBill.Tax = bill.Tax
+		invoiceBill.Gratuity = bill.Gratuity
+		invoiceBill.Total = bill.Total
+		invoiceBill.Due = due
+		invoices[i].Bill = &invoiceBill
+	}
+
+	return nil
+}
+
+// computeBill bills what the invoice covers out of a snapshot of its order:
+// all of it, some of its items, or some shares of it. It returns the bill and
+// what the invoice asks for.
+func computeBill(invoice models.Invoice, snapshot models.InvoiceBill) (billing.Bill, money.Money) {
+	var include func(models.OrderLine) bool
+	if mode := invoice.Mode(); mode == models.SplitItems || mode == models.SplitSeat {
+		onCheck := map[string]bool{}
+		for _, id := range invoice.Order_item_ids {
+			onCheck[id] = true
+		}
+		include = func(line models.OrderLine) bool { return onCheck[line.Order_item_id] }
+	}
+
+	rounding := money.Rounding(snapshot.Rounding)
+
+	bill := billing.Compute(snapshot.Order_lines, snapshot.Order_discounts, snapshot.Tax_rates, snapshot.Currency, rounding, include)
+	if snapshot.Gratuity_basis_points > 0 {
+		bill.AddGratuity(snapshot.Gratuity_basis_points, rounding)
+	}
+
+	// An even share owes its part of the whole order.
+	due := bill.Total
+	if invoice.Mode() == models.SplitEven {
+		due = billing.Shares(bill.Total, invoice.Parts, invoice.Shares)
+	}
+
+	return bill, due
+}
+
+// viewInvoice shows the invoice as it was made out, less the items voided
+// since.
+func viewInvoice(c context.Context, s *store.Store, invoice models.Invoice) (InvoiceViewFormat, error) {
+	var invoiceView InvoiceViewFormat
+
+	var snapshot models.InvoiceBill
+	if invoice.Bill != nil {
+		snapshot = *invoice.Bill
+
+		orderItems, err := s.OrderItems.ListByOrder(c, invoice.Restaurant_id, *invoice.Order_id)
+		if err != nil {
+			return invoiceView, err
+		}
+
+		voided := map[string]bool{}
+		for _, orderItem := range orderItems {
+			if orderItem.CurrentStatus() == models.ItemVoided {
+				voided[orderItem.Order_item_id] = true
+			}
+		}
+
+		snapshot.Order_lines = make([]models.OrderLine, len(invoice.Bill.Order_lines))
+		for i, line := range invoice.Bill.Order_lines {
+			if voided[line.Order_item_id] {
+				line.Status = models.ItemVoided
+			}
+			snapshot.Order_lines[i] = line
+		}
+	} else {
+		var err error
+		snapshot, err = orderBill(c, s, invoice.Restaurant_id, *invoice.Order_id)
+		if err != nil {
+			return invoiceView, err
+		}
+	}
+
+	bill, due := computeBill(invoice, snapshot)
+	currency := snapshot.Currency
+
+	invoiceView.Invoice_id = invoice.Invoice_id
+	invoiceView.Invoice_number = invoice.Invoice_number
+	invoiceView.Order_id = *invoice.Order_id
+	invoiceView.Payment_due_date = invoice.Payment_due_date
+
+	invoiceView.Payment_method = "null"
+	if invoice.Payment_method != "" {
+		invoiceView.Payment_method = invoice.Payment_method
+	}
+
+	invoiceView.Table_number = snapshot.Table_number
+	invoiceView.Split_mode = invoice.Mode()
+	invoiceView.Seat = invoice.Seat
+	invoiceView.Parts = invoice.Parts
+	invoiceView.Shares = invoice.Shares
+	invoiceView.Voided_at = invoice.Voided_at
+	invoiceView.Void_reason = invoice.Void_reason
+	invoiceView.Order_details = bill.Lines
+	invoiceView.Items_total = bill.Items_total
+	invoiceView.Discounts = bill.Discounts
+	invoiceView.Discount = bill.Discount
+	invoiceView.Subtotal = bill.Subtotal
+	invoiceView.Taxes = bill.Taxes
+	invoiceView.Tax = bill.Tax
+	invoiceView.Gratuity_basis_points = bill.Gratuity_basis_points
+	invoiceView.Gratuity = bill.Gratuity
+	invoiceView.Total = bill.Total
+
+	payments, err := s.Payments.ListByInvoice(c, invoice.Restaurant_id, invoice.Invoice_id)
+	if err != nil {
+		return invoiceView, err
+	}
+
+	creditNotes, err := s.CreditNotes.ListByInvoice(c, invoice.Restaurant_id, invoice.Invoice_id)
+	if err != nil {
+		return invoiceView, err
+	}
+
+	invoiceView.Payments = payments
+	invoiceView.Credit_notes = creditNotes
+	invoiceView.Payment_due = due
+	invoiceView.Paid = money.Zero(currency).Add(invoice.Paid)
+	invoiceView.Tips = money.Zero(currency)
+	for _, payment := range payments {
+		invoiceView.Tips = invoiceView.Tips.Add(payment.Tip)
+	}
+	invoiceView.Credited = money.Zero(currency).Add(invoice.Credited)
+	invoiceView.Balance = due.Sub(invoiceView.Credited).Sub(invoiceView.Paid)
+
+	// The status is worked out again here as what is due moves when items
+	// are voided. Invoices marked paid by hand before payments existed stay
+	// paid.
+	status := models.PaymentStatus(invoiceView.Paid, due, invoiceView.Credited)
+	if invoice.Paid.IsZero() && invoice.Credited.IsZero() && invoice.Payment_status == models.InvoicePaid {
+		status = models.InvoicePaid
+	}
+	invoiceView.Payment_status = &status
+
+	return invoiceView, nil
+}
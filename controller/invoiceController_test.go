@@ -0,0 +1,177 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vikas-gouda/go-restraunt-mangement/models"
+)
+
+func TestInvoiceKeepsItsBill(t *testing.T) {
+	a := newTestApp(t)
+	burger := a.food("Burger", 1000, "FOOD")
+
+	var vat models.TaxRate
+	a.expect(a.do(http.MethodPost, "/taxRates", gin.H{"name": "VAT", "category": "FOOD", "basis_points": 1000}), http.StatusOK, &vat)
+
+	order, _ := a.order(a.table(4), orderedItem{food: burger, quantity: 1}, orderedItem{food: burger, quantity: 2})
+	invoice := a.invoice(order)
+
+	if invoice.Bill == nil || invoice.Bill.Total != usd(3300) || invoice.Bill.Due != usd(3300) {
+		t.Fatalf("invoice bill = %+v, want a total of 3300 due", invoice.Bill)
+	}
+
+	a.expect(a.do(http.MethodPatch, "/taxRates/"+vat.Tax_rate_id, gin.H{"basis_points": 2000}), http.StatusOK, nil)
+	a.expect(a.do(http.MethodPatch, "/foods/"+burger.Food_id, gin.H{"price": usd(5000)}), http.StatusOK, nil)
+	a.expect(a.do(http.MethodPatch, "/restaurants/"+a.restaurant.Restaurant_id, gin.H{
+		"auto_gratuity_guests":       1,
+		"auto_gratuity_basis_points": 1500,
+	}), http.StatusOK, nil)
+
+	invoiceView := a.view(invoice.Invoice_id)
+	if invoiceView.Total != usd(3300) || invoiceView.Tax != usd(300) || !invoiceView.Gratuity.IsZero() {
+		t.Errorf("total %v, tax %v, gratuity %v; want 3300, 300 and none as billed", invoiceView.Total, invoiceView.Tax, invoiceView.Gratuity)
+	}
+
+	if len(invoiceView.Taxes) != 1 || invoiceView.Taxes[0].Basis_points != 1000 {
+		t.Errorf("taxes = %+v, want VAT at 1000 basis points", invoiceView.Taxes)
+	}
+}
+
+func TestInvoicedOrderIsLocked(t *testing.T) {
+	a := newTestApp(t)
+	burger := a.food("Burger", 1000, "")
+
+	order, items := a.order(a.table(2), orderedItem{food: burger, quantity: 1})
+	invoice := a.invoice(order)
+
+	discount := gin.H{"rule": gin.H{"kind": "PERCENT", "scope": "ORDER", "basis_points": 1000}, "reason": "STAFF_MEAL"}
+
+	a.expect(a.do(http.MethodPatch, "/orderItems/"+items[0].Order_item_id, gin.H{"quantity": 5}), http.StatusConflict, nil)
+	a.expect(a.do(http.MethodPost, "/orders/"+order.Order_id+"/discounts", discount), http.StatusConflict, nil)
+
+	a.expect(a.do(http.MethodPost, "/invoices/"+invoice.Invoice_id+"/void", gin.H{"reason": "wrong table"}), http.StatusOK, nil)
+
+	var orderItem models.OrderItem
+	a.expect(a.do(http.MethodPatch, "/orderItems/"+items[0].Order_item_id, gin.H{"quantity": 5}), http.StatusOK, &orderItem)
+	if orderItem.Line_total != usd(5000) {
+		t.Errorf("line total = %v, want 5000", orderItem.Line_total)
+	}
+
+	a.expect(a.do(http.MethodPost, "/orders/"+order.Order_id+"/discounts", discount), http.StatusOK, nil)
+
+	reissued := a.invoice(order)
+	if reissued.Bill.Total != usd(4500) {
+		t.Errorf("reissued total = %v, want 4500 after 10%% off", reissued.Bill.Total)
+	}
+}
+
+func TestVoidedItemComesOffTheInvoice(t *testing.T) {
+	a := newTestApp(t)
+	burger := a.food("Burger", 1000, "")
+	manager := a.user(models.RoleManager, a.restaurant.Restaurant_id)
+
+	order, items := a.order(a.table(2), orderedItem{food: burger, quantity: 1}, orderedItem{food: burger, quantity: 2})
+	invoice := a.invoice(order)
+
+	void := gin.H{"reason": "WRONG_ITEM", "manager_id": manager.User_id, "manager_password": "wrong"}
+	a.expect(a.do(http.MethodPost, "/orderItems/"+items[0].Order_item_id+"/void", void), http.StatusForbidden, nil)
+
+	void["manager_password"] = testPassword
+	a.expect(a.do(http.MethodPost, "/orderItems/"+items[0].Order_item_id+"/void", void), http.StatusOK, nil)
+
+	if invoiceView := a.view(invoice.Invoice_id); invoiceView.Total != usd(2000) {
+		t.Errorf("total = %v, want 2000 without the voided item", invoiceView.Total)
+	}
+}
+
+func TestSplitEvenSharesAddUpToTheTotal(t *testing.T) {
+	a := newTestApp(t)
+	soup := a.food("Soup", 1000, "")
+
+	order, _ := a.order(a.table(3), orderedItem{food: soup, quantity: 1})
+	full := a.invoice(order)
+
+	var checks []models.Invoice
+	a.expect(a.do(http.MethodPost, "/orders/"+order.Order_id+"/split", gin.H{"mode": "EVEN", "parts": 3}), http.StatusOK, &checks)
+
+	if len(checks) != 3 {
+		t.Fatalf("got %d checks, want 3", len(checks))
+	}
+
+	want := []int64{334, 333, 333}
+	for i, check := range checks {
+		if check.Bill.Due != usd(want[i]) {
+			t.Errorf("check %d due %v, want %d", i+1, check.Bill.Due, want[i])
+		}
+		if check.Sequence != full.Sequence+int64(i)+1 {
+			t.Errorf("check %d has number %d, want %d", i+1, check.Sequence, full.Sequence+int64(i)+1)
+		}
+	}
+
+	if replaced := a.view(full.Invoice_id); replaced.Voided_at == nil {
+		t.Error("the full invoice was not voided by the split")
+	}
+}
+
+func TestItemChecksMergeBackIntoOne(t *testing.T) {
+	a := newTestApp(t)
+	soup := a.food("Soup", 600, "")
+	steak := a.food("Steak", 2400, "")
+
+	order, items := a.order(a.table(2), orderedItem{food: soup, quantity: 1, seat: 1}, orderedItem{food: steak, quantity: 1, seat: 2})
+
+	a.expect(a.do(http.MethodPost, "/orders/"+order.Order_id+"/split", gin.H{
+		"mode":   "ITEMS",
+		"checks": [][]string{{items[0].Order_item_id}},
+	}), http.StatusBadRequest, nil)
+
+	var checks []models.Invoice
+	a.expect(a.do(http.MethodPost, "/orders/"+order.Order_id+"/split", gin.H{"mode": "SEAT"}), http.StatusOK, &checks)
+
+	if len(checks) != 2 || checks[0].Bill.Total != usd(600) || checks[1].Bill.Total != usd(2400) {
+		t.Fatalf("seat checks = %+v, want 600 and 2400", checks)
+	}
+
+	var merged models.Invoice
+	a.expect(a.do(http.MethodPost, "/orders/"+order.Order_id+"/merge", gin.H{
+		"invoice_ids": []string{checks[0].Invoice_id, checks[1].Invoice_id},
+	}), http.StatusOK, &merged)
+
+	if merged.Mode() != models.SplitFull || merged.Bill.Total != usd(3000) {
+		t.Errorf("merged into %s for %v, want FULL for 3000", merged.Mode(), merged.Bill.Total)
+	}
+}
+
+func TestCancelledOrderIsNotBilled(t *testing.T) {
+	a := newTestApp(t)
+	order, _ := a.order(a.table(2), orderedItem{food: a.food("Burger", 1000, ""), quantity: 1})
+
+	cancelled, err := a.s.Orders.Transition(context.Background(), a.restaurant.Restaurant_id, order.Order_id, models.OrderTransition{
+		From: models.OrderOpen,
+		To:   models.OrderCancelled,
+	})
+	if err != nil || !cancelled {
+		t.Fatalf("cancelling the order gave %v, %v", cancelled, err)
+	}
+
+	for _, request := range []struct {
+		path string
+		body gin.H
+	}{
+		{"/invoices", gin.H{"order_id": order.Order_id}},
+		{"/orders/" + order.Order_id + "/split", gin.H{"mode": "EVEN", "parts": 2}},
+		{"/orders/" + order.Order_id + "/merge", gin.H{"invoice_ids": []string{"a", "b"}}},
+	} {
+		if w := a.do(http.MethodPost, request.path, request.body); w.Code != http.StatusConflict {
+			t.Errorf("%s got %d, want 409: %s", request.path, w.Code, w.Body.String())
+		}
+	}
+
+	invoices, _ := a.s.Invoices.ListByOrder(context.Background(), a.restaurant.Restaurant_id, order.Order_id)
+	if len(invoices) != 0 {
+		t.Errorf("%d invoices were made out for the cancelled order", len(invoices))
+	}
+}
@@ -0,0 +1,278 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/vikas-gouda/go-restraunt-mangement/events"
+	"github.com/vikas-gouda/go-restraunt-mangement/helpers"
+	"github.com/vikas-gouda/go-restraunt-mangement/models"
+	"github.com/vikas-gouda/go-restraunt-mangement/store"
+)
+
+const (
+	feedHeartbeat    = 15 * time.Second
+	feedWriteTimeout = 10 * time.Second
+)
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+}
+
+// KitchenToken hands out a short-lived stream token for the feeds, in the
+// kitchen_token cookie and in the body to pass as ?token=.
+func KitchenToken() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		token, expiresAt, err := helpers.GenerateStreamToken(ctx.GetString("uid"), ctx.GetString("role"), ctx.GetString("restaurant_id"), ctx.GetString("session_id"))
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate the token"})
+			return
+		}
+
+		ctx.SetSameSite(http.SameSiteStrictMode)
+		ctx.SetCookie(helpers.StreamCookie, token, int(helpers.StreamTokenLifetime.Seconds()), "/kitchen", "", ctx.Request.TLS != nil, true)
+		ctx.JSON(http.StatusOK, gin.H{"token": token, "expires_at": expiresAt})
+	}
+}
+
+// KitchenEvents streams the feed as server-sent events. Browsers resend the
+// id of the last event they got in the Last-Event-ID header on reconnect.
+func KitchenEvents(broker *events.Broker) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		sub := broker.Subscribe(feedFilter(ctx), lastEventId(ctx))
+		defer sub.Close()
+
+		rc := http.NewResponseController(ctx.Writer)
+
+		ctx.Header("Content-Type", "text/event-stream")
+		ctx.Header("Cache-Control", "no-cache")
+		ctx.Header("Connection", "keep-alive")
+		ctx.Header("X-Accel-Buffering", "no")
+		ctx.Status(http.StatusOK)
+
+		write := func(format string, args ...interface{}) bool {
+			rc.SetWriteDeadline(time.Now().Add(feedWriteTimeout))
+			if _, err := fmt.Fprintf(ctx.Writer, format, args...); err != nil {
+				return false
+			}
+			return rc.Flush() == nil
+		}
+
+		if !write("retry: 3000\n\n") {
+			return
+		}
+
+		heartbeat := time.NewTicker(feedHeartbeat)
+		defer heartbeat.Stop()
+
+		for {
+			select {
+			case <-ctx.Request.Context().Done():
+				return
+			case <-heartbeat.C:
+				if !write(": ping\n\n") {
+					return
+				}
+			case e, ok := <-sub.C:
+				if !ok {
+					return
+				}
+
+				data, err := json.Marshal(e)
+				if err != nil {
+					continue
+				}
+
+				if e.ID == 0 {
+					// Resync has no id, so the client keeps asking from where it was.
+					if !write("event: %s\ndata: %s\n\n", e.Type, data) {
+						return
+					}
+					continue
+				}
+
+				if !write("id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// KitchenSocket streams the feed over a WebSocket as JSON messages. Clients
+// pass the id of the last event they saw as last_event_id when reconnecting.
+func KitchenSocket(broker *events.Broker) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		filter := feedFilter(ctx)
+		lastId := lastEventId(ctx)
+
+		conn, err := upgrader.Upgrade(ctx.Writer, ctx.Request, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		sub := broker.Subscribe(filter, lastId)
+		defer sub.Close()
+
+		// The reader only exists to answer pings and notice the client leaving.
+		closed := make(chan struct{})
+		conn.SetReadLimit(512)
+		conn.SetReadDeadline(time.Now().Add(2 * feedHeartbeat))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(2 * feedHeartbeat))
+		})
+		go func() {
+			defer close(closed)
+			for {
+				if _, _, err := conn.ReadMessage(); err != nil {
+					return
+				}
+			}
+		}()
+
+		heartbeat := time.NewTicker(feedHeartbeat)
+		defer heartbeat.Stop()
+
+		for {
+			select {
+			case <-closed:
+				return
+			case <-heartbeat.C:
+				if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(feedWriteTimeout)); err != nil {
+					return
+				}
+			case e, ok := <-sub.C:
+				if !ok {
+					conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, ""), time.Now().Add(feedWriteTimeout))
+					return
+				}
+
+				conn.SetWriteDeadline(time.Now().Add(feedWriteTimeout))
+				if err := conn.WriteJSON(e); err != nil {
+					return
+				}
+			}
+		}
+	}
+}
+
+// feedFilter reads the stations to follow from ?station=grill,bar. No station
+// means the whole restaurant.
+func feedFilter(ctx *gin.Context) events.Filter {
+	filter := events.Filter{Restaurant_id: ctx.GetString("restaurant_id")}
+
+	for _, value := range ctx.QueryArray("station") {
+		for _, station := range strings.Split(value, ",") {
+			station = strings.ToLower(strings.TrimSpace(station))
+			if station != "" {
+				filter.Stations = append(filter.Stations, station)
+			}
+		}
+	}
+
+	return filter
+}
+
+func lastEventId(ctx *gin.Context) int64 {
+	value := ctx.GetHeader("Last-Event-ID")
+	if value == "" {
+		value = ctx.Query("last_event_id")
+	}
+
+	id, _ := strconv.ParseInt(value, 10, 64)
+	return id
+}
+
+// ticketBuilder turns order items into kitchen tickets. The station comes from
+// the menu of the food; lookups are cached for the duration of one request.
+type ticketBuilder struct {
+	s      *store.Store
+	foods  map[string]models.Food
+	menus  map[string]models.Menu
+	tables map[string]models.Table
+}
+
+func newTicketBuilder(s *store.Store) *ticketBuilder {
+	return &ticketBuilder{
+		s:      s,
+		foods:  map[string]models.Food{},
+		menus:  map[string]models.Menu{},
+		tables: map[string]models.Table{},
+	}
+}
+
+func (k *ticketBuilder) food(c context.Context, restaurantId string, foodId string) models.Food {
+	food, ok := k.foods[foodId]
+	if !ok {
+		food, _ = k.s.Foods.Get(c, restaurantId, foodId)
+		k.foods[foodId] = food
+	}
+
+	return food
+}
+
+func (k *ticketBuilder) menu(c context.Context, restaurantId string, food models.Food) models.Menu {
+	if food.Menu_id == nil {
+		return models.Menu{}
+	}
+
+	menu, ok := k.menus[*food.Menu_id]
+	if !ok {
+		menu, _ = k.s.Menus.Get(c, restaurantId, *food.Menu_id)
+		k.menus[*food.Menu_id] = menu
+	}
+
+	return menu
+}
+
+func (k *ticketBuilder) tableNumber(c context.Context, restaurantId string, tableId *string) int {
+	if tableId == nil {
+		return 0
+	}
+
+	table, ok := k.tables[*tableId]
+	if !ok {
+		table, _ = k.s.Tables.Get(c, restaurantId, *tableId)
+		k.tables[*tableId] = table
+	}
+
+	return table.Table_number
+}
+
+// ticket builds the kitchen view of an order item.
+func (k *ticketBuilder) ticket(c context.Context, orderItem models.OrderItem, tableId *string) models.KitchenTicket {
+	var ticket models.KitchenTicket
+
+	ticket.Order_item_id = orderItem.Order_item_id
+	ticket.Quantity = orderItem.Quantity
+	ticket.Size = orderItem.Size
+	ticket.Modifiers = orderItem.Modifiers
+	ticket.Status = orderItem.CurrentStatus()
+	ticket.Created_at = orderItem.Created_at
+	ticket.Table_number = k.tableNumber(c, orderItem.Restaurant_id, tableId)
+	ticket.Station = models.StationKitchen
+
+	if orderItem.Order_id != nil {
+		ticket.Order_id = *orderItem.Order_id
+	}
+
+	if orderItem.Food_id != nil {
+		food := k.food(c, orderItem.Restaurant_id, *orderItem.Food_id)
+		ticket.Food_id = *orderItem.Food_id
+		ticket.Food_name = food.Name
+		menu := k.menu(c, orderItem.Restaurant_id, food)
+		ticket.Station = menu.KitchenStation()
+		ticket.Category = menu.Category
+	}
+
+	return ticket
+}
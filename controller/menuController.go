@@ -2,47 +2,43 @@ package controller
 
 import (
 	"context"
-	"log"
+	"errors"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/vikas-gouda/go-restraunt-mangement/models"
-	"go.mongodb.org/mongo-driver/bson"
+	"github.com/vikas-gouda/go-restraunt-mangement/store"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-func GetMenus() gin.HandlerFunc {
+func GetMenus(s *store.Store) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
-
-		result, err := menuCollection.Find(context.TODO(), bson.M{})
 		defer cancel()
+
+		allMenus, err := s.Menus.List(c, ctx.GetString("restaurant_id"))
 		if err != nil {
 			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error occured while listing the menu items"})
 			return
 		}
 
-		var allMenus []bson.M
-		if err = result.All(c, &allMenus); err != nil {
-			log.Fatal(err)
-			return
-		}
-
 		ctx.JSON(http.StatusOK, allMenus)
 	}
 }
 
-func GetMenu() gin.HandlerFunc {
+func GetMenu(s *store.Store) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
 
-		var menu models.Menu
 		var menuID = ctx.Param("menu_id")
 
-		err := menuCollection.FindOne(c, bson.M{"menu_id": menuID}).Decode(&menu)
-		defer cancel()
+		menu, err := s.Menus.Get(c, ctx.GetString("restaurant_id"), menuID)
+		if errors.Is(err, store.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "menu was not found"})
+			return
+		}
 
 		if err != nil {
 			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching the menu"})
@@ -54,9 +50,10 @@ func GetMenu() gin.HandlerFunc {
 	}
 }
 
-func CreateMenu() gin.HandlerFunc {
+func CreateMenu(s *store.Store) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
 
 		var menu models.Menu
 		if err := ctx.BindJSON(&menu); err != nil {
@@ -74,23 +71,22 @@ func CreateMenu() gin.HandlerFunc {
 		menu.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
 		menu.ID = primitive.NewObjectID()
 		menu.Menu_id = menu.ID.Hex()
+		menu.Restaurant_id = ctx.GetString("restaurant_id")
 
-		result, err := menuCollection.InsertOne(c, menu)
-		if err != nil {
+		if err := s.Menus.Create(c, menu); err != nil {
 			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while creating the menu"})
 			return
 		}
 
-		defer cancel()
-
-		ctx.JSON(http.StatusOK, result)
+		ctx.JSON(http.StatusOK, menu)
 
 	}
 }
 
-func UpdateMenu() gin.HandlerFunc {
+func UpdateMenu(s *store.Store) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
 
 		var menu models.Menu
 
@@ -100,48 +96,47 @@ func UpdateMenu() gin.HandlerFunc {
 		}
 
 		menuID := ctx.Param("menu_id")
-		filter := bson.M{"menu_id": menuID}
 
-		var updateObj primitive.D
+		foundMenu, err := s.Menus.Get(c, ctx.GetString("restaurant_id"), menuID)
+		if err != nil {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "menu was not found"})
+			return
+		}
 
 		if (menu.Start_date != time.Time{} && menu.End_date != time.Time{}) {
-			if !inTimeSpan(*&menu.Start_date, *&menu.End_date, time.Now()) {
+			if !inTimeSpan(menu.Start_date, menu.End_date, time.Now()) {
 				ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Kindly retype the time"})
-				defer cancel()
 				return
 			}
 
-			updateObj = append(updateObj, bson.E{"start_date", menu.Start_date})
-			updateObj = append(updateObj, bson.E{"end_date", menu.End_date})
-
-			if menu.Name != "" {
-				updateObj = append(updateObj, bson.E{"name", menu.Name})
-			}
-			if menu.Category != "" {
-				updateObj = append(updateObj, bson.E{"category", menu.Category})
-			}
-
-			menu.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
-
-			updateObj = append(updateObj, bson.E{"updated_at", menu.Updated_at})
-
-			upsert := true
+			foundMenu.Start_date = menu.Start_date
+			foundMenu.End_date = menu.End_date
+		}
 
-			opt := options.UpdateOptions{
-				Upsert: &upsert,
-			}
+		if menu.Name != "" {
+			foundMenu.Name = menu.Name
+		}
+		if menu.Category != "" {
+			foundMenu.Category = menu.Category
+		}
+		if menu.Station != "" {
+			foundMenu.Station = menu.Station
+		}
 
-			result, err := menuCollection.UpdateOne(c, filter, bson.D{
-				{"$set", updateObj},
-			}, &opt)
+		validationErr := validate.Struct(foundMenu)
+		if validationErr != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
 
-			if err != nil {
-				ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Menu Update Failed"})
-			}
+		foundMenu.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
 
-			defer cancel()
-			ctx.JSON(http.StatusOK, result)
+		if err := s.Menus.Update(c, foundMenu); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Menu Update Failed"})
+			return
 		}
+
+		ctx.JSON(http.StatusOK, foundMenu)
 	}
 }
 
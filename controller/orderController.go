@@ -2,53 +2,44 @@ package controller
 
 import (
 	"context"
-	"log"
+	"errors"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/vikas-gouda/go-restraunt-mangement/database"
+	"github.com/vikas-gouda/go-restraunt-mangement/events"
 	"github.com/vikas-gouda/go-restraunt-mangement/models"
-	"go.mongodb.org/mongo-driver/bson"
+	"github.com/vikas-gouda/go-restraunt-mangement/store"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-var orderCollection *mongo.Collection = database.OpenCollection(database.Client, "order")
-
-func GetOrders() gin.HandlerFunc {
+func GetOrders(s *store.Store) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
-
-		result, err := orderCollection.Find(context.Background(), bson.M{})
 		defer cancel()
 
+		allOrder, err := s.Orders.List(c, ctx.GetString("restaurant_id"))
 		if err != nil {
 			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while listing the order"})
 			return
 		}
 
-		var allOrder []bson.M
-		if err = result.All(c, &allOrder); err != nil {
-			log.Fatal(err.Error())
-			return
-		}
-
-		ctx.JSON(http.StatusOK, allOrder[0])
+		ctx.JSON(http.StatusOK, allOrder)
 	}
 }
 
-func GetOrder() gin.HandlerFunc {
+func GetOrder(s *store.Store) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
 
 		orderId := ctx.Param("order_id")
 
-		var order models.Order
-
-		err := orderCollection.FindOne(c, bson.M{"order_id": orderId}).Decode(&order)
-		defer cancel()
+		order, err := s.Orders.Get(c, ctx.GetString("restaurant_id"), orderId)
+		if errors.Is(err, store.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "order not found"})
+			return
+		}
 
 		if err != nil {
 			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -60,12 +51,12 @@ func GetOrder() gin.HandlerFunc {
 	}
 }
 
-func CreateOrder() gin.HandlerFunc {
+func CreateOrder(s *store.Store) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
 
 		var order models.Order
-		var table models.Order
 
 		if err := ctx.BindJSON(&order); err != nil {
 			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -78,36 +69,46 @@ func CreateOrder() gin.HandlerFunc {
 			return
 		}
 
-		err := tableCollection.FindOne(c, bson.M{"table_id": order.Table_id}).Decode(&table)
+		_, err := s.Tables.Get(c, ctx.GetString("restaurant_id"), *order.Table_id)
 		if err != nil {
 			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Table not found"})
 			return
 		}
 
+		// Whoever opens the order looks after it unless somebody else is named.
+		if order.Server_id == "" {
+			order.Server_id = ctx.GetString("uid")
+		} else if status, body := checkServer(c, s, ctx.GetString("restaurant_id"), order.Server_id); status != http.StatusOK {
+			ctx.JSON(status, body)
+			return
+		}
+
 		order.Order_Date, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
 		order.Created_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
 		order.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
 
 		order.ID = primitive.NewObjectID()
 		order.Order_id = order.ID.Hex()
+		order.Status = models.OrderOpen
+		order.Status_history = []models.OrderTransition{}
+		order.Discounts = []models.OrderDiscount{}
+		order.Restaurant_id = ctx.GetString("restaurant_id")
 
-		result, insertErr := orderCollection.InsertOne(c, order)
-		if insertErr != nil {
+		if err := s.Orders.Create(c, order); err != nil {
 			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while inserting"})
 			return
 		}
-		defer cancel()
 
-		ctx.JSON(http.StatusOK, result)
+		ctx.JSON(http.StatusOK, order)
 	}
 }
 
-func UpdateOrder() gin.HandlerFunc {
+func UpdateOrder(s *store.Store) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
 
 		var order models.Order
-		var table models.Table
 
 		if err := ctx.BindJSON(&order); err != nil {
 			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -115,49 +116,174 @@ func UpdateOrder() gin.HandlerFunc {
 		}
 
 		orderId := ctx.Param("order_id")
-		tableId := ctx.Param("table_id")
+		restaurantId := ctx.GetString("restaurant_id")
 
-		err := tableCollection.FindOne(c, bson.M{"table_id": tableId}).Decode(&table)
+		foundOrder, err := s.Orders.Get(c, restaurantId, orderId)
 		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Table not found"})
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "order not found"})
 			return
 		}
 
-		var updateObj primitive.D
+		if foundOrder.Closed() {
+			ctx.JSON(http.StatusConflict, gin.H{"error": "order is " + foundOrder.CurrentStatus() + " and can no longer be changed"})
+			return
+		}
 
-		order.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
-		updateObj = append(updateObj, bson.E{"updated_at", order.Updated_at})
+		if order.Table_id != nil {
+			_, err := s.Tables.Get(c, restaurantId, *order.Table_id)
+			if err != nil {
+				ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Table not found"})
+				return
+			}
 
-		upsert := true
-		filter := bson.M{"order_id": orderId}
-		opt := options.UpdateOptions{
-			Upsert: &upsert,
+			foundOrder.Table_id = order.Table_id
 		}
 
-		result, err := orderCollection.UpdateOne(c, filter, bson.D{
-			{"$set", updateObj},
-		}, &opt)
+		if order.Server_id != "" {
+			if status, body := checkServer(c, s, restaurantId, order.Server_id); status != http.StatusOK {
+				ctx.JSON(status, body)
+				return
+			}
 
-		if err != nil {
+			foundOrder.Server_id = order.Server_id
+		}
+
+		foundOrder.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+
+		if err := s.Orders.Update(c, foundOrder); err != nil {
 			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update the order"})
 			return
 		}
 
-		defer cancel()
-		ctx.JSON(http.StatusOK, result)
+		ctx.JSON(http.StatusOK, foundOrder)
 	}
 
 }
 
-func OrderItemOrderCreator(order models.Order) string {
-	var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
-	order.Created_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
-	order.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
-	order.ID = primitive.NewObjectID()
-	order.Order_id = order.ID.Hex()
+func TransitionOrder(s *store.Store, broker *events.Broker) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var transition models.OrderTransition
+
+		if err := ctx.BindJSON(&transition); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		validationErr := validate.Struct(transition)
+		if validationErr != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		orderId := ctx.Param("order_id")
+		restaurantId := ctx.GetString("restaurant_id")
+		role := ctx.GetString("role")
+
+		order, err := s.Orders.Get(c, restaurantId, orderId)
+		if errors.Is(err, store.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "order not found"})
+			return
+		}
+
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching the order"})
+			return
+		}
+
+		// Whatever the client claims, the transition starts where the order is now.
+		transition.From = order.CurrentStatus()
+
+		if !models.CanTransitionOrder(transition.From, transition.To) {
+			ctx.JSON(http.StatusConflict, gin.H{"error": "order can not move from " + transition.From + " to " + transition.To})
+			return
+		}
+
+		if !models.CanMoveOrderTo(role, transition.To) {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": "a " + role + " can not move an order to " + transition.To})
+			return
+		}
+
+		transition.At, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		transition.By = ctx.GetString("uid")
+		transition.Role = role
+
+		moved, err := s.Orders.Transition(c, restaurantId, orderId, transition)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update the order status"})
+			return
+		}
+
+		if !moved {
+			ctx.JSON(http.StatusConflict, gin.H{"error": "order status changed in the meantime, reload and try again"})
+			return
+		}
+
+		order, err = s.Orders.Get(c, restaurantId, orderId)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching the order"})
+			return
+		}
+
+		broker.Publish(events.Event{
+			Type:          events.OrderStatusChanged,
+			Restaurant_id: restaurantId,
+			Order_id:      orderId,
+			At:            transition.At,
+			Data:          transition,
+		})
+
+		ctx.JSON(http.StatusOK, order)
+	}
+}
+
+// rollupOrder moves the order forward to where its items say the kitchen is,
+// recording every status it passes through.
+func rollupOrder(c context.Context, s *store.Store, broker *events.Broker, restaurantId string, orderId string, by string, role string) error {
+	order, err := s.Orders.Get(c, restaurantId, orderId)
+	if err != nil {
+		return err
+	}
+
+	orderItems, err := s.OrderItems.ListByOrder(c, restaurantId, orderId)
+	if err != nil {
+		return err
+	}
 
-	orderCollection.InsertOne(c, order)
-	defer cancel()
+	from := order.CurrentStatus()
+
+	for _, to := range models.OrderPathTo(from, models.RollupOrderStatus(orderItems)) {
+		var transition models.OrderTransition
+
+		transition.From = from
+		transition.To = to
+		transition.At, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		transition.By = by
+		transition.Role = role
+		transition.Reason = "order items"
+
+		moved, err := s.Orders.Transition(c, restaurantId, orderId, transition)
+		if err != nil {
+			return err
+		}
+
+		// Somebody moved the order by hand meanwhile; theirs wins.
+		if !moved {
+			return nil
+		}
+
+		broker.Publish(events.Event{
+			Type:          events.OrderStatusChanged,
+			Restaurant_id: restaurantId,
+			Order_id:      orderId,
+			At:            transition.At,
+			Data:          transition,
+		})
+
+		from = to
+	}
 
-	return order.Order_id
+	return nil
 }
@@ -2,17 +2,18 @@ package controller
 
 import (
 	"context"
-	"log"
+	"errors"
+	"io"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/vikas-gouda/go-restraunt-mangement/database"
+	"github.com/vikas-gouda/go-restraunt-mangement/events"
 	"github.com/vikas-gouda/go-restraunt-mangement/models"
-	"go.mongodb.org/mongo-driver/bson"
+	"github.com/vikas-gouda/go-restraunt-mangement/money"
+	"github.com/vikas-gouda/go-restraunt-mangement/printing"
+	"github.com/vikas-gouda/go-restraunt-mangement/store"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type OrderItemPack struct {
@@ -20,36 +21,35 @@ type OrderItemPack struct {
 	Oder_items []models.OrderItem
 }
 
-var orderItemCollection = database.OpenCollection(database.Client, "orderItem")
-
-func GetOrderItems() gin.HandlerFunc {
+func GetOrderItems(s *store.Store) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
-
-		result, err := orderCollection.Find(context.TODO(), bson.M{})
 		defer cancel()
+
+		allOrderItems, err := s.OrderItems.List(c, ctx.GetString("restaurant_id"))
 		if err != nil {
 			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
 
-		var allOrderItems []bson.M
-		if err := result.All(c, &allOrderItems); err != nil {
-			log.Fatal(err)
-			return
-		}
-
 		ctx.JSON(http.StatusOK, allOrderItems)
 
 	}
 }
 
-func GetOrderItemsByOrder() gin.HandlerFunc {
+func GetOrderItemsByOrder(s *store.Store) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
+		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
 
 		orderId := ctx.Param("order_id")
 
-		allOrderItems, err := ItemsByOrder(orderId)
+		allOrderItems, err := ItemsByOrder(c, s, ctx.GetString("restaurant_id"), orderId)
+		if errors.Is(err, store.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "order not found"})
+			return
+		}
+
 		if err != nil {
 			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error occured while listing the order items by order id"})
 			return
@@ -59,83 +59,110 @@ func GetOrderItemsByOrder() gin.HandlerFunc {
 	}
 }
 
-func ItemsByOrder(id string) (OrderItems []primitive.M, err error) {
-	var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
-
-	matchStage := bson.D{{"$match", bson.D{{"order_id", id}}}}
-	lookupStage := bson.D{{"$lookup", bson.D{{"from", "food"}, {"localField", "food_id"}, {"foreignField", "food_id"}, {"as", "food"}}}}
-	unwindStage := bson.D{{"$unwind", bson.D{{"path", "$food"}, {"preserveNullAndEmptyArrays", true}}}}
-
-	lookupOrderStage := bson.D{{"$lookup", bson.D{{"from", "order"}, {"localField", "order_id"}, {"foreignField", "order_id"}, {"as", "order"}}}}
-	unwindOrderStage := bson.D{{"$unwind", bson.D{{"path", "$order"}, {"preserveNullAndEmptyArrays", true}}}}
-
-	lookupTableStage := bson.D{{"$lookup", bson.D{{"from", "table"}, {"localField", "order.table_id"}, {"foreignField", "table_id"}, {"as", "table"}}}}
-	unwindTableStage := bson.D{{"$unwind", bson.D{{"path", "$table"}, {"preserveNullAndEmptyArrays", true}}}}
-
-	projectStage := bson.D{
-		{
-			"$project", bson.D{
-				{"id", 0},
-				{"amount", "$food.price"},
-				{"total_count", 1},
-				{"food_name", "$food.name"},
-				{"food_image", "$food.food_image"},
-				{"table_number", "$table.table_number"},
-				{"table_id", "$table.table_id"},
-				{"order_id", "$order.order_id"},
-				{"price", "$food.price"},
-				{"quantity", 1},
-			},
-		},
-	}
+// ItemsByOrder joins the items of an order with their food and table and
+// totals up what is due for them.
+func ItemsByOrder(c context.Context, s *store.Store, restaurantId string, id string) (models.OrderSummary, error) {
+	var summary models.OrderSummary
 
-	groupStage := bson.D{
-		{"$group", bson.D{{"$group", bson.D{{"_id", bson.D{{"order_id", "$order_id "}, {"table_id", "$table_id"}, {"table_number", "$table_number"}}}, {"payment_due", bson.D{{"$sum", "$amount"}}}, {"total_count", bson.D{{"$sum", 1}}}, {"order_items", bson.D{{}}}}}}},
+	order, err := s.Orders.Get(c, restaurantId, id)
+	if err != nil {
+		return summary, err
 	}
 
-	projectStage2 := bson.D{
-		{"$project", bson.D{
-			{"id", 0},
-			{"payment_due", 1},
-			{"table_number", "$_id.table_number"},
-			{"order_items", 1},
-		}},
+	currency, _, err := restaurantMoney(c, s, restaurantId)
+	if err != nil {
+		return summary, err
 	}
 
-	result, err := orderItemCollection.Aggregate(c, mongo.Pipeline{
-		matchStage,
-		lookupStage,
-		unwindStage,
-		lookupOrderStage,
-		unwindOrderStage,
-		lookupTableStage,
-		unwindTableStage,
-		projectStage,
-		groupStage,
-		projectStage2,
-	})
+	summary.Order_id = order.Order_id
+	summary.Payment_due = money.Zero(currency)
+	summary.Order_status = order.CurrentStatus()
+	summary.Order_items = []models.OrderLine{}
+
+	if order.Table_id != nil {
+		table, err := s.Tables.Get(c, restaurantId, *order.Table_id)
+		if err != nil && !errors.Is(err, store.ErrNotFound) {
+			return summary, err
+		}
+
+		summary.Table_id = table.Table_id
+		summary.Table_number = table.Table_number
+		summary.Number_of_guests = table.Number_of_guests
+	}
 
+	orderItems, err := s.OrderItems.ListByOrder(c, restaurantId, id)
 	if err != nil {
-		panic(err)
+		return summary, err
 	}
 
-	if err = result.All(c, &OrderItems); err != nil {
-		panic(err)
+	foods := map[string]models.Food{}
+
+	for _, orderItem := range orderItems {
+		var line models.OrderLine
+
+		line.Order_item_id = orderItem.Order_item_id
+		line.Quantity = orderItem.Quantity
+		line.Size = orderItem.Size
+		line.Seat = orderItem.Seat
+		line.Status = orderItem.CurrentStatus()
+		line.Unit_price = orderItem.Unit_price
+
+		if orderItem.Food_id != nil {
+			food, ok := foods[*orderItem.Food_id]
+			if !ok {
+				food, err = s.Foods.Get(c, restaurantId, *orderItem.Food_id)
+				if err != nil && !errors.Is(err, store.ErrNotFound) {
+					return summary, err
+				}
+				foods[*orderItem.Food_id] = food
+			}
+
+			line.Food_id = *orderItem.Food_id
+			line.Food_name = food.Name
+			line.Food_image = food.Food_image
+			line.Price = food.Price
+			line.Tax_category = food.Tax_category
+		}
+
+		line.Modifiers = orderItem.Modifiers
+		if line.Modifiers == nil {
+			line.Modifiers = []models.ChosenModifier{}
+		}
+		line.Modifiers_total = models.ModifiersTotal(orderItem.Modifiers, currency)
+
+		line.Amount = orderItem.Line_total
+
+		summary.Order_items = append(summary.Order_items, line)
+
+		// Voided items stay on the list but are neither counted nor charged.
+		if line.Status == models.ItemVoided {
+			continue
+		}
+
+		summary.Payment_due = summary.Payment_due.Add(line.Amount)
+		summary.Total_count += line.Quantity
+
+		if line.Status == models.ItemReady || line.Status == models.ItemServed {
+			summary.Ready_count += line.Quantity
+		}
 	}
 
-	defer cancel()
-	return OrderItems, err
+	return summary, nil
 }
 
-func GetOrderItem() gin.HandlerFunc {
+func GetOrderItem(s *store.Store) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
 
 		orderItemId := ctx.Param("order_item_id")
-		var orderItem models.OrderItem
 
-		err := orderItemCollection.FindOne(c, bson.M{"order_item_id": orderItemId}).Decode(&orderItem)
-		defer cancel()
+		orderItem, err := s.OrderItems.Get(c, ctx.GetString("restaurant_id"), orderItemId)
+		if errors.Is(err, store.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "order item not found"})
+			return
+		}
+
 		if err != nil {
 			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error occured while listing the item"})
 			return
@@ -145,9 +172,10 @@ func GetOrderItem() gin.HandlerFunc {
 	}
 }
 
-func CreateOrderItem() gin.HandlerFunc {
+func CreateOrderItem(s *store.Store, broker *events.Broker, queue *printing.Queue) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
 
 		var OrderItemPack OrderItemPack
 		var order models.Order
@@ -157,17 +185,54 @@ func CreateOrderItem() gin.HandlerFunc {
 			return
 		}
 
+		if len(OrderItemPack.Oder_items) == 0 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "at least one order item is required"})
+			return
+		}
+
+		restaurantId := ctx.GetString("restaurant_id")
+
+		_, err := s.Tables.Get(c, restaurantId, OrderItemPack.Table_id)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Table not found"})
+			return
+		}
+
 		order.Order_Date, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
-		orderItemsToBeInserted := []interface{}{}
+		order.Created_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		order.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		order.ID = primitive.NewObjectID()
+		order.Order_id = order.ID.Hex()
 		order.Table_id = &OrderItemPack.Table_id
-		order_id := OrderItemOrderCreator(order)
+		order.Status = models.OrderOpen
+		order.Status_history = []models.OrderTransition{}
+		order.Discounts = []models.OrderDiscount{}
+		order.Server_id = ctx.GetString("uid")
+		order.Restaurant_id = restaurantId
+
+		orderItemsToBeInserted := []models.OrderItem{}
 
+		// Everything is checked before anything is written, so a bad item
+		// can't leave half an order behind.
 		for _, orderItem := range OrderItemPack.Oder_items {
-			orderItem.Order_id = &order_id
+			orderItem.Order_id = &order.Order_id
+			orderItem.Restaurant_id = restaurantId
 
 			validationErr := validate.Struct(orderItem)
 			if validationErr != nil {
-				ctx.JSON(http.StatusBadRequest, gin.H{"error": validationErr})
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+				return
+			}
+
+			food, err := s.Foods.Get(c, restaurantId, *orderItem.Food_id)
+			if err != nil {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": "food was not found"})
+				return
+			}
+
+			orderItem.Modifiers, err = food.ResolveModifiers(orderItem.Modifiers)
+			if err != nil {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 				return
 			}
 
@@ -175,63 +240,257 @@ func CreateOrderItem() gin.HandlerFunc {
 			orderItem.Created_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
 			orderItem.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
 			orderItem.Order_item_id = orderItem.ID.Hex()
+			models.StampItemStatus(&orderItem, models.ItemQueued, orderItem.Created_at)
 
-			var num = toFixed(orderItem.Unit_price, 2)
-			orderItem.Unit_price = num
+			// The price is taken from the menu, not from the client.
+			orderItem.Unit_price = food.Price
+			orderItem.Line_total = orderItem.LineTotal()
 			orderItemsToBeInserted = append(orderItemsToBeInserted, orderItem)
 		}
 
-		result, err := orderItemCollection.InsertMany(c, orderItemsToBeInserted)
-		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		if err := s.Orders.CreateWithItems(c, order, orderItemsToBeInserted); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Order was not created"})
 			return
 		}
-		defer cancel()
-		ctx.JSON(http.StatusOK, result)
+
+		tickets := newTicketBuilder(s)
+		printed := []models.KitchenTicket{}
+		for _, orderItem := range orderItemsToBeInserted {
+			ticket := tickets.ticket(c, orderItem, order.Table_id)
+			broker.Publish(events.Event{
+				Type:          events.OrderItemCreated,
+				Restaurant_id: orderItem.Restaurant_id,
+				Order_id:      ticket.Order_id,
+				Station:       ticket.Station,
+				Data:          ticket,
+			})
+			printed = append(printed, ticket)
+		}
+
+		printKitchenTickets(c, s, queue, restaurantId, printed)
+
+		ctx.JSON(http.StatusOK, gin.H{"order": order, "order_items": orderItemsToBeInserted})
 	}
 }
 
-func UpdateOrderItem() gin.HandlerFunc {
+func UpdateOrderItem(s *store.Store) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
 
 		var orderItem models.OrderItem
 
+		if err := ctx.BindJSON(&orderItem); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
 		orderItemId := ctx.Param("order_item_id")
-		filter := bson.M{"order_item_id": orderItemId}
+		restaurantId := ctx.GetString("restaurant_id")
+
+		foundOrderItem, err := s.OrderItems.Get(c, restaurantId, orderItemId)
+		if err != nil {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "order item not found"})
+			return
+		}
 
-		var updateObj primitive.D
+		if status := foundOrderItem.CurrentStatus(); status == models.ItemServed || status == models.ItemVoided {
+			ctx.JSON(http.StatusConflict, gin.H{"error": "order item is " + status + " and can no longer be changed"})
+			return
+		}
 
-		if orderItem.Unit_price != 0.0 {
-			updateObj = append(updateObj, bson.E{"unit_price", orderItem.Unit_price})
+		order, err := s.Orders.Get(c, restaurantId, *foundOrderItem.Order_id)
+		if err != nil {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "order not found"})
+			return
 		}
 
-		if orderItem.Quantity != "" {
-			updateObj = append(updateObj, bson.E{"quantity", orderItem.Quantity})
+		if order.Closed() {
+			ctx.JSON(http.StatusConflict, gin.H{"error": "order is " + order.CurrentStatus() + " and can no longer be changed"})
+			return
+		}
+
+		// The invoice keeps what the item cost when it was made out; void the
+		// invoice first to change it.
+		invoice, billed, err := invoiceOfItem(c, s, restaurantId, order.Order_id, foundOrderItem.Order_item_id)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching the invoices of the order"})
+			return
+		}
+
+		if billed {
+			ctx.JSON(http.StatusConflict, gin.H{"error": "order item is on invoice " + invoice.Invoice_id + " and can no longer be changed"})
+			return
+		}
+
+		// The price always comes from the menu; lowering it is what discounts
+		// and their approvals are for.
+		if orderItem.Quantity != 0 {
+			foundOrderItem.Quantity = orderItem.Quantity
+		}
+
+		if orderItem.Size != "" {
+			foundOrderItem.Size = orderItem.Size
+		}
+
+		if orderItem.Seat != 0 {
+			foundOrderItem.Seat = orderItem.Seat
 		}
 
 		if orderItem.Food_id != nil {
-			updateObj = append(updateObj, bson.E{"food_id", orderItem.Food_id})
+			foundOrderItem.Food_id = orderItem.Food_id
 		}
 
-		orderItem.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
-		updateObj = append(updateObj, bson.E{"updated_at", orderItem.Updated_at})
+		// Picks are checked again whenever the food or the picks change.
+		if orderItem.Food_id != nil || orderItem.Modifiers != nil {
+			food, err := s.Foods.Get(c, restaurantId, *foundOrderItem.Food_id)
+			if err != nil {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": "food was not found"})
+				return
+			}
+
+			chosen := foundOrderItem.Modifiers
+			if orderItem.Modifiers != nil {
+				chosen = orderItem.Modifiers
+			}
+
+			foundOrderItem.Modifiers, err = food.ResolveModifiers(chosen)
+			if err != nil {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			if orderItem.Food_id != nil {
+				foundOrderItem.Unit_price = food.Price
+			}
+		}
 
-		upsert := true
-		opt := options.UpdateOptions{
-			Upsert: &upsert,
+		validationErr := validate.Struct(foundOrderItem)
+		if validationErr != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
 		}
 
-		result, err := orderCollection.UpdateOne(c, filter, bson.D{
-			{"$set", updateObj},
-		}, &opt)
+		foundOrderItem.Line_total = foundOrderItem.LineTotal()
+		foundOrderItem.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
 
-		if err != nil {
+		if err := s.OrderItems.Update(c, foundOrderItem); err != nil {
 			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Order item update failed"})
 			return
 		}
 
+		ctx.JSON(http.StatusOK, foundOrderItem)
+	}
+}
+
+// BumpOrderItem moves an item to its next status, or to the one in the body,
+// and rolls the change up to the order.
+func BumpOrderItem(s *store.Store, broker *events.Broker) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
 		defer cancel()
-		ctx.JSON(http.StatusOK, result)
+
+		var bump models.ItemBump
+
+		if err := ctx.ShouldBindJSON(&bump); err != nil && !errors.Is(err, io.EOF) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		validationErr := validate.Struct(bump)
+		if validationErr != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		orderItemId := ctx.Param("order_item_id")
+		restaurantId := ctx.GetString("restaurant_id")
+		role := ctx.GetString("role")
+
+		orderItem, err := s.OrderItems.Get(c, restaurantId, orderItemId)
+		if err != nil {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "order item not found"})
+			return
+		}
+
+		order, err := s.Orders.Get(c, restaurantId, *orderItem.Order_id)
+		if err != nil {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "order not found"})
+			return
+		}
+
+		if order.Closed() {
+			ctx.JSON(http.StatusConflict, gin.H{"error": "order is " + order.CurrentStatus() + " and can no longer be changed"})
+			return
+		}
+
+		from := orderItem.CurrentStatus()
+		to := bump.To
+		if to == "" {
+			to = models.NextItemStatus(from)
+		}
+
+		if to == models.ItemVoided {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "order items are voided with POST /orderItems/:order_item_id/void and a reason"})
+			return
+		}
+
+		if to == "" || !models.CanTransitionItem(from, to) {
+			ctx.JSON(http.StatusConflict, gin.H{"error": "order item can not move from " + from + " to " + to})
+			return
+		}
+
+		if !models.CanMoveItemTo(role, to) {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": "a " + role + " can not move an order item to " + to})
+			return
+		}
+
+		orderItem, status, body := moveOrderItem(c, s, broker, order, orderItem, from, to, ctx.GetString("uid"), role)
+		if status != http.StatusOK {
+			ctx.JSON(status, body)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, orderItem)
+	}
+}
+
+// moveOrderItem moves an item from one status to the next, tells the kitchen
+// and rolls the order status up. Anything but 200 is the response to give
+// instead.
+func moveOrderItem(c context.Context, s *store.Store, broker *events.Broker, order models.Order, orderItem models.OrderItem, from string, to string, uid string, role string) (models.OrderItem, int, gin.H) {
+	restaurantId := order.Restaurant_id
+	orderItemId := orderItem.Order_item_id
+
+	at, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+
+	moved, err := s.OrderItems.Transition(c, restaurantId, orderItemId, from, to, at)
+	if err != nil {
+		return orderItem, http.StatusInternalServerError, gin.H{"error": "Failed to update the order item status"}
 	}
+
+	if !moved {
+		return orderItem, http.StatusConflict, gin.H{"error": "order item status changed in the meantime, reload and try again"}
+	}
+
+	orderItem, err = s.OrderItems.Get(c, restaurantId, orderItemId)
+	if err != nil {
+		return orderItem, http.StatusInternalServerError, gin.H{"error": "Error while fetching the order item"}
+	}
+
+	ticket := newTicketBuilder(s).ticket(c, orderItem, order.Table_id)
+	broker.Publish(events.Event{
+		Type:          events.OrderItemStatusChanged,
+		Restaurant_id: restaurantId,
+		Order_id:      ticket.Order_id,
+		Station:       ticket.Station,
+		At:            at,
+		Data:          ticket,
+	})
+
+	if err := rollupOrder(c, s, broker, restaurantId, order.Order_id, uid, role); err != nil {
+		return orderItem, http.StatusInternalServerError, gin.H{"error": "order item was updated but the order status was not"}
+	}
+
+	return orderItem, http.StatusOK, nil
 }
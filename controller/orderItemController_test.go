@@ -0,0 +1,136 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vikas-gouda/go-restraunt-mangement/models"
+)
+
+func TestOrderItemsTakeTheMenuPrice(t *testing.T) {
+	a := newTestApp(t)
+	burger := a.food("Burger", 1250, "")
+	table := a.table(2)
+
+	a.expect(a.do(http.MethodPost, "/orderItems", gin.H{
+		"table_id":   "no-such-table",
+		"oder_items": []gin.H{{"food_id": burger.Food_id, "quantity": 1}},
+	}), http.StatusBadRequest, nil)
+
+	var created struct {
+		Order       models.Order       `json:"order"`
+		Order_items []models.OrderItem `json:"order_items"`
+	}
+	a.expect(a.do(http.MethodPost, "/orderItems", gin.H{
+		"table_id":   table.Table_id,
+		"oder_items": []gin.H{{"food_id": burger.Food_id, "quantity": 2, "unit_price": usd(1)}},
+	}), http.StatusOK, &created)
+
+	orderItem := created.Order_items[0]
+	if orderItem.Unit_price != usd(1250) || orderItem.Line_total != usd(2500) {
+		t.Errorf("unit price %v and line total %v, want 1250 and 2500 from the menu", orderItem.Unit_price, orderItem.Line_total)
+	}
+
+	if created.Order.Status != models.OrderOpen || created.Order.Server_id != a.owner.User_id {
+		t.Errorf("order is %s served by %q, want OPEN by the caller", created.Order.Status, created.Order.Server_id)
+	}
+
+	stored, err := a.s.OrderItems.ListByOrder(context.Background(), a.restaurant.Restaurant_id, created.Order.Order_id)
+	if err != nil || len(stored) != 1 {
+		t.Fatalf("stored items = %v, %v; want the one item", stored, err)
+	}
+}
+
+func TestOrderItemsStayInTheirRestaurant(t *testing.T) {
+	a := newTestApp(t)
+	burger := a.food("Burger", 1000, "")
+	_, items := a.order(a.table(2), orderedItem{food: burger, quantity: 1})
+
+	a.as = a.user(models.RoleManager, "another-restaurant")
+	a.expect(a.do(http.MethodPatch, "/orderItems/"+items[0].Order_item_id, gin.H{"quantity": 3}), http.StatusNotFound, nil)
+}
+
+func TestOrderItemPriceCanNotBeChanged(t *testing.T) {
+	a := newTestApp(t)
+	_, items := a.order(a.table(2), orderedItem{food: a.food("Burger", 1000, ""), quantity: 1})
+
+	a.as = a.user(models.RoleWaiter, a.restaurant.Restaurant_id)
+
+	var updated models.OrderItem
+	a.expect(a.do(http.MethodPatch, "/orderItems/"+items[0].Order_item_id, gin.H{"quantity": 2, "unit_price": usd(1)}), http.StatusOK, &updated)
+
+	if updated.Unit_price != usd(1000) || updated.Line_total != usd(2000) {
+		t.Errorf("unit price %v and line total %v, want 1000 and 2000 from the menu", updated.Unit_price, updated.Line_total)
+	}
+}
+
+func TestModifiersArePricedIntoTheLine(t *testing.T) {
+	a := newTestApp(t)
+	table := a.table(2)
+
+	burger := a.food("Burger", 1000, "")
+	burger.Modifier_groups = []models.ModifierGroup{
+		{Group_id: "size", Name: "Size", Min_select: 1, Max_select: 1, Options: []models.ModifierOption{
+			{Option_id: "regular", Name: "Regular", Price_delta: usd(0)},
+			{Option_id: "double", Name: "Double", Price_delta: usd(350)},
+		}},
+		{Group_id: "extras", Name: "Extras", Max_select: 2, Options: []models.ModifierOption{
+			{Option_id: "cheese", Name: "Cheese", Price_delta: usd(100)},
+			{Option_id: "bacon", Name: "Bacon", Price_delta: usd(175)},
+			{Option_id: "egg", Name: "Egg", Price_delta: usd(80)},
+		}},
+	}
+	if err := a.s.Foods.Update(context.Background(), burger); err != nil {
+		t.Fatal(err)
+	}
+
+	pick := func(group string, option string) gin.H {
+		return gin.H{"group_id": group, "option_id": option}
+	}
+
+	for _, test := range []struct {
+		name      string
+		quantity  int
+		modifiers []gin.H
+		status    int
+		total     int64
+	}{
+		{"plain", 1, []gin.H{pick("size", "regular")}, http.StatusOK, 1000},
+		{"double with extras", 1, []gin.H{pick("size", "double"), pick("extras", "cheese"), pick("extras", "bacon")}, http.StatusOK, 1625},
+		{"times quantity", 3, []gin.H{pick("size", "double"), pick("extras", "egg")}, http.StatusOK, 4290},
+		{"required group left out", 1, []gin.H{pick("extras", "cheese")}, http.StatusBadRequest, 0},
+		{"too many extras", 1, []gin.H{pick("size", "regular"), pick("extras", "cheese"), pick("extras", "bacon"), pick("extras", "egg")}, http.StatusBadRequest, 0},
+		{"picked twice", 1, []gin.H{pick("size", "regular"), pick("extras", "egg"), pick("extras", "egg")}, http.StatusBadRequest, 0},
+		{"unknown option", 1, []gin.H{pick("size", "triple")}, http.StatusBadRequest, 0},
+		{"no quantity", 0, []gin.H{pick("size", "regular")}, http.StatusBadRequest, 0},
+	} {
+		w := a.do(http.MethodPost, "/orderItems", gin.H{
+			"table_id":   table.Table_id,
+			"oder_items": []gin.H{{"food_id": burger.Food_id, "quantity": test.quantity, "modifiers": test.modifiers}},
+		})
+		if w.Code != test.status {
+			t.Errorf("%s: got %d, want %d: %s", test.name, w.Code, test.status, w.Body.String())
+			continue
+		}
+
+		if test.status != http.StatusOK {
+			continue
+		}
+
+		var created struct {
+			Order_items []models.OrderItem `json:"order_items"`
+		}
+		a.expect(w, http.StatusOK, &created)
+
+		orderItem := created.Order_items[0]
+		if orderItem.Line_total != usd(test.total) || orderItem.Unit_price != usd(1000) {
+			t.Errorf("%s: unit price %v and line total %v, want 1000 and %d", test.name, orderItem.Unit_price, orderItem.Line_total, test.total)
+		}
+
+		if len(orderItem.Modifiers) != len(test.modifiers) || orderItem.Modifiers[0].Group_name != "Size" {
+			t.Errorf("%s: modifiers %+v are not resolved from the food", test.name, orderItem.Modifiers)
+		}
+	}
+}
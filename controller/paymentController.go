@@ -0,0 +1,332 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vikas-gouda/go-restraunt-mangement/gateway"
+	"github.com/vikas-gouda/go-restraunt-mangement/models"
+	"github.com/vikas-gouda/go-restraunt-mangement/money"
+	"github.com/vikas-gouda/go-restraunt-mangement/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func GetInvoicePayments(s *store.Store) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		allPayments, err := s.Payments.ListByInvoice(c, ctx.GetString("restaurant_id"), ctx.Param("invoice_id"))
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, allPayments)
+	}
+}
+
+// CreatePayment records one tender against an invoice and works out the
+// payment status of the invoice again. With a provider, card and wallet
+// payments are charged through it before anything is recorded.
+func CreatePayment(s *store.Store, provider gateway.Provider) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var payment models.Payment
+
+		if err := ctx.BindJSON(&payment); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		validationErr := validate.Struct(payment)
+		if validationErr != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		restaurantId := ctx.GetString("restaurant_id")
+
+		invoice, err := s.Invoices.Get(c, restaurantId, ctx.Param("invoice_id"))
+		if err != nil {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "invoice not found"})
+			return
+		}
+
+		if invoice.Voided() {
+			ctx.JSON(http.StatusConflict, gin.H{"error": "invoice was voided and can no longer be paid"})
+			return
+		}
+
+		currency, rounding, err := restaurantMoney(c, s, restaurantId)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching the restaurant"})
+			return
+		}
+
+		if err := preparePayment(&payment, currency, rounding); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		payment.Created_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		payment.ID = primitive.NewObjectID()
+		payment.Payment_id = payment.ID.Hex()
+		payment.Invoice_id = invoice.Invoice_id
+		payment.Order_id = *invoice.Order_id
+		payment.Taken_by = ctx.GetString("uid")
+		payment.Role = ctx.GetString("role")
+		payment.Restaurant_id = restaurantId
+
+		order, err := s.Orders.Get(c, restaurantId, *invoice.Order_id)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching the order"})
+			return
+		}
+
+		payment.Server_id = order.Server_id
+		if payment.Server_id == "" {
+			payment.Server_id = payment.Taken_by
+		}
+
+		invoiceView, err := viewInvoice(c, s, invoice)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while billing the invoice"})
+			return
+		}
+
+		// The service charge is paid off along with the rest of the bill; what
+		// goes beyond the balance pays none of it.
+		payment.Gratuity = money.Zero(currency)
+		if !invoiceView.Gratuity.IsZero() && invoiceView.Total.Amount > 0 {
+			covered := payment.Amount
+			if invoiceView.Balance.Cmp(covered) < 0 {
+				covered = invoiceView.Balance
+			}
+			if covered.Amount > 0 {
+				payment.Gratuity = covered.Ratio(invoiceView.Gratuity.Amount, invoiceView.Total.Amount, rounding)
+			}
+		}
+
+		if provider != nil && (payment.Tender == models.TenderCard || payment.Tender == models.TenderWallet) {
+			status, body := chargePayment(c, provider, &payment)
+			if status != http.StatusOK {
+				ctx.JSON(status, body)
+				return
+			}
+		}
+		payment.Card_token = ""
+
+		updated, added, err := s.Invoices.AddPaid(c, restaurantId, invoice.Invoice_id, payment.Amount)
+		if err != nil || !added {
+			voidPayment(provider, payment)
+		}
+
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record the payment"})
+			return
+		}
+
+		if !added {
+			ctx.JSON(http.StatusConflict, gin.H{"error": "invoice was voided and can no longer be paid"})
+			return
+		}
+
+		if err := s.Payments.Create(c, payment); err != nil {
+			s.Invoices.AddPaid(context.Background(), restaurantId, invoice.Invoice_id, payment.Amount.Neg())
+			voidPayment(provider, payment)
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record the payment"})
+			return
+		}
+
+		invoiceView, err = settleInvoice(c, s, updated)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "payment was recorded but the invoice status was not updated"})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"payment": payment, "invoice": invoiceView})
+	}
+}
+
+// chargePayment authorizes and captures amount and tip with the provider and
+// puts the transaction on the payment. Anything but 200 is the response to
+// give instead.
+func chargePayment(c context.Context, provider gateway.Provider, payment *models.Payment) (int, gin.H) {
+	if payment.Card_token == "" {
+		return http.StatusBadRequest, gin.H{"error": "card_token is required for " + strings.ToLower(payment.Tender) + " payments"}
+	}
+
+	charged := payment.Amount.Add(payment.Tip)
+
+	result, err := provider.Authorize(c, gateway.Charge{
+		Amount:      charged,
+		Reference:   payment.Payment_id,
+		Card_token:  payment.Card_token,
+		Description: "Invoice " + payment.Invoice_id,
+	})
+	if status, body := gatewayError(err, result); status != http.StatusOK {
+		return status, body
+	}
+
+	payment.Provider = provider.Name()
+	payment.Transaction_id = result.Transaction_id
+
+	result, err = provider.Capture(c, result.Transaction_id, charged)
+	if status, body := gatewayError(err, result); status != http.StatusOK {
+		voidPayment(provider, *payment)
+		return status, body
+	}
+
+	payment.Provider_status = result.Status
+	return http.StatusOK, nil
+}
+
+func gatewayError(err error, result gateway.Result) (int, gin.H) {
+	switch {
+	case err == nil:
+		return http.StatusOK, nil
+	case errors.Is(err, gateway.ErrDeclined):
+		return http.StatusPaymentRequired, gin.H{"error": "payment was declined", "decline_reason": result.Decline_reason}
+	case errors.Is(err, gateway.ErrTimeout):
+		return http.StatusGatewayTimeout, gin.H{"error": "payment provider did not answer in time, check the transaction before charging again"}
+	default:
+		return http.StatusBadGateway, gin.H{"error": "payment provider failed: " + err.Error()}
+	}
+}
+
+// voidPayment gives the money back for a charge that could not be recorded.
+// A failure is only logged; the provider's webhook will show the charge.
+func voidPayment(provider gateway.Provider, payment models.Payment) {
+	if provider == nil || payment.Transaction_id == "" {
+		return
+	}
+
+	if _, err := provider.Void(context.Background(), payment.Transaction_id); err != nil {
+		log.Printf("voiding transaction %s of payment %s: %v", payment.Transaction_id, payment.Payment_id, err)
+	}
+}
+
+// PaymentWebhook takes the status updates a provider sends for its
+// transactions. It is not behind authentication; the signature is the proof.
+func PaymentWebhook(s *store.Store, provider gateway.Provider) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		body, err := io.ReadAll(io.LimitReader(ctx.Request.Body, 1<<20))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		event, err := provider.VerifyWebhook(ctx.Request.Header, body)
+		if err != nil {
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+
+		err = s.Payments.SetProviderStatus(c, provider.Name(), event.Transaction_id, event.Status)
+		if errors.Is(err, store.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "no payment has that transaction"})
+			return
+		}
+
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update the payment"})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"transaction_id": event.Transaction_id, "status": event.Status})
+	}
+}
+
+// settleInvoice stores the payment status and method that follow from the
+// payments on the invoice and returns the invoice as billed now.
+func settleInvoice(c context.Context, s *store.Store, invoice models.Invoice) (InvoiceViewFormat, error) {
+	invoiceView, err := viewInvoice(c, s, invoice)
+	if err != nil {
+		return invoiceView, err
+	}
+
+	method := ""
+	for _, payment := range invoiceView.Payments {
+		switch {
+		case method == "":
+			method = payment.Tender
+		case method != payment.Tender:
+			method = models.MixedTender
+		}
+	}
+
+	err = s.Invoices.SetPaymentStatus(c, invoice.Restaurant_id, invoice.Invoice_id, invoice.Paid, *invoiceView.Payment_status, method)
+	if err != nil {
+		return invoiceView, err
+	}
+
+	invoiceView.Payment_method = method
+	return invoiceView, nil
+}
+
+// preparePayment checks the amounts of a payment, works out a tip given as a
+// share and the change. Only cash gives change; any other tender is for
+// exactly amount and tip.
+func preparePayment(payment *models.Payment, currency string, rounding money.Rounding) error {
+	for _, amount := range []*money.Money{&payment.Amount, &payment.Tip, &payment.Tendered} {
+		if amount.Currency == "" {
+			amount.Currency = currency
+		}
+
+		if amount.Currency != currency {
+			return fmt.Errorf("payments must be in %s", currency)
+		}
+
+		if amount.IsNegative() {
+			return errors.New("payment amounts can not be negative")
+		}
+	}
+
+	if payment.Tip_basis_points > 0 {
+		if !payment.Tip.IsZero() {
+			return errors.New("give either a tip or tip_basis_points")
+		}
+
+		payment.Tip = payment.Amount.Percent(payment.Tip_basis_points, rounding)
+	}
+
+	charged := payment.Amount.Add(payment.Tip)
+	if charged.IsZero() {
+		return errors.New("a payment needs an amount or a tip")
+	}
+
+	if payment.Tendered.IsZero() {
+		payment.Tendered = charged
+	}
+
+	if payment.Tender != models.TenderCash && payment.Tendered.Cmp(charged) != 0 {
+		return errors.New("only cash payments can be tendered for more than amount and tip")
+	}
+
+	if payment.Tendered.Cmp(charged) < 0 {
+		return errors.New("tendered is less than amount and tip")
+	}
+
+	payment.Change = payment.Tendered.Sub(charged)
+	payment.Refunded = money.Zero(currency)
+	payment.Tip_refunded = money.Zero(currency)
+
+	// Only a charge through the provider says where the money went.
+	payment.Provider = ""
+	payment.Transaction_id = ""
+	payment.Provider_status = ""
+	return nil
+}
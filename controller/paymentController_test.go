@@ -0,0 +1,224 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vikas-gouda/go-restraunt-mangement/gateway"
+	"github.com/vikas-gouda/go-restraunt-mangement/gateway/mock"
+	"github.com/vikas-gouda/go-restraunt-mangement/models"
+)
+
+type paymentResponse struct {
+	Payment models.Payment    `json:"payment"`
+	Invoice InvoiceViewFormat `json:"invoice"`
+}
+
+// billed opens an order for 1000 and invoices it.
+func billed(a *testApp) models.Invoice {
+	a.t.Helper()
+
+	order, _ := a.order(a.table(2), orderedItem{food: a.food("Burger", 1000, ""), quantity: 1})
+	return a.invoice(order)
+}
+
+func TestCardPaymentIsChargedThroughTheProvider(t *testing.T) {
+	a := newTestApp(t)
+	invoice := billed(a)
+
+	var paid paymentResponse
+	a.expect(a.do(http.MethodPost, "/invoices/"+invoice.Invoice_id+"/payments", gin.H{
+		"tender":     models.TenderCard,
+		"amount":     usd(1000),
+		"tip":        usd(150),
+		"card_token": "tok_visa",
+	}), http.StatusOK, &paid)
+
+	if paid.Payment.Provider != "mock" || paid.Payment.Transaction_id == "" || paid.Payment.Provider_status != gateway.Captured {
+		t.Errorf("payment went through %q as %q, %s; want a captured mock transaction", paid.Payment.Provider, paid.Payment.Transaction_id, paid.Payment.Provider_status)
+	}
+
+	if paid.Payment.Card_token != "" {
+		t.Error("the card token was kept on the payment")
+	}
+
+	if *paid.Invoice.Payment_status != models.InvoicePaid || paid.Invoice.Payment_method != models.TenderCard {
+		t.Errorf("invoice is %s by %s, want PAID by CARD", *paid.Invoice.Payment_status, paid.Invoice.Payment_method)
+	}
+
+	// The tip was charged along with the amount, so all of it can go back.
+	result, err := a.payments.Refund(context.Background(), paid.Payment.Transaction_id, usd(1150))
+	if err != nil || result.Status != gateway.Refunded {
+		t.Errorf("refunding the whole charge at the provider gave %+v, %v", result, err)
+	}
+}
+
+func TestDeclinedCardRecordsNothing(t *testing.T) {
+	a := newTestApp(t)
+	invoice := billed(a)
+
+	for _, token := range []string{"tok_decline", "tok_insufficient_funds"} {
+		var declined struct {
+			Decline_reason string `json:"decline_reason"`
+		}
+		a.expect(a.do(http.MethodPost, "/invoices/"+invoice.Invoice_id+"/payments", gin.H{
+			"tender":     models.TenderCard,
+			"amount":     usd(1000),
+			"card_token": token,
+		}), http.StatusPaymentRequired, &declined)
+
+		if declined.Decline_reason == "" {
+			t.Errorf("%s: no decline reason given", token)
+		}
+	}
+
+	a.expect(a.do(http.MethodPost, "/invoices/"+invoice.Invoice_id+"/payments", gin.H{
+		"tender": models.TenderCard,
+		"amount": usd(1000),
+	}), http.StatusBadRequest, nil)
+
+	payments, _ := a.s.Payments.ListByInvoice(context.Background(), a.restaurant.Restaurant_id, invoice.Invoice_id)
+	if len(payments) != 0 {
+		t.Errorf("%d payments were recorded for declined cards", len(payments))
+	}
+
+	if invoiceView := a.view(invoice.Invoice_id); *invoiceView.Payment_status != models.InvoicePending {
+		t.Errorf("invoice is %s, want PENDING", *invoiceView.Payment_status)
+	}
+}
+
+func TestCashPaymentCarriesNoTransaction(t *testing.T) {
+	a := newTestApp(t)
+	invoice := billed(a)
+
+	var paid paymentResponse
+	a.expect(a.do(http.MethodPost, "/invoices/"+invoice.Invoice_id+"/payments", gin.H{
+		"tender":          models.TenderCash,
+		"amount":          usd(1000),
+		"provider":        "mock",
+		"transaction_id":  "mock_forged",
+		"provider_status": gateway.Captured,
+	}), http.StatusOK, &paid)
+
+	payment, err := a.s.Payments.Get(context.Background(), a.restaurant.Restaurant_id, paid.Payment.Payment_id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if payment.Provider != "" || payment.Transaction_id != "" || payment.Provider_status != "" {
+		t.Errorf("cash payment was stored with %q, %q, %q from the client", payment.Provider, payment.Transaction_id, payment.Provider_status)
+	}
+}
+
+func TestProviderTimeoutIsReported(t *testing.T) {
+	a := newTestApp(t)
+	invoice := billed(a)
+	a.router.POST("/slow/:invoice_id/payments", CreatePayment(a.s, gateway.WithTimeout(a.payments, 20*time.Millisecond)))
+
+	a.expect(a.do(http.MethodPost, "/slow/"+invoice.Invoice_id+"/payments", gin.H{
+		"tender":     models.TenderCard,
+		"amount":     usd(1000),
+		"card_token": "tok_timeout",
+	}), http.StatusGatewayTimeout, nil)
+}
+
+func TestRefundGoesBackThroughTheProvider(t *testing.T) {
+	a := newTestApp(t)
+	invoice := billed(a)
+	manager := a.user(models.RoleManager, a.restaurant.Restaurant_id)
+
+	var paid paymentResponse
+	a.expect(a.do(http.MethodPost, "/invoices/"+invoice.Invoice_id+"/payments", gin.H{
+		"tender":     models.TenderCard,
+		"amount":     usd(1000),
+		"card_token": "tok_visa",
+	}), http.StatusOK, &paid)
+
+	refundPath := "/payments/" + paid.Payment.Payment_id + "/refunds"
+
+	a.expect(a.do(http.MethodPost, refundPath, gin.H{
+		"amount":           usd(400),
+		"reason":           "QUALITY",
+		"manager_id":       manager.User_id,
+		"manager_password": "not-the-password",
+	}), http.StatusForbidden, nil)
+
+	var partial struct {
+		Credit_note models.CreditNote `json:"credit_note"`
+		Invoice     InvoiceViewFormat `json:"invoice"`
+	}
+	a.expect(a.do(http.MethodPost, refundPath, gin.H{
+		"amount":           usd(400),
+		"reason":           "QUALITY",
+		"manager_id":       manager.User_id,
+		"manager_password": testPassword,
+	}), http.StatusOK, &partial)
+
+	if partial.Credit_note.Provider_status != gateway.PartiallyRefunded || partial.Credit_note.Transaction_id != paid.Payment.Transaction_id {
+		t.Errorf("credit note = %+v, want a partial refund of the transaction", partial.Credit_note)
+	}
+
+	var rest struct {
+		Credit_note models.CreditNote `json:"credit_note"`
+		Invoice     InvoiceViewFormat `json:"invoice"`
+	}
+	a.expect(a.do(http.MethodPost, refundPath, gin.H{
+		"reason":           "QUALITY",
+		"manager_id":       manager.User_id,
+		"manager_password": testPassword,
+	}), http.StatusOK, &rest)
+
+	if rest.Credit_note.Amount != usd(600) || rest.Credit_note.Provider_status != gateway.Refunded {
+		t.Errorf("second refund = %v as %s, want the 600 left, REFUNDED", rest.Credit_note.Amount, rest.Credit_note.Provider_status)
+	}
+
+	if *rest.Invoice.Payment_status != models.InvoiceRefunded {
+		t.Errorf("invoice is %s, want REFUNDED", *rest.Invoice.Payment_status)
+	}
+
+	a.expect(a.do(http.MethodPost, refundPath, gin.H{
+		"reason":           "QUALITY",
+		"manager_id":       manager.User_id,
+		"manager_password": testPassword,
+	}), http.StatusConflict, nil)
+}
+
+func TestPaymentWebhook(t *testing.T) {
+	a := newTestApp(t)
+	invoice := billed(a)
+
+	var paid paymentResponse
+	a.expect(a.do(http.MethodPost, "/invoices/"+invoice.Invoice_id+"/payments", gin.H{
+		"tender":     models.TenderWallet,
+		"amount":     usd(1000),
+		"card_token": "tok_wallet",
+	}), http.StatusOK, &paid)
+
+	send := func(event gateway.WebhookEvent, signature func([]byte) string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(event)
+		req := httptest.NewRequest(http.MethodPost, "/webhooks/payments/mock", bytes.NewReader(body))
+		req.Header.Set(mock.SignatureHeader, signature(body))
+
+		w := httptest.NewRecorder()
+		a.router.ServeHTTP(w, req)
+		return w
+	}
+
+	refunded := gateway.WebhookEvent{Transaction_id: paid.Payment.Transaction_id, Status: gateway.Refunded}
+
+	forged := mock.New("someone-else")
+	a.expect(send(refunded, forged.Sign), http.StatusUnauthorized, nil)
+	a.expect(send(gateway.WebhookEvent{Transaction_id: "mock_unknown", Status: gateway.Refunded}, a.payments.Sign), http.StatusNotFound, nil)
+	a.expect(send(refunded, a.payments.Sign), http.StatusOK, nil)
+
+	payment, err := a.s.Payments.Get(context.Background(), a.restaurant.Restaurant_id, paid.Payment.Payment_id)
+	if err != nil || payment.Provider_status != gateway.Refunded {
+		t.Errorf("payment status = %q, %v; want REFUNDED from the webhook", payment.Provider_status, err)
+	}
+}
@@ -0,0 +1,315 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vikas-gouda/go-restraunt-mangement/models"
+	"github.com/vikas-gouda/go-restraunt-mangement/printing"
+	"github.com/vikas-gouda/go-restraunt-mangement/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// printerPatch is the body of UpdatePrinter. Pointers tell clearing the
+// rules or enabling a printer again apart from leaving them alone.
+type printerPatch struct {
+	Name       string    `json:"name" validate:"omitempty,max=50"`
+	Address    string    `json:"address" validate:"omitempty,max=255"`
+	Stations   *[]string `json:"stations" validate:"omitempty,dive,eq=kitchen|eq=grill|eq=bar|eq=pastry"`
+	Categories *[]string `json:"categories" validate:"omitempty,dive,required,max=50"`
+	Width      *int      `json:"width" validate:"omitempty,eq=0|eq=40|eq=48"`
+	Disabled   *bool     `json:"disabled"`
+}
+
+type printRequest struct {
+	Printer_id string `json:"printer_id"`
+}
+
+func GetPrinters(s *store.Store) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		allPrinters, err := s.Printers.List(c, ctx.GetString("restaurant_id"))
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, allPrinters)
+	}
+}
+
+func GetPrinter(s *store.Store) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		printer, err := s.Printers.Get(c, ctx.GetString("restaurant_id"), ctx.Param("printer_id"))
+		if errors.Is(err, store.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "printer not found"})
+			return
+		}
+
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error occured while fetching the printer"})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, printer)
+	}
+}
+
+// CreatePrinter sets up a printer, which has to be on one of the networks.
+func CreatePrinter(s *store.Store, networks printing.Networks) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var printer models.Printer
+
+		if err := ctx.BindJSON(&printer); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		printer.Kind = strings.ToUpper(printer.Kind)
+
+		validationErr := validate.Struct(printer)
+		if validationErr != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		printer.Address = printing.Address(printer.Address)
+		if err := networks.Allows(printer.Address); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "address must be a printer on the local network"})
+			return
+		}
+
+		printer.Created_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		printer.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		printer.ID = primitive.NewObjectID()
+		printer.Printer_id = printer.ID.Hex()
+		printer.Restaurant_id = ctx.GetString("restaurant_id")
+
+		if err := s.Printers.Create(c, printer); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Printer was not created"})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, printer)
+	}
+}
+
+func UpdatePrinter(s *store.Store, networks printing.Networks) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var patch printerPatch
+
+		if err := ctx.BindJSON(&patch); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		validationErr := validate.Struct(patch)
+		if validationErr != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		foundPrinter, err := s.Printers.Get(c, ctx.GetString("restaurant_id"), ctx.Param("printer_id"))
+		if err != nil {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "printer not found"})
+			return
+		}
+
+		if patch.Name != "" {
+			foundPrinter.Name = patch.Name
+		}
+
+		if patch.Address != "" {
+			foundPrinter.Address = printing.Address(patch.Address)
+			if err := networks.Allows(foundPrinter.Address); err != nil {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": "address must be a printer on the local network"})
+				return
+			}
+		}
+
+		if patch.Stations != nil {
+			foundPrinter.Stations = *patch.Stations
+		}
+
+		if patch.Categories != nil {
+			foundPrinter.Categories = *patch.Categories
+		}
+
+		if patch.Width != nil {
+			foundPrinter.Width = *patch.Width
+		}
+
+		if patch.Disabled != nil {
+			foundPrinter.Disabled = *patch.Disabled
+		}
+
+		foundPrinter.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+
+		if err := s.Printers.Update(c, foundPrinter); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update the printer"})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, foundPrinter)
+	}
+}
+
+// TestPrinter prints a test page on the printer.
+func TestPrinter(s *store.Store, queue *printing.Queue) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		printer, err := s.Printers.Get(c, ctx.GetString("restaurant_id"), ctx.Param("printer_id"))
+		if err != nil {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "printer not found"})
+			return
+		}
+
+		job := queue.Submit(printJob(printer, printing.KindTest, ""), printing.TestPage(printer))
+		ctx.JSON(http.StatusAccepted, job)
+	}
+}
+
+// PrintInvoice prints the receipt of an invoice on the receipt printer given
+// as printer_id, or on the first one of the restaurant.
+func PrintInvoice(s *store.Store, queue *printing.Queue) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var request printRequest
+
+		if ctx.Request.ContentLength != 0 {
+			if err := ctx.BindJSON(&request); err != nil {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
+
+		restaurantId := ctx.GetString("restaurant_id")
+
+		printers, err := s.Printers.List(c, restaurantId)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while listing the printers"})
+			return
+		}
+
+		var printer *models.Printer
+		for i := range printers {
+			candidate := printers[i]
+			if candidate.Kind != models.PrinterReceipt || candidate.Disabled {
+				continue
+			}
+
+			if request.Printer_id == "" || request.Printer_id == candidate.Printer_id {
+				printer = &candidate
+				break
+			}
+		}
+
+		if printer == nil {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "no receipt printer to print on"})
+			return
+		}
+
+		doc, status, body := invoiceDocument(c, s, restaurantId, ctx.Param("invoice_id"))
+		if status != http.StatusOK {
+			ctx.JSON(status, body)
+			return
+		}
+
+		job := queue.Submit(printJob(*printer, printing.KindReceipt, doc.Number), printing.Receipt(*printer, doc))
+		ctx.JSON(http.StatusAccepted, job)
+	}
+}
+
+func GetPrintJobs(queue *printing.Queue) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.JSON(http.StatusOK, queue.List(ctx.GetString("restaurant_id")))
+	}
+}
+
+func GetPrintJob(queue *printing.Queue) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		job, ok := queue.Get(ctx.GetString("restaurant_id"), ctx.Param("job_id"))
+		if !ok {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "print job not found"})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, job)
+	}
+}
+
+// RetryPrintJob queues a failed job again, e.g. once the printer has paper.
+func RetryPrintJob(queue *printing.Queue) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		job, err := queue.Retry(ctx.GetString("restaurant_id"), ctx.Param("job_id"))
+		if errors.Is(err, printing.ErrJobNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "print job not found"})
+			return
+		}
+
+		if errors.Is(err, printing.ErrNotFailed) {
+			ctx.JSON(http.StatusConflict, gin.H{"error": "print job is " + job.Status + ", only failed jobs can be retried"})
+			return
+		}
+
+		ctx.JSON(http.StatusAccepted, job)
+	}
+}
+
+// printKitchenTickets sends each kitchen printer the tickets its rules pick.
+// Printing never fails the order; the job list shows what did not print.
+func printKitchenTickets(c context.Context, s *store.Store, queue *printing.Queue, restaurantId string, tickets []models.KitchenTicket) {
+	if queue == nil || len(tickets) == 0 {
+		return
+	}
+
+	printers, err := s.Printers.List(c, restaurantId)
+	if err != nil {
+		log.Printf("listing the printers of restaurant %s: %v", restaurantId, err)
+		return
+	}
+
+	for _, printer := range printers {
+		picked := []models.KitchenTicket{}
+		for _, ticket := range tickets {
+			if printer.Prints(ticket) {
+				picked = append(picked, ticket)
+			}
+		}
+
+		if len(picked) > 0 {
+			queue.Submit(printJob(printer, printing.KindKitchen, picked[0].Order_id), printing.Kitchen(printer, picked))
+		}
+	}
+}
+
+func printJob(printer models.Printer, kind string, reference string) printing.Job {
+	return printing.Job{
+		Kind:          kind,
+		Reference:     reference,
+		Printer_id:    printer.Printer_id,
+		Printer_name:  printer.Name,
+		Address:       printer.Address,
+		Restaurant_id: printer.Restaurant_id,
+	}
+}
@@ -0,0 +1,35 @@
+package controller
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vikas-gouda/go-restraunt-mangement/models"
+	"github.com/vikas-gouda/go-restraunt-mangement/printing"
+)
+
+func TestPrinterAddressMustBeOnTheNetworks(t *testing.T) {
+	a := newTestApp(t)
+	a.router.POST("/printers", CreatePrinter(a.s, printing.LAN))
+	a.router.PATCH("/printers/:printer_id", UpdatePrinter(a.s, printing.LAN))
+
+	for _, test := range []struct {
+		address string
+		status  int
+	}{
+		{"192.168.1.50", http.StatusOK},
+		{"grill.local", http.StatusOK},
+		{"169.254.169.254:80", http.StatusBadRequest},
+		{"127.0.0.1:27017", http.StatusBadRequest},
+	} {
+		w := a.do(http.MethodPost, "/printers", gin.H{"name": "Grill", "address": test.address, "kind": models.PrinterKitchen})
+		if w.Code != test.status {
+			t.Errorf("creating a printer at %s got %d, want %d: %s", test.address, w.Code, test.status, w.Body.String())
+		}
+	}
+
+	var printer models.Printer
+	a.expect(a.do(http.MethodPost, "/printers", gin.H{"name": "Bar", "address": "10.0.0.9", "kind": models.PrinterKitchen}), http.StatusOK, &printer)
+	a.expect(a.do(http.MethodPatch, "/printers/"+printer.Printer_id, gin.H{"address": "8.8.8.8"}), http.StatusBadRequest, nil)
+}
@@ -0,0 +1,435 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vikas-gouda/go-restraunt-mangement/models"
+	"github.com/vikas-gouda/go-restraunt-mangement/money"
+	"github.com/vikas-gouda/go-restraunt-mangement/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// promotionPatch is the body of UpdatePromotion. The rule itself can't be
+// changed; a different discount is a new promotion.
+type promotionPatch struct {
+	Name              string     `json:"name" validate:"omitempty,max=50"`
+	Starts_at         *time.Time `json:"starts_at"`
+	Ends_at           *time.Time `json:"ends_at"`
+	Max_uses          *int       `json:"max_uses" validate:"omitempty,gte=0"`
+	Requires_approval *bool      `json:"requires_approval"`
+	Active            *bool      `json:"active"`
+}
+
+func GetPromotions(s *store.Store) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		allPromotions, err := s.Promotions.List(c, ctx.GetString("restaurant_id"))
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, allPromotions)
+	}
+}
+
+func GetPromotion(s *store.Store) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		promotion, err := s.Promotions.Get(c, ctx.GetString("restaurant_id"), ctx.Param("promotion_id"))
+		if errors.Is(err, store.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "promotion not found"})
+			return
+		}
+
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error occured while fetching the promotion"})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, promotion)
+	}
+}
+
+func CreatePromotion(s *store.Store) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var promotion models.Promotion
+
+		if err := ctx.BindJSON(&promotion); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		validationErr := validate.Struct(promotion)
+		if validationErr != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		restaurantId := ctx.GetString("restaurant_id")
+
+		if err := checkDiscountRule(c, s, restaurantId, &promotion.DiscountRule); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if promotion.Starts_at != nil && promotion.Ends_at != nil && !promotion.Ends_at.After(*promotion.Starts_at) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "ends_at must be after starts_at"})
+			return
+		}
+
+		promotion.Code = strings.ToUpper(promotion.Code)
+		if promotion.Code != "" {
+			_, err := s.Promotions.GetByCode(c, restaurantId, promotion.Code)
+			if err == nil {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": "The promotion code already exists"})
+				return
+			}
+
+			if !errors.Is(err, store.ErrNotFound) {
+				ctx.JSON(http.StatusInternalServerError, gin.H{"error": "while checking for the promotion code"})
+				return
+			}
+		}
+
+		promotion.Uses = 0
+		promotion.Active = true
+		promotion.Created_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		promotion.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		promotion.ID = primitive.NewObjectID()
+		promotion.Promotion_id = promotion.ID.Hex()
+		promotion.Restaurant_id = restaurantId
+
+		if err := s.Promotions.Create(c, promotion); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Promotion was not created"})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, promotion)
+	}
+}
+
+func UpdatePromotion(s *store.Store) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var patch promotionPatch
+
+		if err := ctx.BindJSON(&patch); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		validationErr := validate.Struct(patch)
+		if validationErr != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		foundPromotion, err := s.Promotions.Get(c, ctx.GetString("restaurant_id"), ctx.Param("promotion_id"))
+		if err != nil {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "promotion not found"})
+			return
+		}
+
+		if patch.Name != "" {
+			foundPromotion.Name = patch.Name
+		}
+
+		if patch.Starts_at != nil {
+			foundPromotion.Starts_at = patch.Starts_at
+		}
+
+		if patch.Ends_at != nil {
+			foundPromotion.Ends_at = patch.Ends_at
+		}
+
+		if patch.Max_uses != nil {
+			foundPromotion.Max_uses = *patch.Max_uses
+		}
+
+		if patch.Requires_approval != nil {
+			foundPromotion.Requires_approval = *patch.Requires_approval
+		}
+
+		if patch.Active != nil {
+			foundPromotion.Active = *patch.Active
+		}
+
+		if foundPromotion.Starts_at != nil && foundPromotion.Ends_at != nil && !foundPromotion.Ends_at.After(*foundPromotion.Starts_at) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "ends_at must be after starts_at"})
+			return
+		}
+
+		foundPromotion.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+
+		if err := s.Promotions.Update(c, foundPromotion); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update the promotion"})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, foundPromotion)
+	}
+}
+
+// ApplyDiscount puts a promotion, a coupon code or, for managers, a one-off
+// discount on an order. Discounts that need approval take a reason code.
+func ApplyDiscount(s *store.Store) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var request models.DiscountRequest
+
+		if err := ctx.BindJSON(&request); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		validationErr := validate.Struct(request)
+		if validationErr != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		orderId := ctx.Param("order_id")
+		restaurantId := ctx.GetString("restaurant_id")
+		role := ctx.GetString("role")
+		manager := role == models.RoleManager || role == models.RoleOwner
+
+		order, err := s.Orders.Get(c, restaurantId, orderId)
+		if err != nil {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "order not found"})
+			return
+		}
+
+		if order.Closed() {
+			ctx.JSON(http.StatusConflict, gin.H{"error": "order is " + order.CurrentStatus() + " and can no longer be changed"})
+			return
+		}
+
+		if status, body := checkNotInvoiced(c, s, order); status != http.StatusOK {
+			ctx.JSON(status, body)
+			return
+		}
+
+		discount := models.OrderDiscount{
+			Discount_id: primitive.NewObjectID().Hex(),
+			Reason:      request.Reason,
+			Applied_by:  ctx.GetString("uid"),
+			Role:        role,
+		}
+		discount.Applied_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+
+		needsApproval := false
+
+		switch {
+		case request.Code != "" || request.Promotion_id != "":
+			var promotion models.Promotion
+			if request.Code != "" {
+				promotion, err = s.Promotions.GetByCode(c, restaurantId, strings.ToUpper(request.Code))
+			} else {
+				promotion, err = s.Promotions.Get(c, restaurantId, request.Promotion_id)
+			}
+
+			if err != nil {
+				ctx.JSON(http.StatusNotFound, gin.H{"error": "promotion not found"})
+				return
+			}
+
+			discount.Promotion_id = promotion.Promotion_id
+			discount.Code = promotion.Code
+			discount.Name = promotion.Name
+			discount.DiscountRule = promotion.DiscountRule
+			needsApproval = promotion.Requires_approval
+
+		case request.Rule != nil:
+			if err := checkDiscountRule(c, s, restaurantId, request.Rule); err != nil {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			discount.Name = request.Name
+			if discount.Name == "" {
+				discount.Name = "Manager discount"
+			}
+			discount.DiscountRule = *request.Rule
+			needsApproval = true
+
+		default:
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "a code, a promotion_id or a rule is required"})
+			return
+		}
+
+		if needsApproval {
+			if !manager {
+				ctx.JSON(http.StatusForbidden, gin.H{"error": "this discount has to be applied by a manager"})
+				return
+			}
+
+			if request.Reason == "" {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": "a reason is required for this discount"})
+				return
+			}
+
+			discount.Approved_by = ctx.GetString("uid")
+		}
+
+		if discount.Promotion_id != "" {
+			for _, existing := range order.Discounts {
+				if existing.Promotion_id == discount.Promotion_id {
+					ctx.JSON(http.StatusConflict, gin.H{"error": "the promotion is already on this order"})
+					return
+				}
+			}
+
+			redeemed, err := s.Promotions.Redeem(c, restaurantId, discount.Promotion_id, discount.Applied_at)
+			if err != nil {
+				ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to redeem the promotion"})
+				return
+			}
+
+			if !redeemed {
+				ctx.JSON(http.StatusConflict, gin.H{"error": "the promotion is not active or has been used up"})
+				return
+			}
+		}
+
+		added, err := s.Orders.AddDiscount(c, restaurantId, orderId, order.Billing_version, discount)
+		if err != nil || !added {
+			if discount.Promotion_id != "" {
+				s.Promotions.Unredeem(c, restaurantId, discount.Promotion_id)
+			}
+
+			if err != nil {
+				ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to apply the discount"})
+				return
+			}
+
+			ctx.JSON(http.StatusConflict, gin.H{"error": "the order changed while the discount was applied, try again"})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, discount)
+	}
+}
+
+// RemoveDiscount takes a discount off an order and gives its promotion the
+// use back. Only managers can take off discounts they had to approve.
+func RemoveDiscount(s *store.Store) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		orderId := ctx.Param("order_id")
+		discountId := ctx.Param("discount_id")
+		restaurantId := ctx.GetString("restaurant_id")
+		role := ctx.GetString("role")
+
+		order, err := s.Orders.Get(c, restaurantId, orderId)
+		if err != nil {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "order not found"})
+			return
+		}
+
+		var discount *models.OrderDiscount
+		for i := range order.Discounts {
+			if order.Discounts[i].Discount_id == discountId {
+				discount = &order.Discounts[i]
+			}
+		}
+
+		if discount == nil {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "discount not found"})
+			return
+		}
+
+		if discount.Approved_by != "" && role != models.RoleManager && role != models.RoleOwner {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": "this discount can only be removed by a manager"})
+			return
+		}
+
+		if status, body := checkNotInvoiced(c, s, order); status != http.StatusOK {
+			ctx.JSON(status, body)
+			return
+		}
+
+		removed, err := s.Orders.RemoveDiscount(c, restaurantId, orderId, order.Billing_version, discountId)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove the discount"})
+			return
+		}
+
+		if !removed {
+			ctx.JSON(http.StatusConflict, gin.H{"error": "the order changed or the discount is already gone, try again"})
+			return
+		}
+
+		if discount.Promotion_id != "" {
+			if err := s.Promotions.Unredeem(c, restaurantId, discount.Promotion_id); err != nil {
+				ctx.JSON(http.StatusInternalServerError, gin.H{"error": "discount was removed but the promotion use was not given back"})
+				return
+			}
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"discount_id": discountId})
+	}
+}
+
+// checkNotInvoiced refuses changes to the bill of an order that has an invoice
+// which is not voided, as it would change what the invoice asks for. Anything
+// but 200 is the response to give instead.
+func checkNotInvoiced(c context.Context, s *store.Store, order models.Order) (int, gin.H) {
+	openInvoices, err := openInvoicesOf(c, s, order)
+	if err != nil {
+		return http.StatusInternalServerError, gin.H{"error": "Error while listing the invoices of the order"}
+	}
+
+	if len(openInvoices) > 0 {
+		return http.StatusConflict, gin.H{"error": "the order is invoiced already, void its invoices to change it"}
+	}
+
+	return http.StatusOK, nil
+}
+
+// checkDiscountRule checks a rule on top of its field validation: amounts in
+// the restaurant's currency and item rules naming foods that exist.
+func checkDiscountRule(c context.Context, s *store.Store, restaurantId string, rule *models.DiscountRule) error {
+	if err := rule.Check(); err != nil {
+		return err
+	}
+
+	if rule.Kind == models.DiscountAmount {
+		currency, _, err := restaurantMoney(c, s, restaurantId)
+		if err != nil {
+			return err
+		}
+
+		if err := checkPrice(&rule.Amount, currency); err != nil {
+			return err
+		}
+	} else {
+		rule.Amount = money.Money{}
+	}
+
+	for _, foodId := range rule.Food_ids {
+		if _, err := s.Foods.Get(c, restaurantId, foodId); err != nil {
+			return errors.New("food " + foodId + " was not found")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,120 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vikas-gouda/go-restraunt-mangement/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// promotion stores an active promotion of the test restaurant taking
+// basisPoints off the order.
+func (a *testApp) promotion(code string, basisPoints int64, change func(*models.Promotion)) models.Promotion {
+	a.t.Helper()
+
+	promotion := models.Promotion{
+		ID:            primitive.NewObjectID(),
+		Name:          code,
+		Code:          code,
+		DiscountRule:  models.DiscountRule{Kind: models.DiscountPercent, Scope: models.ScopeOrder, Basis_points: basisPoints},
+		Active:        true,
+		Restaurant_id: a.restaurant.Restaurant_id,
+	}
+	promotion.Promotion_id = promotion.ID.Hex()
+	if change != nil {
+		change(&promotion)
+	}
+
+	if err := a.s.Promotions.Create(context.Background(), promotion); err != nil {
+		a.t.Fatal(err)
+	}
+
+	return promotion
+}
+
+func TestDiscountApprovals(t *testing.T) {
+	a := newTestApp(t)
+	burger := a.food("Burger", 1000, "")
+	waiter := a.user(models.RoleWaiter, a.restaurant.Restaurant_id)
+	manager := a.user(models.RoleManager, a.restaurant.Restaurant_id)
+
+	yesterday := time.Now().Add(-24 * time.Hour)
+	a.promotion("HAPPY", 1000, nil)
+	a.promotion("STAFF", 5000, func(p *models.Promotion) { p.Requires_approval = true })
+	a.promotion("GONE", 1000, func(p *models.Promotion) { p.Max_uses, p.Uses = 1, 1 })
+	a.promotion("OVER", 1000, func(p *models.Promotion) { p.Ends_at = &yesterday })
+	a.promotion("OFF", 1000, func(p *models.Promotion) { p.Active = false })
+	manual := gin.H{"kind": models.DiscountAmount, "scope": models.ScopeOrder, "amount": usd(200)}
+
+	for _, test := range []struct {
+		name     string
+		as       models.User
+		request  gin.H
+		status   int
+		approved bool
+	}{
+		{"waiter with a coupon", waiter, gin.H{"code": "happy"}, http.StatusOK, false},
+		{"waiter with a promotion needing approval", waiter, gin.H{"code": "STAFF", "reason": models.ReasonStaffMeal}, http.StatusForbidden, false},
+		{"manager without a reason", manager, gin.H{"code": "STAFF"}, http.StatusBadRequest, false},
+		{"manager with a reason", manager, gin.H{"code": "STAFF", "reason": models.ReasonStaffMeal}, http.StatusOK, true},
+		{"waiter with a one-off rule", waiter, gin.H{"rule": manual, "reason": models.ReasonComplimentary}, http.StatusForbidden, false},
+		{"manager with a one-off rule", manager, gin.H{"rule": manual, "reason": models.ReasonComplimentary}, http.StatusOK, true},
+		{"unknown reason", manager, gin.H{"rule": manual, "reason": "FRIEND"}, http.StatusBadRequest, false},
+		{"used up", waiter, gin.H{"code": "GONE"}, http.StatusConflict, false},
+		{"ended", waiter, gin.H{"code": "OVER"}, http.StatusConflict, false},
+		{"not active", waiter, gin.H{"code": "OFF"}, http.StatusConflict, false},
+		{"unknown code", waiter, gin.H{"code": "NOPE"}, http.StatusNotFound, false},
+	} {
+		order, _ := a.order(a.table(2), orderedItem{food: burger, quantity: 1})
+
+		a.as = test.as
+		w := a.do(http.MethodPost, "/orders/"+order.Order_id+"/discounts", test.request)
+		a.as = a.owner
+
+		if w.Code != test.status {
+			t.Errorf("%s: got %d, want %d: %s", test.name, w.Code, test.status, w.Body.String())
+			continue
+		}
+
+		if test.status != http.StatusOK {
+			continue
+		}
+
+		var discount models.OrderDiscount
+		a.expect(w, http.StatusOK, &discount)
+		approver := ""
+		if test.approved {
+			approver = test.as.User_id
+		}
+
+		if discount.Approved_by != approver {
+			t.Errorf("%s: approved by %q, want %q", test.name, discount.Approved_by, approver)
+		}
+	}
+}
+
+func TestApprovedDiscountIsRemovedByAManager(t *testing.T) {
+	a := newTestApp(t)
+	a.promotion("STAFF", 5000, func(p *models.Promotion) { p.Requires_approval = true; p.Max_uses = 1 })
+	order, _ := a.order(a.table(2), orderedItem{food: a.food("Burger", 1000, ""), quantity: 1})
+
+	var discount models.OrderDiscount
+	a.expect(a.do(http.MethodPost, "/orders/"+order.Order_id+"/discounts", gin.H{"code": "STAFF", "reason": models.ReasonStaffMeal}), http.StatusOK, &discount)
+
+	// The one use is taken; a second order can't have it.
+	second, _ := a.order(a.table(2), orderedItem{food: a.food("Fries", 300, ""), quantity: 1})
+	a.expect(a.do(http.MethodPost, "/orders/"+second.Order_id+"/discounts", gin.H{"code": "STAFF", "reason": models.ReasonStaffMeal}), http.StatusConflict, nil)
+
+	a.as = a.user(models.RoleWaiter, a.restaurant.Restaurant_id)
+	a.expect(a.do(http.MethodDelete, "/orders/"+order.Order_id+"/discounts/"+discount.Discount_id, nil), http.StatusForbidden, nil)
+
+	a.as = a.user(models.RoleManager, a.restaurant.Restaurant_id)
+	a.expect(a.do(http.MethodDelete, "/orders/"+order.Order_id+"/discounts/"+discount.Discount_id, nil), http.StatusOK, nil)
+
+	// Taking it off gave the use back.
+	a.expect(a.do(http.MethodPost, "/orders/"+second.Order_id+"/discounts", gin.H{"code": "STAFF", "reason": models.ReasonStaffMeal}), http.StatusOK, nil)
+}
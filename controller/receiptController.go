@@ -0,0 +1,189 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vikas-gouda/go-restraunt-mangement/models"
+	"github.com/vikas-gouda/go-restraunt-mangement/money"
+	"github.com/vikas-gouda/go-restraunt-mangement/receipt"
+	"github.com/vikas-gouda/go-restraunt-mangement/store"
+)
+
+// GetInvoicePDF renders the invoice as a PDF to hand or mail to the guest.
+func GetInvoicePDF(s *store.Store) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		doc, status, body := invoiceDocument(c, s, ctx.GetString("restaurant_id"), ctx.Param("invoice_id"))
+		if status != http.StatusOK {
+			ctx.JSON(status, body)
+			return
+		}
+
+		var pdf bytes.Buffer
+		if err := receipt.PDF(&pdf, doc); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while rendering the invoice"})
+			return
+		}
+
+		filename := strings.NewReplacer(`"`, "", "/", "-", `\`, "-").Replace(doc.Number)
+		ctx.Header("Content-Disposition", `inline; filename="invoice-`+filename+`.pdf"`)
+		ctx.Data(http.StatusOK, "application/pdf", pdf.Bytes())
+	}
+}
+
+// GetInvoiceReceipt renders the invoice as plain text for a receipt printer,
+// ?width=40 or 48 columns.
+func GetInvoiceReceipt(s *store.Store) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		width, err := receiptWidth(ctx.Query("width"))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		doc, status, body := invoiceDocument(c, s, ctx.GetString("restaurant_id"), ctx.Param("invoice_id"))
+		if status != http.StatusOK {
+			ctx.JSON(status, body)
+			return
+		}
+
+		ctx.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(receipt.Text(doc, width)))
+	}
+}
+
+func receiptWidth(value string) (int, error) {
+	switch value {
+	case "", strconv.Itoa(receipt.Narrow):
+		return receipt.Narrow, nil
+	case strconv.Itoa(receipt.Wide):
+		return receipt.Wide, nil
+	}
+
+	return 0, errors.New("width must be 40 or 48")
+}
+
+// invoiceDocument lays out an invoice as billed now for the receipt
+// renderers. Anything but 200 is the response to give instead.
+func invoiceDocument(c context.Context, s *store.Store, restaurantId string, invoiceId string) (receipt.Document, int, gin.H) {
+	var doc receipt.Document
+
+	invoice, err := s.Invoices.Get(c, restaurantId, invoiceId)
+	if errors.Is(err, store.ErrNotFound) {
+		return doc, http.StatusNotFound, gin.H{"error": "invoice not found"}
+	}
+
+	if err != nil {
+		return doc, http.StatusInternalServerError, gin.H{"error": "Error occurred while listing invoice item"}
+	}
+
+	invoiceView, err := viewInvoice(c, s, invoice)
+	if err != nil {
+		return doc, http.StatusInternalServerError, gin.H{"error": "Error occurred while billing the order"}
+	}
+
+	restaurant, err := s.Restaurants.Get(c, restaurantId)
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		return doc, http.StatusInternalServerError, gin.H{"error": "Error while fetching the restaurant"}
+	}
+
+	return receiptDocument(restaurant, invoice, invoiceView), http.StatusOK, nil
+}
+
+func receiptDocument(restaurant models.Restaurant, invoice models.Invoice, invoiceView InvoiceViewFormat) receipt.Document {
+	doc := receipt.Document{
+		Restaurant:  restaurant.Name,
+		Address:     restaurant.Address,
+		Phone:       restaurant.Phone,
+		Title:       "Invoice",
+		Number:      invoice.Invoice_number,
+		Seat:        invoice.Seat,
+		Date:        invoice.Created_at,
+		Items_total: invoiceView.Items_total,
+		Total:       invoiceView.Total,
+		Paid:        invoiceView.Paid,
+		Credited:    invoiceView.Credited,
+		Balance:     invoiceView.Balance,
+		QR:          restaurant.ReceiptQR(invoice.Invoice_id),
+		Footer:      restaurant.Receipt_footer,
+	}
+
+	if doc.Number == "" {
+		doc.Number = invoice.Invoice_id
+	}
+
+	if table, ok := invoiceView.Table_number.(int); ok {
+		doc.Table_number = table
+	}
+
+	if invoiceView.Payment_status != nil {
+		doc.Status = *invoiceView.Payment_status
+	}
+	if invoice.Voided() {
+		doc.Status = "VOID"
+	}
+
+	if invoice.Mode() == models.SplitEven {
+		if due, ok := invoiceView.Payment_due.(money.Money); ok {
+			doc.Due = due
+		}
+	}
+
+	lines, _ := invoiceView.Order_details.([]models.OrderLine)
+	for _, line := range lines {
+		if line.Status == models.ItemVoided {
+			continue
+		}
+
+		name := line.Food_name
+		if line.Size != "" {
+			name += " (" + line.Size + ")"
+		}
+
+		modifiers := []string{}
+		for _, modifier := range line.Modifiers {
+			modifiers = append(modifiers, modifier.Name)
+		}
+
+		doc.Lines = append(doc.Lines, receipt.Line{Quantity: line.Quantity, Name: name, Modifiers: modifiers, Amount: line.Amount})
+	}
+
+	for _, discount := range invoiceView.Discounts {
+		doc.Adjustments = append(doc.Adjustments, receipt.Amount{Label: discount.Name, Amount: discount.Amount.Neg()})
+	}
+
+	for _, tax := range invoiceView.Taxes {
+		label := tax.Name + " " + percent(tax.Basis_points)
+		if tax.Inclusive {
+			label += " incl."
+		}
+		doc.Adjustments = append(doc.Adjustments, receipt.Amount{Label: label, Amount: tax.Amount})
+	}
+
+	if !invoiceView.Gratuity.IsZero() {
+		doc.Adjustments = append(doc.Adjustments, receipt.Amount{Label: "Service charge " + percent(invoiceView.Gratuity_basis_points), Amount: invoiceView.Gratuity})
+	}
+
+	for _, payment := range invoiceView.Payments {
+		doc.Payments = append(doc.Payments, receipt.Payment{Tender: payment.Tender, Amount: payment.Amount, Tip: payment.Tip, Change: payment.Change})
+	}
+
+	return doc
+}
+
+// percent writes basis points as a percentage, e.g. 1250 as 12.5%.
+func percent(basisPoints int64) string {
+	value := strconv.FormatFloat(float64(basisPoints)/100, 'f', 2, 64)
+	return strings.TrimSuffix(strings.TrimRight(value, "0"), ".") + "%"
+}
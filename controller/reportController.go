@@ -0,0 +1,309 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vikas-gouda/go-restraunt-mangement/models"
+	"github.com/vikas-gouda/go-restraunt-mangement/money"
+	"github.com/vikas-gouda/go-restraunt-mangement/store"
+)
+
+// GetSalesReport adds up payments, refunds and voids between ?from and ?to,
+// given as dates (to is included) or RFC 3339 times. It defaults to today.
+func GetSalesReport(s *store.Store) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		from, to, err := reportWindow(ctx.Query("from"), ctx.Query("to"))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		restaurantId := ctx.GetString("restaurant_id")
+
+		currency, _, err := restaurantMoney(c, s, restaurantId)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching the restaurant"})
+			return
+		}
+
+		payments, err := s.Payments.ListBetween(c, restaurantId, from, to)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while listing the payments"})
+			return
+		}
+
+		creditNotes, err := s.CreditNotes.List(c, restaurantId, from, to)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while listing the credit notes"})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, salesReport(from, to, currency, payments, creditNotes))
+	}
+}
+
+func salesReport(from time.Time, to time.Time, currency string, payments []models.Payment, creditNotes []models.CreditNote) models.SalesReport {
+	zero := money.Zero(currency)
+	report := models.SalesReport{
+		From: from, To: to,
+		Gross: zero, Tips: zero, Refunds: zero, Tips_refunded: zero, Net: zero, Voided: zero,
+		Tenders: []models.TenderTotal{},
+		Reasons: []models.ReasonTotal{},
+	}
+
+	tenders := map[string]*models.TenderTotal{}
+	tender := func(name string) *models.TenderTotal {
+		if tenders[name] == nil {
+			tenders[name] = &models.TenderTotal{Tender: name, Gross: zero, Tips: zero, Refunds: zero, Tips_refunded: zero, Net: zero}
+		}
+		return tenders[name]
+	}
+
+	for _, payment := range payments {
+		total := tender(payment.Tender)
+		total.Payments++
+		total.Gross = total.Gross.Add(payment.Amount)
+		total.Tips = total.Tips.Add(payment.Tip)
+	}
+
+	reasons := map[[2]string]*models.ReasonTotal{}
+
+	for _, creditNote := range creditNotes {
+		key := [2]string{creditNote.Kind, creditNote.Reason}
+		if reasons[key] == nil {
+			reasons[key] = &models.ReasonTotal{Kind: creditNote.Kind, Reason: creditNote.Reason, Amount: zero}
+		}
+		reasons[key].Count++
+		reasons[key].Amount = reasons[key].Amount.Add(creditNote.Amount)
+
+		switch creditNote.Kind {
+		case models.CreditRefund:
+			total := tender(creditNote.Tender)
+			total.Refunds = total.Refunds.Add(creditNote.Amount)
+			total.Tips_refunded = total.Tips_refunded.Add(creditNote.Tip)
+		case models.CreditItemVoid:
+			report.Voids++
+			report.Voided = report.Voided.Add(creditNote.Amount)
+		}
+	}
+
+	for _, total := range tenders {
+		total.Net = total.Gross.Sub(total.Refunds)
+
+		report.Payments += total.Payments
+		report.Gross = report.Gross.Add(total.Gross)
+		report.Tips = report.Tips.Add(total.Tips)
+		report.Refunds = report.Refunds.Add(total.Refunds)
+		report.Tips_refunded = report.Tips_refunded.Add(total.Tips_refunded)
+		report.Tenders = append(report.Tenders, *total)
+	}
+	report.Net = report.Gross.Sub(report.Refunds)
+
+	for _, total := range reasons {
+		report.Reasons = append(report.Reasons, *total)
+	}
+
+	sort.Slice(report.Tenders, func(i, j int) bool { return report.Tenders[i].Tender < report.Tenders[j].Tender })
+	sort.Slice(report.Reasons, func(i, j int) bool {
+		if report.Reasons[i].Kind != report.Reasons[j].Kind {
+			return report.Reasons[i].Kind < report.Reasons[j].Kind
+		}
+		return report.Reasons[i].Reason < report.Reasons[j].Reason
+	})
+
+	return report
+}
+
+// reportWindow reads the from and to of a report. A date means the whole
+// day, in UTC.
+func reportWindow(fromValue string, toValue string) (time.Time, time.Time, error) {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	from, to := today, today.Add(24*time.Hour)
+
+	if fromValue != "" {
+		t, _, err := reportTime(fromValue)
+		if err != nil {
+			return from, to, err
+		}
+		from = t
+	}
+
+	if toValue != "" {
+		t, date, err := reportTime(toValue)
+		if err != nil {
+			return from, to, err
+		}
+		to = t
+		if date {
+			to = t.Add(24 * time.Hour)
+		}
+	} else if fromValue != "" {
+		to = time.Now().UTC()
+	}
+
+	if !from.Before(to) {
+		return from, to, errors.New("from must be before to")
+	}
+
+	return from, to, nil
+}
+
+func reportTime(value string) (time.Time, bool, error) {
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, true, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	return t, false, err
+}
+
+// GetTipReport shares out the tips between ?from and ?to, or over the times
+// of ?shift_id, among the staff on shift.
+func GetTipReport(s *store.Store) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		restaurantId := ctx.GetString("restaurant_id")
+
+		from, to, err := reportWindow(ctx.Query("from"), ctx.Query("to"))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if shiftId := ctx.Query("shift_id"); shiftId != "" {
+			shift, err := s.Shifts.Get(c, restaurantId, shiftId)
+			if err != nil {
+				ctx.JSON(http.StatusNotFound, gin.H{"error": "shift was not found"})
+				return
+			}
+
+			// Times are kept to the second, so the second of the clock out
+			// still belongs to the shift.
+			from, to = shift.Clock_in, time.Now().UTC().Add(time.Second)
+			if shift.Clock_out != nil {
+				to = shift.Clock_out.Add(time.Second)
+			}
+		}
+
+		restaurant, err := s.Restaurants.Get(c, restaurantId)
+		if err != nil && !errors.Is(err, store.ErrNotFound) {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while fetching the restaurant"})
+			return
+		}
+
+		payments, err := s.Payments.ListBetween(c, restaurantId, from, to)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while listing the payments"})
+			return
+		}
+
+		shifts, err := s.Shifts.List(c, restaurantId, from, to)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while listing the shifts"})
+			return
+		}
+
+		currency, rounding := restaurant.Money()
+		report := tipReport(from, to, restaurant.TipPool(), currency, rounding, payments, shifts)
+
+		for i, share := range report.Staff {
+			if user, err := s.Users.Get(c, share.User_id); err == nil {
+				report.Staff[i].Name = user.First_name + " " + user.Last_name
+				if report.Staff[i].Role == "" {
+					report.Staff[i].Role = user.Role
+				}
+			}
+		}
+
+		ctx.JSON(http.StatusOK, report)
+	}
+}
+
+func tipReport(from time.Time, to time.Time, rules models.TipPoolRules, currency string, rounding money.Rounding, payments []models.Payment, shifts []models.Shift) models.TipReport {
+	zero := money.Zero(currency)
+	report := models.TipReport{
+		From: from, To: to, Rules: rules,
+		Tips: zero, Gratuity: zero, Pool: zero, Undistributed: zero,
+		Staff: []models.TipShare{},
+	}
+
+	shares := map[string]*models.TipShare{}
+	order := []string{}
+	share := func(userId string) *models.TipShare {
+		if shares[userId] == nil {
+			shares[userId] = &models.TipShare{User_id: userId, Tips: zero, Gratuity: zero, Kept: zero, Pool_share: zero, Total: zero}
+			order = append(order, userId)
+		}
+		return shares[userId]
+	}
+
+	now := time.Now().UTC()
+	for _, shift := range shifts {
+		staff := share(shift.User_id)
+		staff.Role = shift.Role
+		staff.Minutes += shift.Minutes(from, to, now)
+	}
+
+	for _, payment := range payments {
+		tip := payment.Tip.Sub(payment.Tip_refunded)
+
+		// A refund takes back its share of the service charge.
+		gratuity := zero.Add(payment.Gratuity)
+		if !payment.Refunded.IsZero() && payment.Amount.Amount > 0 {
+			gratuity = gratuity.Sub(gratuity.Ratio(payment.Refunded.Amount, payment.Amount.Amount, rounding))
+		}
+
+		report.Tips = report.Tips.Add(tip)
+		report.Gratuity = report.Gratuity.Add(gratuity)
+
+		taken := tip.Add(gratuity)
+		pooled := taken.Percent(rules.Pool_basis_points, rounding)
+		if payment.Server_id == "" {
+			pooled = taken
+		} else {
+			staff := share(payment.Server_id)
+			staff.Tips = staff.Tips.Add(tip)
+			staff.Gratuity = staff.Gratuity.Add(gratuity)
+			staff.Kept = staff.Kept.Add(taken.Sub(pooled))
+		}
+
+		report.Pool = report.Pool.Add(pooled)
+	}
+
+	weights := make([]int64, len(order))
+	for i, userId := range order {
+		staff := shares[userId]
+		if staff.Minutes == 0 {
+			continue
+		}
+
+		staff.Points = rules.Role_weights[staff.Role]
+		if rules.By_hours {
+			staff.Points *= staff.Minutes
+		}
+		weights[i] = staff.Points
+	}
+
+	distributed := zero
+	poolShares := report.Pool.Allocate(weights)
+	for i, userId := range order {
+		staff := shares[userId]
+		staff.Pool_share = poolShares[i]
+		staff.Total = staff.Kept.Add(staff.Pool_share)
+		distributed = distributed.Add(staff.Pool_share)
+		report.Staff = append(report.Staff, *staff)
+	}
+	report.Undistributed = report.Pool.Sub(distributed)
+
+	return report
+}
@@ -0,0 +1,252 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vikas-gouda/go-restraunt-mangement/models"
+	"github.com/vikas-gouda/go-restraunt-mangement/money"
+	"github.com/vikas-gouda/go-restraunt-mangement/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func GetRestaurants(s *store.Store) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		// Owners look after the whole group, everybody else only sees their own site.
+		if ctx.GetString("role") != models.RoleOwner {
+			restaurant, err := s.Restaurants.Get(c, ctx.GetString("restaurant_id"))
+			if errors.Is(err, store.ErrNotFound) {
+				ctx.JSON(http.StatusOK, []models.Restaurant{})
+				return
+			}
+
+			if err != nil {
+				ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while listing the restaurants"})
+				return
+			}
+
+			ctx.JSON(http.StatusOK, []models.Restaurant{restaurant})
+			return
+		}
+
+		allRestaurants, err := s.Restaurants.List(c)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while listing the restaurants"})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, allRestaurants)
+	}
+}
+
+func GetRestaurant(s *store.Store) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		restaurantId := ctx.Param("restaurant_id")
+
+		if restaurantId != ctx.GetString("restaurant_id") && ctx.GetString("role") != models.RoleOwner {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "restaurant was not found"})
+			return
+		}
+
+		restaurant, err := s.Restaurants.Get(c, restaurantId)
+		if err != nil {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "restaurant was not found"})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, restaurant)
+	}
+}
+
+func CreateRestaurant(s *store.Store) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var restaurant models.Restaurant
+
+		if err := ctx.BindJSON(&restaurant); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		validationErr := validate.Struct(restaurant)
+		if validationErr != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		restaurant.Code = strings.ToUpper(restaurant.Code)
+
+		// Stored explicitly so a later change of the defaults can't reprice anything.
+		restaurant.Currency = strings.ToUpper(restaurant.Currency)
+		currency, rounding := restaurant.Money()
+		restaurant.Currency = currency
+		restaurant.Rounding = string(rounding)
+
+		_, err := s.Restaurants.GetByCode(c, restaurant.Code)
+		if err == nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "The restaurant code already exists"})
+			return
+		}
+
+		if !errors.Is(err, store.ErrNotFound) {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "while checking for the restaurant code"})
+			return
+		}
+
+		restaurant.Created_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		restaurant.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		restaurant.ID = primitive.NewObjectID()
+		restaurant.Restaurant_id = restaurant.ID.Hex()
+
+		if err := s.Restaurants.Create(c, restaurant); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Restaurant was not created"})
+			return
+		}
+
+		// The bootstrap owner has no restaurant yet and gets the first one; the
+		// new restaurant shows up in their token after the next refresh.
+		if ctx.GetString("restaurant_id") == "" {
+			owner, err := s.Users.Get(c, ctx.GetString("uid"))
+			if err == nil {
+				owner.Restaurant_id = restaurant.Restaurant_id
+				err = s.Users.Update(c, owner)
+			}
+
+			if err != nil {
+				ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to attach the restaurant to the owner"})
+				return
+			}
+		}
+
+		ctx.JSON(http.StatusOK, restaurant)
+	}
+}
+
+// restaurantPatch lets the numbers be set back to 0; every other field is
+// left alone when empty.
+type restaurantPatch struct {
+	models.Restaurant
+	Auto_gratuity_guests       *int   `json:"auto_gratuity_guests"`
+	Auto_gratuity_basis_points *int64 `json:"auto_gratuity_basis_points"`
+	Invoice_digits             *int   `json:"invoice_digits"`
+	Fiscal_year_start          *int   `json:"fiscal_year_start"`
+}
+
+func UpdateRestaurant(s *store.Store) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var restaurant restaurantPatch
+
+		if err := ctx.BindJSON(&restaurant); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		restaurantId := ctx.Param("restaurant_id")
+
+		foundRestaurant, err := s.Restaurants.Get(c, restaurantId)
+		if err != nil {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "restaurant was not found"})
+			return
+		}
+
+		if restaurant.Name != "" {
+			foundRestaurant.Name = restaurant.Name
+		}
+
+		if restaurant.Address != "" {
+			foundRestaurant.Address = restaurant.Address
+		}
+
+		if restaurant.Phone != "" {
+			foundRestaurant.Phone = restaurant.Phone
+		}
+
+		if restaurant.Rounding != "" {
+			foundRestaurant.Rounding = restaurant.Rounding
+		}
+
+		if restaurant.Auto_gratuity_guests != nil {
+			foundRestaurant.Auto_gratuity_guests = *restaurant.Auto_gratuity_guests
+		}
+
+		if restaurant.Auto_gratuity_basis_points != nil {
+			foundRestaurant.Auto_gratuity_basis_points = *restaurant.Auto_gratuity_basis_points
+		}
+
+		if restaurant.Tip_pool != nil {
+			foundRestaurant.Tip_pool = restaurant.Tip_pool
+		}
+
+		if restaurant.Receipt_qr_url != "" {
+			foundRestaurant.Receipt_qr_url = restaurant.Receipt_qr_url
+		}
+
+		if restaurant.Receipt_footer != "" {
+			foundRestaurant.Receipt_footer = restaurant.Receipt_footer
+		}
+
+		if restaurant.Invoice_format != "" {
+			foundRestaurant.Invoice_format = restaurant.Invoice_format
+		}
+
+		if restaurant.Invoice_digits != nil {
+			foundRestaurant.Invoice_digits = *restaurant.Invoice_digits
+		}
+
+		if restaurant.Fiscal_year_start != nil {
+			foundRestaurant.Fiscal_year_start = *restaurant.Fiscal_year_start
+		}
+
+		// Every price is kept in the restaurant's currency, so it is fixed. Older
+		// restaurants without one priced everything in the default currency.
+		currency, _ := foundRestaurant.Money()
+		if restaurant.Currency != "" && !strings.EqualFold(restaurant.Currency, currency) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "the currency of a restaurant can not be changed"})
+			return
+		}
+
+		foundRestaurant.Currency = currency
+
+		validationErr := validate.Struct(foundRestaurant)
+		if validationErr != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		foundRestaurant.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+
+		if err := s.Restaurants.Update(c, foundRestaurant); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update the restaurant"})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, foundRestaurant)
+	}
+}
+
+// restaurantMoney returns the currency and rounding mode of a restaurant, or
+// the defaults when there is no such restaurant.
+func restaurantMoney(c context.Context, s *store.Store, restaurantId string) (string, money.Rounding, error) {
+	restaurant, err := s.Restaurants.Get(c, restaurantId)
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		return "", "", err
+	}
+
+	currency, rounding := restaurant.Money()
+	return currency, rounding, nil
+}
@@ -0,0 +1,48 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vikas-gouda/go-restraunt-mangement/models"
+	"github.com/vikas-gouda/go-restraunt-mangement/money"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestRestaurantCurrencyIsFixed(t *testing.T) {
+	a := newTestApp(t)
+
+	// From before restaurants kept their currency, priced in the default one.
+	legacy := models.Restaurant{ID: primitive.NewObjectID(), Name: "Old", Code: "OLD"}
+	legacy.Restaurant_id = legacy.ID.Hex()
+	if err := a.s.Restaurants.Create(context.Background(), legacy); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		restaurant models.Restaurant
+		currency   string
+		status     int
+	}{
+		{a.restaurant, "EUR", http.StatusBadRequest},
+		{a.restaurant, "usd", http.StatusOK},
+		{legacy, "EUR", http.StatusBadRequest},
+		{legacy, money.DefaultCurrency, http.StatusOK},
+	} {
+		var updated models.Restaurant
+		w := a.do(http.MethodPatch, "/restaurants/"+test.restaurant.Restaurant_id, gin.H{"currency": test.currency})
+		if w.Code != test.status {
+			t.Errorf("%s to %s: got %d, want %d: %s", test.restaurant.Code, test.currency, w.Code, test.status, w.Body.String())
+			continue
+		}
+
+		if test.status == http.StatusOK {
+			a.expect(w, http.StatusOK, &updated)
+			if updated.Currency != money.DefaultCurrency {
+				t.Errorf("%s: currency is %q, want %s", test.restaurant.Code, updated.Currency, money.DefaultCurrency)
+			}
+		}
+	}
+}
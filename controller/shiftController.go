@@ -0,0 +1,187 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vikas-gouda/go-restraunt-mangement/models"
+	"github.com/vikas-gouda/go-restraunt-mangement/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func GetShifts(s *store.Store) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		from, to, err := reportWindow(ctx.Query("from"), ctx.Query("to"))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		allShifts, err := s.Shifts.List(c, ctx.GetString("restaurant_id"), from, to)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while listing the shifts"})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, allShifts)
+	}
+}
+
+func ClockIn(s *store.Store) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		user, status, body := shiftUser(c, s, ctx)
+		if status != http.StatusOK {
+			ctx.JSON(status, body)
+			return
+		}
+
+		var shift models.Shift
+
+		shift.Clock_in, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		shift.Created_at = shift.Clock_in
+		shift.Updated_at = shift.Clock_in
+		shift.Open = true
+		shift.User_id = user.User_id
+		shift.Role = user.Role
+		shift.ID = primitive.NewObjectID()
+		shift.Shift_id = shift.ID.Hex()
+		shift.Restaurant_id = ctx.GetString("restaurant_id")
+
+		err := s.Shifts.ClockIn(c, shift)
+		if errors.Is(err, store.ErrDuplicate) {
+			ctx.JSON(http.StatusConflict, gin.H{"error": "user is clocked in already"})
+			return
+		}
+
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to clock in"})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, shift)
+	}
+}
+
+func ClockOut(s *store.Store) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		user, status, body := shiftUser(c, s, ctx)
+		if status != http.StatusOK {
+			ctx.JSON(status, body)
+			return
+		}
+
+		at, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+
+		shift, err := s.Shifts.ClockOut(c, ctx.GetString("restaurant_id"), user.User_id, at)
+		if errors.Is(err, store.ErrNotFound) {
+			ctx.JSON(http.StatusConflict, gin.H{"error": "user is not clocked in"})
+			return
+		}
+
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to clock out"})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, shift)
+	}
+}
+
+// UpdateShift lets managers fix the times of a shift. Giving an open shift a
+// clock out closes it.
+func UpdateShift(s *store.Store) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var patch models.ShiftPatch
+
+		if err := ctx.BindJSON(&patch); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		shift, err := s.Shifts.Get(c, ctx.GetString("restaurant_id"), ctx.Param("shift_id"))
+		if err != nil {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "shift was not found"})
+			return
+		}
+
+		if patch.Clock_in != nil {
+			shift.Clock_in = patch.Clock_in.UTC()
+		}
+
+		if patch.Clock_out != nil {
+			clockOut := patch.Clock_out.UTC()
+			shift.Clock_out = &clockOut
+			shift.Open = false
+		}
+
+		if shift.Clock_out != nil && !shift.Clock_out.After(shift.Clock_in) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "clock_out must be after clock_in"})
+			return
+		}
+
+		shift.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+
+		if err := s.Shifts.Update(c, shift); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update the shift"})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, shift)
+	}
+}
+
+// shiftUser is who a clock in or out is for: the caller, or for managers the
+// user_id in the body. Anything but 200 is the response to give instead.
+func shiftUser(c context.Context, s *store.Store, ctx *gin.Context) (models.User, int, gin.H) {
+	var request models.ShiftRequest
+
+	if err := ctx.ShouldBindJSON(&request); err != nil && !errors.Is(err, io.EOF) {
+		return models.User{}, http.StatusBadRequest, gin.H{"error": err.Error()}
+	}
+
+	userId := ctx.GetString("uid")
+	if request.User_id != "" && request.User_id != userId {
+		if role := ctx.GetString("role"); role != models.RoleManager && role != models.RoleOwner {
+			return models.User{}, http.StatusForbidden, gin.H{"error": "only managers can clock in or out for someone else"}
+		}
+		userId = request.User_id
+	}
+
+	user, err := s.Users.Get(c, userId)
+	if err != nil || (user.Restaurant_id != ctx.GetString("restaurant_id") && user.Role != models.RoleOwner) {
+		return user, http.StatusNotFound, gin.H{"error": "user was not found"}
+	}
+
+	return user, http.StatusOK, nil
+}
+
+// checkServer makes sure an order is given to someone who works at the
+// restaurant. Anything but 200 is the response to give instead.
+func checkServer(c context.Context, s *store.Store, restaurantId string, userId string) (int, gin.H) {
+	user, err := s.Users.Get(c, userId)
+	if errors.Is(err, store.ErrNotFound) || (err == nil && user.Restaurant_id != restaurantId && user.Role != models.RoleOwner) {
+		return http.StatusBadRequest, gin.H{"error": "server_id is not a user of this restaurant"}
+	}
+
+	if err != nil {
+		return http.StatusInternalServerError, gin.H{"error": "Error while fetching the server"}
+	}
+
+	return http.StatusOK, nil
+}
@@ -2,52 +2,43 @@ package controller
 
 import (
 	"context"
-	"log"
+	"errors"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/vikas-gouda/go-restraunt-mangement/database"
 	"github.com/vikas-gouda/go-restraunt-mangement/models"
-	"go.mongodb.org/mongo-driver/bson"
+	"github.com/vikas-gouda/go-restraunt-mangement/store"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-var tableCollection *mongo.Collection = database.OpenCollection(database.Client, "table")
-
-func GetTables() gin.HandlerFunc {
+func GetTables(s *store.Store) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
-
-		result, err := tableCollection.Find(context.TODO(), bson.M{})
 		defer cancel()
+
+		allTables, err := s.Tables.List(c, ctx.GetString("restaurant_id"))
 		if err != nil {
 			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
 
-		var allTables []bson.M
-		if err := result.All(c, &allTables); err != nil {
-			log.Fatal(err)
-			return
-		}
-
 		ctx.JSON(http.StatusOK, allTables)
 	}
 }
 
-func GetTable() gin.HandlerFunc {
+func GetTable(s *store.Store) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
 
 		table_id := ctx.Param("table_id")
 
-		var table models.Table
-
-		err := tableCollection.FindOne(c, bson.M{"table_id": table_id}).Decode(&table)
-		defer cancel()
+		table, err := s.Tables.Get(c, ctx.GetString("restaurant_id"), table_id)
+		if errors.Is(err, store.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Table not found"})
+			return
+		}
 
 		if err != nil {
 			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -58,9 +49,10 @@ func GetTable() gin.HandlerFunc {
 	}
 }
 
-func CreateTable() gin.HandlerFunc {
+func CreateTable(s *store.Store) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
 
 		var table models.Table
 
@@ -80,22 +72,22 @@ func CreateTable() gin.HandlerFunc {
 
 		table.ID = primitive.NewObjectID()
 		table.Table_id = table.ID.Hex()
+		table.Restaurant_id = ctx.GetString("restaurant_id")
 
-		result, insertErr := tableCollection.InsertOne(c, table)
-		if insertErr != nil {
+		if err := s.Tables.Create(c, table); err != nil {
 			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while inserting"})
 			return
 		}
-		defer cancel()
 
-		ctx.JSON(http.StatusOK, result)
+		ctx.JSON(http.StatusOK, table)
 
 	}
 }
 
-func UpdateTable() gin.HandlerFunc {
+func UpdateTable(s *store.Store) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
 
 		var table models.Table
 
@@ -106,34 +98,28 @@ func UpdateTable() gin.HandlerFunc {
 			return
 		}
 
-		var updateObj primitive.D
+		foundTable, err := s.Tables.Get(c, ctx.GetString("restaurant_id"), tableId)
+		if err != nil {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Table not found"})
+			return
+		}
 
 		if table.Number_of_guests != 0 {
-			updateObj = append(updateObj, bson.E{"number_of_guests", table.Number_of_guests})
+			foundTable.Number_of_guests = table.Number_of_guests
 		}
 
 		if table.Table_number != 0 {
-			updateObj = append(updateObj, bson.E{"table_number", table.Table_number})
+			foundTable.Table_number = table.Table_number
 		}
 
-		table.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		foundTable.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
 
-		upsert := true
-		opt := options.UpdateOptions{
-			Upsert: &upsert,
-		}
-
-		filter := bson.M{"table_id": tableId}
-
-		result, err := tableCollection.UpdateOne(c, filter, bson.D{{"$set", updateObj}}, &opt)
-
-		if err != nil {
+		if err := s.Tables.Update(c, foundTable); err != nil {
 			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Table item update failed"})
 			return
 		}
 
-		defer cancel()
-		ctx.JSON(http.StatusOK, result)
+		ctx.JSON(http.StatusOK, foundTable)
 
 	}
 }
@@ -0,0 +1,144 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vikas-gouda/go-restraunt-mangement/models"
+	"github.com/vikas-gouda/go-restraunt-mangement/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// taxRatePatch is the body of UpdateTaxRate. Pointers tell a zero rate or
+// switching inclusive off apart from leaving them alone.
+type taxRatePatch struct {
+	Name         string  `json:"name" validate:"omitempty,max=50"`
+	Category     *string `json:"category" validate:"omitempty,max=30"`
+	Basis_points *int64  `json:"basis_points" validate:"omitempty,gte=0,lte=10000"`
+	Inclusive    *bool   `json:"inclusive"`
+}
+
+func GetTaxRates(s *store.Store) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		allTaxRates, err := s.TaxRates.List(c, ctx.GetString("restaurant_id"))
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, allTaxRates)
+	}
+}
+
+func GetTaxRate(s *store.Store) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		taxRate, err := s.TaxRates.Get(c, ctx.GetString("restaurant_id"), ctx.Param("tax_rate_id"))
+		if errors.Is(err, store.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "tax rate not found"})
+			return
+		}
+
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error occured while fetching the tax rate"})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, taxRate)
+	}
+}
+
+func CreateTaxRate(s *store.Store) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var taxRate models.TaxRate
+
+		if err := ctx.BindJSON(&taxRate); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		validationErr := validate.Struct(taxRate)
+		if validationErr != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		taxRate.Category = strings.ToUpper(taxRate.Category)
+		taxRate.Created_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		taxRate.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		taxRate.ID = primitive.NewObjectID()
+		taxRate.Tax_rate_id = taxRate.ID.Hex()
+		taxRate.Restaurant_id = ctx.GetString("restaurant_id")
+
+		if err := s.TaxRates.Create(c, taxRate); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Tax rate was not created"})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, taxRate)
+	}
+}
+
+func UpdateTaxRate(s *store.Store) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var patch taxRatePatch
+
+		if err := ctx.BindJSON(&patch); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		validationErr := validate.Struct(patch)
+		if validationErr != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		foundTaxRate, err := s.TaxRates.Get(c, ctx.GetString("restaurant_id"), ctx.Param("tax_rate_id"))
+		if err != nil {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "tax rate not found"})
+			return
+		}
+
+		if patch.Name != "" {
+			foundTaxRate.Name = patch.Name
+		}
+
+		// An empty category makes the rate apply to everything.
+		if patch.Category != nil {
+			foundTaxRate.Category = strings.ToUpper(*patch.Category)
+		}
+
+		if patch.Basis_points != nil {
+			foundTaxRate.Basis_points = *patch.Basis_points
+		}
+
+		if patch.Inclusive != nil {
+			foundTaxRate.Inclusive = *patch.Inclusive
+		}
+
+		foundTaxRate.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+
+		if err := s.TaxRates.Update(c, foundTaxRate); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update the tax rate"})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, foundTaxRate)
+	}
+}
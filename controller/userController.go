@@ -2,6 +2,7 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -9,20 +10,17 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/vikas-gouda/go-restraunt-mangement/database"
 	"github.com/vikas-gouda/go-restraunt-mangement/helpers"
 	"github.com/vikas-gouda/go-restraunt-mangement/models"
-	"go.mongodb.org/mongo-driver/bson"
+	"github.com/vikas-gouda/go-restraunt-mangement/store"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
 	"golang.org/x/crypto/bcrypt"
 )
 
-var userCollection *mongo.Collection = database.OpenCollection(database.Client, "user")
-
-func GetUsers() gin.HandlerFunc {
+func GetUsers(s *store.Store) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
 
 		recordPerPage, err := strconv.Atoi(ctx.Query("recordPerPage"))
 		if err != nil || recordPerPage < 1 {
@@ -35,157 +33,545 @@ func GetUsers() gin.HandlerFunc {
 		}
 
 		startIndex := (page - 1) * recordPerPage
-		startIndex, err = strconv.Atoi(ctx.Query("startIndex"))
-
-		matchStage := bson.D{{"$match", bson.D{{}}}}
-		projectStage := bson.D{
-			{
-				"$project", bson.D{
-					{"_id", 0},
-					{"total_count", 1},
-					{"user_items", bson.D{
-						{"$slice", []interface{}{"$data", startIndex, recordPerPage}},
-					}},
-				},
-			},
+		if index, err := strconv.Atoi(ctx.Query("startIndex")); err == nil && index >= 0 {
+			startIndex = index
 		}
 
-		result, err := userCollection.Aggregate(c, mongo.Pipeline{
-			matchStage, projectStage,
-		})
+		// Owners look after the whole group and may pick the restaurant.
+		restaurantId := ctx.GetString("restaurant_id")
+		if ctx.GetString("role") == models.RoleOwner && ctx.Query("restaurant_id") != "" {
+			restaurantId = ctx.Query("restaurant_id")
+		}
 
-		defer cancel()
+		total, allUsers, err := s.Users.List(c, restaurantId, startIndex, recordPerPage)
 		if err != nil {
 			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "error ocured while listing user items"})
 			return
 		}
 
-		var allUsers []bson.M
-		if err = result.All(c, &allUsers); err != nil {
-			log.Fatal(err.Error())
-		}
-
-		ctx.JSON(http.StatusOK, allUsers[0])
+		ctx.JSON(http.StatusOK, gin.H{"total_count": total, "user_items": allUsers})
 
 	}
 }
 
-func GetUser() gin.HandlerFunc {
+func GetUser(s *store.Store) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
 		userId := ctx.Param("user_id")
 
-		var user models.User
+		user, err := s.Users.Get(c, userId)
+		if err != nil || !inRestaurant(ctx, user) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Error while listing the user"})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, user)
+	}
+}
 
-		err := userCollection.FindOne(c, bson.M{"user_id": userId}).Decode(&user)
+// UserRequest is an account to create along with its password, which is
+// only kept as a hash and never sent back.
+type UserRequest struct {
+	models.User
+	Password string `json:"password" validate:"required"`
+}
+
+type LoginRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// SignedInUser is the account along with the tokens of its new session.
+type SignedInUser struct {
+	models.User
+	Token         string `json:"token"`
+	Refresh_token string `json:"refresh_token"`
+}
 
+// SignUp creates the very first account, the owner who sets up the
+// restaurants. Everybody after that is added by a manager with CreateUser.
+func SignUp(s *store.Store) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
 		defer cancel()
 
+		var request UserRequest
+
+		if err := ctx.BindJSON(&request); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		validateErr := validate.Struct(request)
+		if validateErr != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": validateErr.Error()})
+			return
+		}
+
+		// Checked up front to spare hashing the password; CreateFirst settles
+		// sign ups that come in at the same time.
+		countUsers, err := s.Users.Count(c)
 		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while listing the user"})
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "while checking for existing users"})
+			return
 		}
 
-		ctx.JSON(http.StatusOK, user)
+		if countUsers > 0 {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": "sign up is closed, a manager has to create your account"})
+			return
+		}
+
+		user := request.User
+		user.Role = models.RoleOwner
+
+		if status, body := newUser(c, s, &user, request.Password); status != http.StatusOK {
+			ctx.JSON(status, body)
+			return
+		}
+
+		sessionId := helpers.NewSessionId()
+		token, refreshToken, _ := helpers.GenerateAllTokens(user.Email, user.First_name, user.Last_name, user.User_id, user.Role, user.Restaurant_id, sessionId)
+
+		created, err := s.Users.CreateFirst(c, user)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "User item is not created"})
+			return
+		}
+
+		// Somebody else signed up first in the meantime.
+		if !created {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": "sign up is closed, a manager has to create your account"})
+			return
+		}
+
+		session := helpers.NewSession(sessionId, user.User_id, refreshToken, ctx.Request.UserAgent(), ctx.ClientIP())
+		if err := s.Sessions.Create(c, session); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create the session"})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, SignedInUser{User: user, Token: token, Refresh_token: refreshToken})
 	}
 }
 
-func SignUp() gin.HandlerFunc {
+// CreateUser adds a staff account to the restaurant of the manager. Owners
+// may give another restaurant of the group. New staff start as waiters and
+// sign in with Login.
+func CreateUser(s *store.Store) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
 
-		var user models.User
+		var request UserRequest
 
-		if err := ctx.BindJSON(&user); err != nil {
+		if err := ctx.BindJSON(&request); err != nil {
 			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
 
-		validateErr := validate.Struct(user)
+		validateErr := validate.Struct(request)
 		if validateErr != nil {
 			ctx.JSON(http.StatusBadRequest, gin.H{"error": validateErr.Error()})
 			return
 		}
 
-		countEmail, err := userCollection.CountDocuments(c, bson.M{"email": user.Email})
+		user := request.User
+
+		// Roles are never assigned on creation, only owners change them.
+		user.Role = models.RoleWaiter
+
+		if user.Restaurant_id == "" || ctx.GetString("role") != models.RoleOwner {
+			user.Restaurant_id = ctx.GetString("restaurant_id")
+		}
+
+		if user.Restaurant_id == "" {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "restaurant_id is required"})
+			return
+		}
+
+		if status, body := newUser(c, s, &user, request.Password); status != http.StatusOK {
+			ctx.JSON(status, body)
+			return
+		}
+
+		if err := s.Users.Create(c, user); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "User item is not created"})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, user)
+	}
+}
+
+// newUser checks that the email, phone number and restaurant of a new account
+// are fine and fills in its ids, timestamps and password hash. Anything but
+// 200 is the response to give instead.
+func newUser(c context.Context, s *store.Store, user *models.User, password string) (int, gin.H) {
+	_, err := s.Users.GetByEmail(c, user.Email)
+	emailTaken := err == nil
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		return http.StatusInternalServerError, gin.H{"error": "while checking for the email"}
+	}
+
+	_, err = s.Users.GetByPhone(c, user.Phone)
+	phoneTaken := err == nil
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		return http.StatusInternalServerError, gin.H{"error": "while checking for the phone number"}
+	}
+
+	if emailTaken || phoneTaken {
+		return http.StatusInternalServerError, gin.H{"error": "The email or phone number already exists"}
+	}
+
+	// Only the bootstrap owner may sign up before any restaurant exists, every
+	// other account belongs to exactly one restaurant.
+	if user.Restaurant_id != "" {
+		_, err := s.Restaurants.Get(c, user.Restaurant_id)
+		if errors.Is(err, store.ErrNotFound) {
+			return http.StatusBadRequest, gin.H{"error": "restaurant was not found"}
+		}
+
+		if err != nil {
+			return http.StatusInternalServerError, gin.H{"error": "while checking for the restaurant"}
+		}
+	}
+
+	user.Password = HashPassword(password)
+	user.Created_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	user.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	user.ID = primitive.NewObjectID()
+	user.User_id = user.ID.Hex()
+
+	return http.StatusOK, nil
+}
+
+func Login(s *store.Store) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		var request LoginRequest
+
+		if err := ctx.BindJSON(&request); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		foundUser, err := s.Users.GetByEmail(c, request.Email)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "user not found"})
+			return
+		}
+
+		passwordisValid, msg := VerifyPassword(request.Password, foundUser.Password)
+		if passwordisValid != true {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+			return
+		}
+
+		sessionId := helpers.NewSessionId()
+		token, refreshToken, _ := helpers.GenerateAllTokens(foundUser.Email, foundUser.First_name, foundUser.Last_name, foundUser.User_id, foundUser.Role, foundUser.Restaurant_id, sessionId)
+
+		session := helpers.NewSession(sessionId, foundUser.User_id, refreshToken, ctx.Request.UserAgent(), ctx.ClientIP())
+		if err := s.Sessions.Create(c, session); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create the session"})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, SignedInUser{User: foundUser, Token: token, Refresh_token: refreshToken})
+
+	}
+}
+
+type RefreshRequest struct {
+	Refresh_token string `json:"refresh_token" validate:"required"`
+}
+
+func RefreshToken(s *store.Store) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
 		defer cancel()
 
+		var request RefreshRequest
+
+		if err := ctx.BindJSON(&request); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		validationErr := validate.Struct(request)
+		if validationErr != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		claims, msg := helpers.ValidateToken(request.Refresh_token)
+		if msg != "" {
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": msg})
+			return
+		}
+
+		if claims.Token_type != helpers.RefreshToken {
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "a refresh token is required"})
+			return
+		}
+
+		active, err := s.Sessions.Active(c, claims.Id)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while checking the session"})
+			return
+		}
+
+		if !active {
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "the session has been revoked or has expired"})
+			return
+		}
+
+		foundUser, err := s.Users.Get(c, claims.Uid)
 		if err != nil {
-			log.Panic(err)
-			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "while checking for the email"})
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "user not found"})
 			return
 		}
 
-		password := HashPassword(user.Password)
-		user.Password = password
+		token, refreshToken, err := helpers.GenerateAllTokens(foundUser.Email, foundUser.First_name, foundUser.Last_name, foundUser.User_id, foundUser.Role, foundUser.Restaurant_id, claims.Id)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate the tokens"})
+			return
+		}
 
-		countPhone, err := userCollection.CountDocuments(c, bson.M{"phone": user.Phone})
+		expiresAt := time.Now().UTC().Truncate(time.Second).Add(helpers.RefreshTokenLifetime)
+		rotated, err := s.Sessions.Rotate(c, claims.Id, helpers.HashToken(request.Refresh_token), helpers.HashToken(refreshToken), expiresAt)
 		if err != nil {
-			log.Panic(err)
-			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "while checking for the phone number"})
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to rotate the tokens"})
 			return
 		}
 
-		if countEmail > 0 || countPhone > 0 {
-			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "The email or phone number already exists"})
+		// A valid but no longer current refresh token means it was used before,
+		// so whoever holds it loses the session along with the real user.
+		if !rotated {
+			if _, err := s.Sessions.Revoke(c, foundUser.User_id, claims.Id); err != nil {
+				log.Println(err)
+			}
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "refresh token reuse detected, please login again"})
 			return
 		}
 
-		user.Created_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
-		user.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
-		user.ID = primitive.NewObjectID()
-		user.User_id = user.ID.Hex()
+		ctx.JSON(http.StatusOK, gin.H{"token": token, "refresh_token": refreshToken})
+	}
+}
+
+func Logout(s *store.Store) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
 
-		token, refreshToken, err := helpers.GenerateAllTokens(user.Email, user.First_name, user.Last_name, user.User_id)
+		if _, err := s.Sessions.Revoke(c, ctx.GetString("uid"), ctx.GetString("session_id")); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to logout"})
+			return
+		}
 
-		user.Token = token
-		user.Refresh_token = refreshToken
+		ctx.JSON(http.StatusOK, gin.H{"message": "logged out"})
+	}
+}
 
-		result, insertErr := userCollection.InsertOne(c, user)
+func GetUserSessions(s *store.Store) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
 
-		if insertErr != nil {
-			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "User item is not created"})
+		userId := ctx.Param("user_id")
+
+		foundUser, err := s.Users.Get(c, userId)
+		if err != nil || !inRestaurant(ctx, foundUser) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+			return
+		}
+
+		if !canManageUser(ctx, userId) {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": "You are not allowed to perform this action"})
+			return
+		}
+
+		sessions, err := s.Sessions.List(c, userId)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while listing the sessions"})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, sessions)
+	}
+}
+
+func RevokeUserSession(s *store.Store) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		userId := ctx.Param("user_id")
+		sessionId := ctx.Param("session_id")
+
+		foundUser, err := s.Users.Get(c, userId)
+		if err != nil || !inRestaurant(ctx, foundUser) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+			return
+		}
+
+		if !canManageUser(ctx, userId) {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": "You are not allowed to perform this action"})
+			return
+		}
+
+		revoked, err := s.Sessions.Revoke(c, userId, sessionId)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke the session"})
+			return
+		}
+
+		if !revoked {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "session not found"})
 			return
 		}
 
+		ctx.JSON(http.StatusOK, gin.H{"revoked": 1})
+	}
+}
+
+func RevokeUserSessions(s *store.Store) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
 		defer cancel()
 
-		ctx.JSON(http.StatusOK, result)
+		userId := ctx.Param("user_id")
+
+		foundUser, err := s.Users.Get(c, userId)
+		if err != nil || !inRestaurant(ctx, foundUser) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+			return
+		}
+
+		if !canManageUser(ctx, userId) {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": "You are not allowed to perform this action"})
+			return
+		}
+
+		revoked, err := s.Sessions.RevokeAll(c, userId)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke the sessions"})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"revoked": revoked})
 	}
 }
 
-func Login() gin.HandlerFunc {
+// canManageUser allows staff to look after their own sessions, and managers to
+// look after everybody's.
+func canManageUser(ctx *gin.Context, userId string) bool {
+	role := ctx.GetString("role")
+	return ctx.GetString("uid") == userId || role == models.RoleOwner || role == models.RoleManager
+}
+
+// inRestaurant tells whether the user works at the restaurant of the caller.
+// Owners look after the whole group.
+func inRestaurant(ctx *gin.Context, user models.User) bool {
+	return ctx.GetString("role") == models.RoleOwner || user.Restaurant_id == ctx.GetString("restaurant_id")
+}
+
+func UpdateUserRole(s *store.Store) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
 
 		var user models.User
-		var foundUser models.User
+
+		userId := ctx.Param("user_id")
 
 		if err := ctx.BindJSON(&user); err != nil {
 			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
 
-		err := userCollection.FindOne(c, bson.M{"email": user.Email}).Decode(&user)
+		validationErr := validate.Var(user.Role, "required,eq=OWNER|eq=MANAGER|eq=CASHIER|eq=WAITER|eq=KITCHEN")
+		if validationErr != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "role must be one of OWNER, MANAGER, CASHIER, WAITER or KITCHEN"})
+			return
+		}
+
+		if userId == ctx.GetString("uid") && user.Role != models.RoleOwner {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "owners can not demote themselves"})
+			return
+		}
+
+		foundUser, err := s.Users.Get(c, userId)
+		if err != nil || !inRestaurant(ctx, foundUser) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+			return
+		}
+
+		foundUser.Role = user.Role
+		foundUser.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+
+		if err := s.Users.Update(c, foundUser); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update the user role"})
+			return
+		}
+
+		// Tokens carry the role, so the user has to sign in again.
+		if _, err := s.Sessions.RevokeAll(c, userId); err != nil {
+			log.Println(err)
+		}
+
+		ctx.JSON(http.StatusOK, foundUser)
+	}
+}
 
+func UpdateUserRestaurant(s *store.Store) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
 		defer cancel()
+
+		var user models.User
+
+		userId := ctx.Param("user_id")
+
+		if err := ctx.BindJSON(&user); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		_, err := s.Restaurants.Get(c, user.Restaurant_id)
+		if errors.Is(err, store.ErrNotFound) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "restaurant was not found"})
+			return
+		}
+
 		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "user not found"})
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "while checking for the restaurant"})
 			return
 		}
 
-		passwordisValid, msg := VerifyPassword(user.Password, *&foundUser.Password)
-		defer cancel()
-		if passwordisValid != true {
-			ctx.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+		foundUser, err := s.Users.Get(c, userId)
+		if err != nil || !inRestaurant(ctx, foundUser) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
 			return
 		}
 
-		token, refreshToken, _ := helpers.GenerateAllTokens(foundUser.Email, foundUser.First_name, foundUser.Last_name, foundUser.User_id)
+		foundUser.Restaurant_id = user.Restaurant_id
+		foundUser.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+
+		if err := s.Users.Update(c, foundUser); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update the user restaurant"})
+			return
+		}
 
-		helpers.UpdateAllTokens(token, refreshToken, foundUser.User_id)
+		// Tokens carry the restaurant, so the user has to sign in again.
+		if _, err := s.Sessions.RevokeAll(c, userId); err != nil {
+			log.Println(err)
+		}
 
 		ctx.JSON(http.StatusOK, foundUser)
-
 	}
 }
 
@@ -0,0 +1,175 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vikas-gouda/go-restraunt-mangement/helpers"
+	"github.com/vikas-gouda/go-restraunt-mangement/models"
+	"github.com/vikas-gouda/go-restraunt-mangement/store/memstore"
+)
+
+func TestSignUpMakesTheFirstAccountOwner(t *testing.T) {
+	a := newTestApp(t)
+	a.s = memstore.New()
+	a.router = gin.New()
+	a.router.POST("/users/signup", SignUp(a.s))
+
+	first := gin.H{"first_name": "Ann", "email": "ann@example.com", "password": testPassword, "phone": "5550001", "role": "WAITER"}
+
+	var owner models.User
+	a.expect(a.do(http.MethodPost, "/users/signup", first), http.StatusOK, &owner)
+	if owner.Role != models.RoleOwner {
+		t.Errorf("first account is %s, want OWNER", owner.Role)
+	}
+
+	second := gin.H{"first_name": "Bob", "email": "bob@example.com", "password": testPassword, "phone": "5550002"}
+	a.expect(a.do(http.MethodPost, "/users/signup", second), http.StatusForbidden, nil)
+}
+
+func TestOnlyOneOfConcurrentSignUpsBecomesOwner(t *testing.T) {
+	a := newTestApp(t)
+	a.s = memstore.New()
+	a.router = gin.New()
+	a.router.POST("/users/signup", SignUp(a.s))
+
+	const n = 3
+	statuses := make(chan int, n)
+	for i := 0; i < n; i++ {
+		go func(i int) {
+			w := httptest.NewRecorder()
+			body := fmt.Sprintf(`{"first_name":"Owner","email":"owner%d@example.com","password":%q,"phone":"555000%d"}`, i, testPassword, i)
+			a.router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/users/signup", strings.NewReader(body)))
+			statuses <- w.Code
+		}(i)
+	}
+
+	owners := 0
+	for i := 0; i < n; i++ {
+		if <-statuses == http.StatusOK {
+			owners++
+		}
+	}
+
+	if count, _ := a.s.Users.Count(context.Background()); owners != 1 || count != 1 {
+		t.Errorf("%d sign ups went through and %d users were stored, want 1", owners, count)
+	}
+}
+
+func TestCreateUserAddsAWaiterToTheManagersRestaurant(t *testing.T) {
+	a := newTestApp(t)
+	a.as = a.user(models.RoleManager, a.restaurant.Restaurant_id)
+
+	var user models.User
+	w := a.do(http.MethodPost, "/users", gin.H{
+		"first_name":    "Cy",
+		"email":         "cy@example.com",
+		"password":      testPassword,
+		"phone":         "5550003",
+		"role":          "OWNER",
+		"restaurant_id": "elsewhere",
+	})
+	a.expect(w, http.StatusOK, &user)
+
+	if user.Role != models.RoleWaiter || user.Restaurant_id != a.restaurant.Restaurant_id {
+		t.Errorf("created a %s of %q, want a WAITER of %q", user.Role, user.Restaurant_id, a.restaurant.Restaurant_id)
+	}
+
+	assertNoSecrets(t, w.Body.Bytes())
+}
+
+func TestUserLookupsLeaveOutPasswordAndTokens(t *testing.T) {
+	a := newTestApp(t)
+	a.as = a.user(models.RoleWaiter, a.restaurant.Restaurant_id)
+
+	for _, path := range []string{"/users", "/users/" + a.owner.User_id} {
+		w := a.do(http.MethodGet, path, nil)
+		a.expect(w, http.StatusOK, nil)
+		assertNoSecrets(t, w.Body.Bytes())
+	}
+}
+
+// assertNoSecrets fails when a user in body carries its password hash or
+// tokens.
+func assertNoSecrets(t *testing.T, body []byte) {
+	t.Helper()
+
+	for _, key := range []string{`"password"`, `"token"`, `"refresh_token"`, "$2a$"} {
+		if bytes.Contains(body, []byte(key)) {
+			t.Errorf("%s found in %s", key, body)
+		}
+	}
+}
+
+func TestUpdateUserRoleSignsTheUserOut(t *testing.T) {
+	a := newTestApp(t)
+	waiter := a.user(models.RoleWaiter, a.restaurant.Restaurant_id)
+
+	sessionId := helpers.NewSessionId()
+	if err := a.s.Sessions.Create(context.Background(), helpers.NewSession(sessionId, waiter.User_id, "refresh", "test", "127.0.0.1")); err != nil {
+		t.Fatal(err)
+	}
+
+	a.expect(a.do(http.MethodPatch, "/users/"+waiter.User_id+"/role", gin.H{"role": models.RoleCashier}), http.StatusOK, nil)
+
+	if active, _ := a.s.Sessions.Active(context.Background(), sessionId); active {
+		t.Error("the session outlived the role change")
+	}
+}
+
+func TestManagersOnlyReachTheirOwnStaff(t *testing.T) {
+	a := newTestApp(t)
+	other := a.user(models.RoleWaiter, "another-restaurant")
+	own := a.user(models.RoleWaiter, a.restaurant.Restaurant_id)
+	a.as = a.user(models.RoleManager, a.restaurant.Restaurant_id)
+
+	a.expect(a.do(http.MethodDelete, "/users/"+other.User_id+"/sessions", nil), http.StatusNotFound, nil)
+	a.expect(a.do(http.MethodDelete, "/users/"+own.User_id+"/sessions", nil), http.StatusOK, nil)
+
+	a.as = a.owner
+	a.expect(a.do(http.MethodDelete, "/users/"+other.User_id+"/sessions", nil), http.StatusOK, nil)
+}
+
+func TestUserLookupsStayInTheRestaurant(t *testing.T) {
+	a := newTestApp(t)
+	other := a.user(models.RoleWaiter, "another-restaurant")
+	own := a.user(models.RoleWaiter, a.restaurant.Restaurant_id)
+	manager := a.user(models.RoleManager, a.restaurant.Restaurant_id)
+
+	for _, test := range []struct {
+		as     models.User
+		path   string
+		status int
+		users  int
+	}{
+		{manager, "/users/" + own.User_id, http.StatusOK, 0},
+		{manager, "/users/" + other.User_id, http.StatusNotFound, 0},
+		{manager, "/users?restaurant_id=another-restaurant", http.StatusOK, 3},
+		{a.owner, "/users/" + other.User_id, http.StatusOK, 0},
+		{a.owner, "/users?restaurant_id=another-restaurant", http.StatusOK, 1},
+	} {
+		a.as = test.as
+
+		var list struct {
+			Total_count int64 `json:"total_count"`
+		}
+		w := a.do(http.MethodGet, test.path, nil)
+		if w.Code != test.status {
+			t.Errorf("%s as %s got %d, want %d", test.path, test.as.Role, w.Code, test.status)
+			continue
+		}
+
+		if test.users > 0 {
+			a.expect(w, test.status, &list)
+			if list.Total_count != int64(test.users) {
+				t.Errorf("%s as %s listed %d users, want %d", test.path, test.as.Role, list.Total_count, test.users)
+			}
+		}
+	}
+}
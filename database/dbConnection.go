@@ -2,45 +2,29 @@ package database
 
 import (
 	"context"
-	"fmt"
 	"log"
-	"os"
 
-	"github.com/joho/godotenv"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-func DBinstance() *mongo.Client {
-	err := godotenv.Load(".env")
-	if err != nil {
-		log.Fatal("Error loading .env file")
-	}
-	connectionString := os.Getenv("DB_URI")
-
+func DBinstance(c context.Context, connectionString string) (*mongo.Client, error) {
 	clientOptions := options.Client().ApplyURI(connectionString)
 
 	// Connect to MongoDB
-	client, err := mongo.Connect(context.TODO(), clientOptions)
+	client, err := mongo.Connect(c, clientOptions)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 
-	err = client.Ping(context.TODO(), nil)
+	err = client.Ping(c, nil)
 	if err != nil {
-		log.Fatal(err)
+		client.Disconnect(context.Background())
+		return nil, err
 	}
 
-	fmt.Println("Connected to MongoDB")
-
-	return client
-
-}
-
-var Client *mongo.Client = DBinstance()
+	log.Println("Connected to MongoDB")
 
-func OpenCollection(client *mongo.Client, collectionName string) *mongo.Collection {
-	var collection *mongo.Collection = client.Database("restaurant").Collection(collectionName)
+	return client, nil
 
-	return collection
 }
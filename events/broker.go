@@ -0,0 +1,195 @@
+// Package events fans out kitchen and floor updates to the live feeds. The
+// broker lives in process, so every replica only sees the changes it made
+// itself; run a single instance behind the kitchen screens.
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	OrderItemCreated       = "order_item.created"
+	OrderStatusChanged     = "order.status_changed"
+	OrderItemStatusChanged = "order_item.status_changed"
+	// Resync tells a client that events it asked for have already dropped
+	// out of the buffer and it has to reload its state from the REST API.
+	Resync = "resync"
+)
+
+type Event struct {
+	ID            int64       `json:"id"`
+	Type          string      `json:"type"`
+	Restaurant_id string      `json:"restaurant_id"`
+	Order_id      string      `json:"order_id,omitempty"`
+	Station       string      `json:"station,omitempty"`
+	At            time.Time   `json:"at"`
+	Data          interface{} `json:"data,omitempty"`
+}
+
+// Filter picks the events a subscriber gets. Events without a station, such
+// as order status changes, go to every station.
+type Filter struct {
+	Restaurant_id string
+	Stations      []string
+}
+
+func (f Filter) Match(e Event) bool {
+	if e.Restaurant_id != f.Restaurant_id {
+		return false
+	}
+
+	if e.Station == "" || len(f.Stations) == 0 {
+		return true
+	}
+
+	for _, station := range f.Stations {
+		if station == e.Station {
+			return true
+		}
+	}
+
+	return false
+}
+
+type Subscription struct {
+	C      <-chan Event
+	c      chan Event
+	filter Filter
+	broker *Broker
+	once   sync.Once
+}
+
+// Close stops the subscription. It is safe to call more than once.
+func (s *Subscription) Close() {
+	s.broker.unsubscribe(s)
+}
+
+// Broker keeps the last events in a ring buffer so that clients reconnecting
+// with the id of the last event they saw get everything they missed.
+type Broker struct {
+	mu     sync.Mutex
+	nextID int64
+	buffer []Event
+	start  int
+	size   int
+	subs   map[*Subscription]struct{}
+	closed bool
+}
+
+func NewBroker(capacity int) *Broker {
+	// Ids start from the clock so that a client still holding an id from
+	// before a restart is told to resync instead of silently missing events.
+	return &Broker{
+		nextID: time.Now().UnixMilli(),
+		buffer: make([]Event, capacity),
+		subs:   map[*Subscription]struct{}{},
+	}
+}
+
+// Publish stamps the event with the next id and hands it to every matching
+// subscriber. Subscribers that fall too far behind are dropped; their client
+// reconnects and replays from the buffer.
+func (b *Broker) Publish(e Event) Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	e.ID = b.nextID
+	b.nextID++
+
+	if e.At.IsZero() {
+		e.At, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	}
+
+	if len(b.buffer) > 0 {
+		if b.size < len(b.buffer) {
+			b.buffer[(b.start+b.size)%len(b.buffer)] = e
+			b.size++
+		} else {
+			b.buffer[b.start] = e
+			b.start = (b.start + 1) % len(b.buffer)
+		}
+	}
+
+	for sub := range b.subs {
+		if !sub.filter.Match(e) {
+			continue
+		}
+
+		select {
+		case sub.c <- e:
+		default:
+			b.drop(sub)
+		}
+	}
+
+	return e
+}
+
+// Subscribe registers a new subscriber. When lastEventId is not zero the
+// events after it that are still buffered are queued first, or a single
+// Resync event when some of them are already gone.
+func (b *Broker) Subscribe(filter Filter, lastEventId int64) *Subscription {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var backlog []Event
+
+	if lastEventId > 0 && lastEventId != b.nextID-1 {
+		oldest := b.nextID
+		if b.size > 0 {
+			oldest = b.buffer[b.start].ID
+		}
+
+		if lastEventId+1 < oldest || lastEventId >= b.nextID {
+			backlog = append(backlog, Event{Type: Resync, Restaurant_id: filter.Restaurant_id, At: time.Now().UTC().Truncate(time.Second)})
+		} else {
+			for i := 0; i < b.size; i++ {
+				e := b.buffer[(b.start+i)%len(b.buffer)]
+				if e.ID > lastEventId && filter.Match(e) {
+					backlog = append(backlog, e)
+				}
+			}
+		}
+	}
+
+	c := make(chan Event, len(backlog)+64)
+	for _, e := range backlog {
+		c <- e
+	}
+
+	sub := &Subscription{C: c, c: c, filter: filter, broker: b}
+	if b.closed {
+		b.drop(sub)
+		return sub
+	}
+	b.subs[sub] = struct{}{}
+
+	return sub
+}
+
+func (b *Broker) unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.drop(sub)
+}
+
+func (b *Broker) drop(sub *Subscription) {
+	sub.once.Do(func() {
+		delete(b.subs, sub)
+		close(sub.c)
+	})
+}
+
+// Close ends every subscription and refuses new ones, so that long-lived
+// streams let the server shut down.
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for sub := range b.subs {
+		b.drop(sub)
+	}
+}
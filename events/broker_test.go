@@ -0,0 +1,153 @@
+package events
+
+import (
+	"reflect"
+	"testing"
+)
+
+// drain takes what is queued on a subscription without waiting, and tells
+// whether it was closed.
+func drain(sub *Subscription) ([]Event, bool) {
+	var got []Event
+	for {
+		select {
+		case e, ok := <-sub.C:
+			if !ok {
+				return got, true
+			}
+			got = append(got, e)
+		default:
+			return got, false
+		}
+	}
+}
+
+func TestFilter(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		filter Filter
+		event  Event
+		match  bool
+	}{
+		{"other restaurant", Filter{Restaurant_id: "r1"}, Event{Restaurant_id: "r2"}, false},
+		{"every station", Filter{Restaurant_id: "r1"}, Event{Restaurant_id: "r1", Station: "grill"}, true},
+		{"own station", Filter{Restaurant_id: "r1", Stations: []string{"bar", "grill"}}, Event{Restaurant_id: "r1", Station: "grill"}, true},
+		{"other station", Filter{Restaurant_id: "r1", Stations: []string{"bar"}}, Event{Restaurant_id: "r1", Station: "grill"}, false},
+		{"event for every station", Filter{Restaurant_id: "r1", Stations: []string{"bar"}}, Event{Restaurant_id: "r1"}, true},
+	} {
+		if got := test.filter.Match(test.event); got != test.match {
+			t.Errorf("%s: match = %v, want %v", test.name, got, test.match)
+		}
+	}
+}
+
+func TestReplay(t *testing.T) {
+	b := NewBroker(3)
+	defer b.Close()
+
+	stations := []string{"grill", "bar", "grill", "bar", "grill"}
+	ids := make([]int64, len(stations))
+	for i, station := range stations {
+		ids[i] = b.Publish(Event{Type: OrderItemCreated, Restaurant_id: "r1", Station: station}).ID
+	}
+
+	grill := Filter{Restaurant_id: "r1", Stations: []string{"grill"}}
+	everything := Filter{Restaurant_id: "r1"}
+
+	for _, test := range []struct {
+		name   string
+		filter Filter
+		last   int64
+		want   []int64
+		resync bool
+	}{
+		{"new client", everything, 0, nil, false},
+		{"up to date", everything, ids[4], nil, false},
+		{"missed two", everything, ids[2], []int64{ids[3], ids[4]}, false},
+		{"missed all that is buffered", everything, ids[1], []int64{ids[2], ids[3], ids[4]}, false},
+		{"missed more than is buffered", everything, ids[0], nil, true},
+		{"id from the future", everything, ids[4] + 10, nil, true},
+		{"only its station", grill, ids[1], []int64{ids[2], ids[4]}, false},
+	} {
+		sub := b.Subscribe(test.filter, test.last)
+		got, _ := drain(sub)
+		sub.Close()
+
+		if test.resync {
+			if len(got) != 1 || got[0].Type != Resync || got[0].Restaurant_id != "r1" {
+				t.Errorf("%s: got %+v, want a single resync", test.name, got)
+			}
+			continue
+		}
+
+		var gotIds []int64
+		for _, e := range got {
+			gotIds = append(gotIds, e.ID)
+		}
+
+		if !reflect.DeepEqual(gotIds, test.want) {
+			t.Errorf("%s: replayed %v, want %v", test.name, gotIds, test.want)
+		}
+	}
+}
+
+func TestLiveEventsFollowTheReplay(t *testing.T) {
+	b := NewBroker(10)
+	defer b.Close()
+
+	first := b.Publish(Event{Type: OrderItemCreated, Restaurant_id: "r1"})
+	missed := b.Publish(Event{Type: OrderStatusChanged, Restaurant_id: "r1"})
+
+	sub := b.Subscribe(Filter{Restaurant_id: "r1"}, first.ID)
+	defer sub.Close()
+
+	b.Publish(Event{Type: OrderItemCreated, Restaurant_id: "r2"})
+	live := b.Publish(Event{Type: OrderItemStatusChanged, Restaurant_id: "r1"})
+
+	got, closed := drain(sub)
+	if closed || len(got) != 2 || got[0].ID != missed.ID || got[1].ID != live.ID {
+		t.Errorf("got %+v, closed %v; want the missed event, then the live one", got, closed)
+	}
+
+	if live.ID != missed.ID+2 || live.At.IsZero() {
+		t.Errorf("event ids %d and %d are not consecutive or not stamped", missed.ID, live.ID)
+	}
+}
+
+func TestSlowSubscriberIsDropped(t *testing.T) {
+	b := NewBroker(10)
+	defer b.Close()
+
+	slow := b.Subscribe(Filter{Restaurant_id: "r1"}, 0)
+	other := b.Subscribe(Filter{Restaurant_id: "r2"}, 0)
+	defer other.Close()
+
+	for i := 0; i < 100; i++ {
+		b.Publish(Event{Type: OrderItemCreated, Restaurant_id: "r1"})
+	}
+
+	if got, closed := drain(slow); !closed || len(got) == 100 {
+		t.Errorf("slow subscriber got %d events and closed %v, want it dropped", len(got), closed)
+	}
+
+	if _, closed := drain(other); closed {
+		t.Error("a subscriber of another restaurant was dropped")
+	}
+
+	slow.Close()
+}
+
+func TestCloseEndsEverySubscription(t *testing.T) {
+	b := NewBroker(10)
+	sub := b.Subscribe(Filter{Restaurant_id: "r1"}, 0)
+
+	b.Close()
+	if _, closed := drain(sub); !closed {
+		t.Error("subscription is still open after the broker closed")
+	}
+	sub.Close()
+
+	if _, closed := drain(b.Subscribe(Filter{Restaurant_id: "r1"}, 0)); !closed {
+		t.Error("a closed broker took a new subscription")
+	}
+}
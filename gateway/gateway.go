@@ -0,0 +1,125 @@
+// Package gateway is the boundary to card payment providers. The invoice
+// flow only talks to Provider, so a real acquirer can be plugged in next to
+// the mock one used for development.
+package gateway
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/vikas-gouda/go-restraunt-mangement/money"
+)
+
+// Transaction statuses as reported by providers.
+const (
+	Authorized        = "AUTHORIZED"
+	Captured          = "CAPTURED"
+	Voided            = "VOIDED"
+	Refunded          = "REFUNDED"
+	PartiallyRefunded = "PARTIALLY_REFUNDED"
+	Declined          = "DECLINED"
+)
+
+var (
+	// ErrDeclined is returned with a Result that says why.
+	ErrDeclined = errors.New("gateway: declined")
+	// ErrTimeout means the provider did not answer in time. Whether the card
+	// was charged is unknown until the provider reports it.
+	ErrTimeout = errors.New("gateway: timed out")
+	// ErrUnknownTransaction is returned for transaction ids the provider has
+	// no record of.
+	ErrUnknownTransaction = errors.New("gateway: unknown transaction")
+	// ErrInvalidState is returned when the transaction can't take the step,
+	// e.g. refunding more than was captured.
+	ErrInvalidState = errors.New("gateway: transaction can not do that")
+	ErrBadSignature = errors.New("gateway: webhook signature does not match")
+)
+
+// Charge asks for money off a card. Reference is our payment id; providers
+// use it to spot the same charge being sent twice.
+type Charge struct {
+	Amount      money.Money
+	Reference   string
+	Card_token  string
+	Description string
+}
+
+type Result struct {
+	Transaction_id string
+	Status         string
+	Decline_reason string
+}
+
+// WebhookEvent is a status change the provider pushes to us.
+type WebhookEvent struct {
+	Transaction_id string `json:"transaction_id"`
+	Status         string `json:"status"`
+}
+
+type Provider interface {
+	Name() string
+	// Authorize holds the amount on the card without taking it.
+	Authorize(ctx context.Context, charge Charge) (Result, error)
+	// Capture takes up to the authorized amount.
+	Capture(ctx context.Context, transactionId string, amount money.Money) (Result, error)
+	// Void cancels a transaction that has not been settled yet.
+	Void(ctx context.Context, transactionId string) (Result, error)
+	// Refund gives back some or all of a captured amount.
+	Refund(ctx context.Context, transactionId string, amount money.Money) (Result, error)
+	// VerifyWebhook checks that a webhook call comes from the provider and
+	// reads the event out of it.
+	VerifyWebhook(header http.Header, body []byte) (WebhookEvent, error)
+}
+
+// WithTimeout bounds every call to the provider and turns running out of
+// time into ErrTimeout.
+func WithTimeout(provider Provider, timeout time.Duration) Provider {
+	return timeoutProvider{Provider: provider, timeout: timeout}
+}
+
+type timeoutProvider struct {
+	Provider
+	timeout time.Duration
+}
+
+func (p timeoutProvider) Authorize(ctx context.Context, charge Charge) (Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	result, err := p.Provider.Authorize(ctx, charge)
+	return result, timedOut(ctx, err)
+}
+
+func (p timeoutProvider) Capture(ctx context.Context, transactionId string, amount money.Money) (Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	result, err := p.Provider.Capture(ctx, transactionId, amount)
+	return result, timedOut(ctx, err)
+}
+
+func (p timeoutProvider) Void(ctx context.Context, transactionId string) (Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	result, err := p.Provider.Void(ctx, transactionId)
+	return result, timedOut(ctx, err)
+}
+
+func (p timeoutProvider) Refund(ctx context.Context, transactionId string, amount money.Money) (Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	result, err := p.Provider.Refund(ctx, transactionId, amount)
+	return result, timedOut(ctx, err)
+}
+
+func timedOut(ctx context.Context, err error) error {
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return ErrTimeout
+	}
+
+	return err
+}
@@ -0,0 +1,171 @@
+// Package mock is a payment provider that runs in process, for development
+// and tests. The card token picks the outcome:
+//
+//	tok_decline             declined, card_declined
+//	tok_insufficient_funds  declined, insufficient_funds
+//	tok_timeout             never answers
+//
+// Any other token is approved. Webhooks are signed with HMAC-SHA256 of the
+// body, hex encoded in the Mock-Signature header.
+package mock
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/vikas-gouda/go-restraunt-mangement/gateway"
+	"github.com/vikas-gouda/go-restraunt-mangement/money"
+)
+
+const SignatureHeader = "Mock-Signature"
+
+type transaction struct {
+	authorized money.Money
+	captured   money.Money
+	refunded   money.Money
+	status     string
+}
+
+type Provider struct {
+	secret []byte
+
+	mu           sync.Mutex
+	transactions map[string]*transaction
+	references   map[string]string
+	next         int64
+}
+
+func New(secret string) *Provider {
+	return &Provider{
+		secret:       []byte(secret),
+		transactions: map[string]*transaction{},
+		references:   map[string]string{},
+		next:         time.Now().UnixMilli(),
+	}
+}
+
+func (p *Provider) Name() string {
+	return "mock"
+}
+
+func (p *Provider) Authorize(ctx context.Context, charge gateway.Charge) (gateway.Result, error) {
+	switch charge.Card_token {
+	case "tok_decline":
+		return gateway.Result{Status: gateway.Declined, Decline_reason: "card_declined"}, gateway.ErrDeclined
+	case "tok_insufficient_funds":
+		return gateway.Result{Status: gateway.Declined, Decline_reason: "insufficient_funds"}, gateway.ErrDeclined
+	case "tok_timeout":
+		<-ctx.Done()
+		return gateway.Result{}, ctx.Err()
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// The same reference twice is the same charge.
+	if id, ok := p.references[charge.Reference]; ok && charge.Reference != "" {
+		return gateway.Result{Transaction_id: id, Status: p.transactions[id].status}, nil
+	}
+
+	p.next++
+	id := "mock_" + strconv.FormatInt(p.next, 36)
+	p.transactions[id] = &transaction{
+		authorized: charge.Amount,
+		captured:   money.Zero(charge.Amount.Currency),
+		refunded:   money.Zero(charge.Amount.Currency),
+		status:     gateway.Authorized,
+	}
+	p.references[charge.Reference] = id
+
+	return gateway.Result{Transaction_id: id, Status: gateway.Authorized}, nil
+}
+
+func (p *Provider) Capture(ctx context.Context, transactionId string, amount money.Money) (gateway.Result, error) {
+	return p.step(transactionId, func(t *transaction) error {
+		if t.status != gateway.Authorized || amount.Cmp(t.authorized) > 0 {
+			return gateway.ErrInvalidState
+		}
+
+		t.captured = amount
+		t.status = gateway.Captured
+		return nil
+	})
+}
+
+func (p *Provider) Void(ctx context.Context, transactionId string) (gateway.Result, error) {
+	return p.step(transactionId, func(t *transaction) error {
+		if t.status != gateway.Authorized && t.status != gateway.Captured {
+			return gateway.ErrInvalidState
+		}
+
+		t.status = gateway.Voided
+		return nil
+	})
+}
+
+func (p *Provider) Refund(ctx context.Context, transactionId string, amount money.Money) (gateway.Result, error) {
+	return p.step(transactionId, func(t *transaction) error {
+		if t.status != gateway.Captured && t.status != gateway.PartiallyRefunded {
+			return gateway.ErrInvalidState
+		}
+
+		refunded := t.refunded.Add(amount)
+		if !amount.IsNegative() && refunded.Cmp(t.captured) > 0 {
+			return gateway.ErrInvalidState
+		}
+
+		t.refunded = refunded
+		t.status = gateway.PartiallyRefunded
+		if refunded.Cmp(t.captured) == 0 {
+			t.status = gateway.Refunded
+		}
+		return nil
+	})
+}
+
+func (p *Provider) step(transactionId string, fn func(*transaction) error) (gateway.Result, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	t, ok := p.transactions[transactionId]
+	if !ok {
+		return gateway.Result{}, gateway.ErrUnknownTransaction
+	}
+
+	if err := fn(t); err != nil {
+		return gateway.Result{Transaction_id: transactionId, Status: t.status}, err
+	}
+
+	return gateway.Result{Transaction_id: transactionId, Status: t.status}, nil
+}
+
+func (p *Provider) VerifyWebhook(header http.Header, body []byte) (gateway.WebhookEvent, error) {
+	var event gateway.WebhookEvent
+
+	signature, err := hex.DecodeString(header.Get(SignatureHeader))
+	if err != nil || !hmac.Equal(signature, p.sign(body)) {
+		return event, gateway.ErrBadSignature
+	}
+
+	err = json.Unmarshal(body, &event)
+	return event, err
+}
+
+// Sign returns the signature header value for a webhook body, for sending
+// test webhooks.
+func (p *Provider) Sign(body []byte) string {
+	return hex.EncodeToString(p.sign(body))
+}
+
+func (p *Provider) sign(body []byte) []byte {
+	mac := hmac.New(sha256.New, p.secret)
+	mac.Write(body)
+	return mac.Sum(nil)
+}
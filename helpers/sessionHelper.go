@@ -0,0 +1,37 @@
+package helpers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/vikas-gouda/go-restraunt-mangement/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func NewSessionId() string {
+	return primitive.NewObjectID().Hex()
+}
+
+// NewSession describes the device a user just signed in from. Only a hash of
+// the refresh token is kept so a leaked session document can't be replayed.
+func NewSession(sessionId string, userId string, refreshToken string, userAgent string, ip string) models.Session {
+	var session models.Session
+
+	session.ID = primitive.NewObjectID()
+	session.Session_id = sessionId
+	session.User_id = userId
+	session.User_agent = userAgent
+	session.Ip_address = ip
+	session.Refresh_token_hash = HashToken(refreshToken)
+	session.Created_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	session.Last_seen_at = session.Created_at
+	session.Expires_at = session.Created_at.Add(RefreshTokenLifetime)
+
+	return session
+}
+
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -1,52 +1,84 @@
 package helpers
 
 import (
-	"context"
 	"fmt"
 	"log"
 	"os"
 	"time"
 
 	"github.com/dgrijalva/jwt-go"
-	"github.com/vikas-gouda/go-restraunt-mangement/database"
-	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type SignedDetails struct {
-	Email      string
-	First_name string
-	Last_name  string
-	Uid        string
+	Email         string
+	First_name    string
+	Last_name     string
+	Uid           string
+	Role          string
+	Restaurant_id string
+	Token_type    string
+	// Nonce makes every refresh token unique, so one rotated within the
+	// same second still differs from the one it replaces.
+	Nonce string `json:",omitempty"`
 	jwt.StandardClaims
 }
 
-var userCollection *mongo.Collection = database.OpenCollection(database.Client, "user")
+const (
+	AccessToken  = "access"
+	RefreshToken = "refresh"
+	// StreamToken is only taken by the live kitchen feeds, whose browser
+	// clients can't send headers and put it in the URL or a cookie instead.
+	StreamToken = "stream"
+)
+
+// StreamCookie is the cookie the stream token is set in.
+const StreamCookie = "kitchen_token"
 
 var SECRET_KEY = os.Getenv("SECRET_KEY")
 
-func GenerateAllTokens(email string, firstName string, lastName string, userId string) (signedToken string, signedRefreshToken string, err error) {
+var RefreshTokenLifetime = time.Hour * time.Duration(24*7)
+
+// StreamTokenLifetime is short as the token ends up in URLs and logs; it is
+// only checked when a feed connects.
+var StreamTokenLifetime = 5 * time.Minute
+
+// GenerateAllTokens signs both tokens with the session id as their jti, which
+// is what the middleware checks against the session store.
+func GenerateAllTokens(email string, firstName string, lastName string, userId string, role string, restaurantId string, sessionId string) (signedToken string, signedRefreshToken string, err error) {
 	claims := SignedDetails{
-		Email:      email,
-		First_name: firstName,
-		Last_name:  lastName,
-		Uid:        userId,
+		Email:         email,
+		First_name:    firstName,
+		Last_name:     lastName,
+		Uid:           userId,
+		Role:          role,
+		Restaurant_id: restaurantId,
+		Token_type:    AccessToken,
 		StandardClaims: jwt.StandardClaims{
+			Id:        sessionId,
+			IssuedAt:  time.Now().Local().Unix(),
 			ExpiresAt: time.Now().Local().Add(time.Hour * time.Duration(24)).Unix(),
 		},
 	}
 
+	// The refresh token only identifies the user, everything else is reloaded on exchange.
 	refreshClaims := SignedDetails{
+		Uid:        userId,
+		Token_type: RefreshToken,
+		Nonce:      NewSessionId(),
 		StandardClaims: jwt.StandardClaims{
-			ExpiresAt: time.Now().Local().Add(time.Hour * time.Duration(24)).Unix(),
+			Id:        sessionId,
+			IssuedAt:  time.Now().Local().Unix(),
+			ExpiresAt: time.Now().Local().Add(RefreshTokenLifetime).Unix(),
 		},
 	}
 
 	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(SECRET_KEY))
-	refreshToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, refreshClaims).SignedString([]byte(SECRET_KEY))
+	if err != nil {
+		log.Panic(err)
+		return
+	}
 
+	refreshToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, refreshClaims).SignedString([]byte(SECRET_KEY))
 	if err != nil {
 		log.Panic(err)
 		return
@@ -56,44 +88,30 @@ func GenerateAllTokens(email string, firstName string, lastName string, userId s
 
 }
 
-func UpdateAllTokens(signedToken string, signedRefreshToken string, userId string) {
-	var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
-
-	var updateObj primitive.D
+// GenerateStreamToken signs a stream token for the session of an access token.
+func GenerateStreamToken(userId string, role string, restaurantId string, sessionId string) (signedToken string, expiresAt time.Time, err error) {
+	expiresAt = time.Now().Local().Add(StreamTokenLifetime)
 
-	updateObj = append(updateObj, bson.E{"token", signedToken})
-	updateObj = append(updateObj, bson.E{"refresh_token", signedRefreshToken})
-
-	Updated_at, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
-	updateObj = append(updateObj, bson.E{"updated_at", Updated_at})
-
-	upsert := true
-	filter := bson.M{"user_id": userId}
-	opt := options.UpdateOptions{
-		Upsert: &upsert,
-	}
-
-	_, err := userCollection.UpdateOne(
-		c, filter, bson.D{
-			{"$set", updateObj},
+	claims := SignedDetails{
+		Uid:           userId,
+		Role:          role,
+		Restaurant_id: restaurantId,
+		Token_type:    StreamToken,
+		StandardClaims: jwt.StandardClaims{
+			Id:        sessionId,
+			IssuedAt:  time.Now().Local().Unix(),
+			ExpiresAt: expiresAt.Unix(),
 		},
-		&opt,
-	)
-
-	defer cancel()
-
-	if err != nil {
-		log.Panic(err)
-		return
 	}
 
-	return
+	signedToken, err = jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(SECRET_KEY))
+	return signedToken, expiresAt, err
 }
 
 func ValidateToken(signedToken string) (claims *SignedDetails, msg string) {
 	token, err := jwt.ParseWithClaims(
 		signedToken,
-		SignedDetails{},
+		&SignedDetails{},
 		func(t *jwt.Token) (interface{}, error) {
 			return []byte(SECRET_KEY), nil
 		},
@@ -106,14 +124,12 @@ func ValidateToken(signedToken string) (claims *SignedDetails, msg string) {
 	claims, ok := token.Claims.(*SignedDetails)
 	if !ok {
 		msg = fmt.Sprint("the token is invalid")
-		msg = err.Error()
 		return
 	}
 
 	// Check if the token is expired.
 	if claims.ExpiresAt < time.Now().Local().Unix() {
 		msg = fmt.Sprintf("token is expired")
-		msg = err.Error()
 		return
 	}
 
@@ -1,36 +1,27 @@
 package main
 
 import (
+	"log"
 	"os"
 
-	"github.com/gin-gonic/gin"
-	"github.com/vikas-gouda/go-restraunt-mangement/database"
-	"github.com/vikas-gouda/go-restraunt-mangement/middleware"
-	"github.com/vikas-gouda/go-restraunt-mangement/routes"
-	"go.mongodb.org/mongo-driver/mongo"
+	"github.com/vikas-gouda/go-restraunt-mangement/app"
+	"github.com/vikas-gouda/go-restraunt-mangement/config"
 )
 
-var foodCollection *mongo.Collection = database.OpenCollection(database.Client, "food")
-
 func main() {
 
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8000"
+	cfg, err := config.Load(os.Args[1:])
+	if err != nil {
+		log.Fatal(err)
 	}
 
-	router := gin.New()
-	router.Use(gin.Logger())
-	routes.UserRoutes(router)
-	router.Use(middleware.Authentication())
-
-	routes.FoodRoutes(router)
-	routes.MenuRoutes(router)
-	routes.TableRoutes(router)
-	routes.OrderRoutes(router)
-	routes.OrderItemRoutes(router)
-	routes.InvoiceRoutes(router)
+	a, err := app.New(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	router.Run(":" + port)
+	if err := a.Run(); err != nil {
+		log.Fatal(err)
+	}
 
 }
@@ -5,9 +5,11 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/vikas-gouda/go-restraunt-mangement/helpers"
+	"github.com/vikas-gouda/go-restraunt-mangement/models"
+	"github.com/vikas-gouda/go-restraunt-mangement/store"
 )
 
-func Authentication() gin.HandlerFunc {
+func Authentication(sessions store.SessionStore) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		// Retrieve JWT token from the Authorization header.
 		clientToken := ctx.Request.Header.Get("token")
@@ -17,20 +19,103 @@ func Authentication() gin.HandlerFunc {
 			return
 		}
 
-		// Validate the JWT token.
-		claims, err := helpers.ValidateToken(clientToken)
-		if err != "" {
-			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err})
+		authenticate(ctx, sessions, clientToken, helpers.AccessToken)
+	}
+}
+
+// StreamAuthentication guards the live kitchen feeds. EventSource and
+// WebSocket clients in a browser can't set the token header, so they pass a
+// stream token as ?token= or in the kitchen_token cookie instead.
+func StreamAuthentication(sessions store.SessionStore) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if clientToken := ctx.Request.Header.Get("token"); clientToken != "" {
+			authenticate(ctx, sessions, clientToken, helpers.AccessToken)
+			return
+		}
+
+		clientToken := ctx.Query("token")
+		if clientToken == "" {
+			clientToken, _ = ctx.Cookie(helpers.StreamCookie)
+		}
+
+		if clientToken == "" {
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "a token header, ?token= or the " + helpers.StreamCookie + " cookie is required"})
 			ctx.Abort()
 			return
 		}
 
-		// Set user claims as context values for further processing.
-		ctx.Set("email", claims.Email)
-		ctx.Set("first_name", claims.First_name)
-		ctx.Set("last_name", claims.Last_name)
-		ctx.Set("uid", claims.Uid)
-		// Proceed to the next middleware or handler.
-		ctx.Next()
+		authenticate(ctx, sessions, clientToken, helpers.StreamToken)
+	}
+}
+
+// authenticate checks a token of tokenType and its session, and sets the
+// claims on the context for the handlers.
+func authenticate(ctx *gin.Context, sessions store.SessionStore, clientToken string, tokenType string) {
+	// Validate the JWT token.
+	claims, err := helpers.ValidateToken(clientToken)
+	if err != "" {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err})
+		ctx.Abort()
+		return
+	}
+
+	if claims.Token_type != tokenType {
+		msg := "refresh tokens can only be used on /users/refresh"
+		switch {
+		case tokenType == helpers.StreamToken:
+			msg = "only stream tokens from /kitchen/token can be passed this way"
+		case claims.Token_type == helpers.StreamToken:
+			msg = "stream tokens can only be used on the kitchen feeds"
+		}
+
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": msg})
+		ctx.Abort()
+		return
+	}
+
+	active, sessionErr := sessions.Active(ctx.Request.Context(), claims.Id)
+	if sessionErr != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while checking the session"})
+		ctx.Abort()
+		return
+	}
+
+	if !active {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "the session has been revoked or has expired"})
+		ctx.Abort()
+		return
+	}
+
+	// Set user claims as context values for further processing.
+	ctx.Set("email", claims.Email)
+	ctx.Set("first_name", claims.First_name)
+	ctx.Set("last_name", claims.Last_name)
+	ctx.Set("uid", claims.Uid)
+	ctx.Set("role", claims.Role)
+	ctx.Set("restaurant_id", claims.Restaurant_id)
+	ctx.Set("session_id", claims.Id)
+	// Proceed to the next middleware or handler.
+	ctx.Next()
+}
+
+func Authorization(roles ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		role := ctx.GetString("role")
+
+		// Owners are allowed everywhere, everybody else has to be listed.
+		if role == models.RoleOwner {
+			ctx.Next()
+			return
+		}
+
+		for _, allowed := range roles {
+			if role == allowed {
+				ctx.Next()
+				return
+			}
+		}
+
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "You are not allowed to perform this action"})
+		ctx.Abort()
 	}
 }
@@ -0,0 +1,127 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vikas-gouda/go-restraunt-mangement/models"
+	"github.com/vikas-gouda/go-restraunt-mangement/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const maxIdempotencyKeyLength = 255
+
+// responseRecorder keeps a copy of everything the handler writes.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Idempotency makes POST requests carrying an Idempotency-Key header safe to
+// retry: the first response is stored for ttl and replayed for every retry
+// with the same key, while reusing the key for a different request is
+// refused. It has to run after Authentication, keys are per restaurant.
+func Idempotency(records store.IdempotencyStore, ttl time.Duration) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		key := ctx.GetHeader("Idempotency-Key")
+		if key == "" || ctx.Request.Method != http.MethodPost {
+			ctx.Next()
+			return
+		}
+
+		if len(key) > maxIdempotencyKeyLength {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Idempotency-Key must be at most 255 characters"})
+			ctx.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(ctx.Request.Body)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Error while reading the request body"})
+			ctx.Abort()
+			return
+		}
+		ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		hash := sha256.New()
+		hash.Write([]byte(ctx.Request.Method + " " + ctx.Request.URL.Path + "\n"))
+		hash.Write(body)
+
+		var record models.IdempotencyRecord
+
+		record.ID = primitive.NewObjectID()
+		record.Key = key
+		record.Restaurant_id = ctx.GetString("restaurant_id")
+		record.User_id = ctx.GetString("uid")
+		record.Method = ctx.Request.Method
+		record.Path = ctx.Request.URL.Path
+		record.Request_hash = hex.EncodeToString(hash.Sum(nil))
+		record.Created_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		record.Expires_at = record.Created_at.Add(ttl)
+
+		var c, cancel = context.WithTimeout(context.Background(), 100*time.Second)
+		defer cancel()
+
+		existing, reserved, err := records.Reserve(c, record)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error while checking the Idempotency-Key"})
+			ctx.Abort()
+			return
+		}
+
+		if !reserved {
+			replay(ctx, record, existing)
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: ctx.Writer}
+		ctx.Writer = recorder
+
+		ctx.Next()
+
+		// Server errors are not remembered so the client can try again.
+		if recorder.Status() >= http.StatusInternalServerError {
+			records.Release(c, record.Restaurant_id, record.Key)
+			return
+		}
+
+		record.Status_code = recorder.Status()
+		record.Content_type = recorder.Header().Get("Content-Type")
+		record.Body = recorder.body.Bytes()
+
+		records.Complete(c, record)
+	}
+}
+
+func replay(ctx *gin.Context, record models.IdempotencyRecord, existing models.IdempotencyRecord) {
+	defer ctx.Abort()
+
+	if existing.Request_hash != record.Request_hash {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Idempotency-Key was already used for a different request"})
+		return
+	}
+
+	if existing.Status_code == 0 {
+		ctx.JSON(http.StatusConflict, gin.H{"error": "a request with this Idempotency-Key is still being processed"})
+		return
+	}
+
+	ctx.Header("Idempotent-Replayed", "true")
+	ctx.Data(existing.Status_code, existing.Content_type, existing.Body)
+}
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vikas-gouda/go-restraunt-mangement/store/memstore"
+)
+
+func TestIdempotency(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	for _, test := range []struct {
+		name   string
+		ttl    time.Duration
+		key    string
+		body   string
+		status int
+		runs   int
+		replay bool
+	}{
+		{"retry is replayed", time.Hour, "k1", `{"n":1}`, http.StatusCreated, 1, true},
+		{"expired key runs again", 0, "k1", `{"n":1}`, http.StatusCreated, 2, false},
+		{"key reused for another body", time.Hour, "k1", `{"n":2}`, http.StatusUnprocessableEntity, 1, false},
+		{"no key runs every time", time.Hour, "", `{"n":1}`, http.StatusCreated, 2, false},
+	} {
+		runs := 0
+		r := gin.New()
+		r.Use(func(ctx *gin.Context) { ctx.Set("restaurant_id", "r1") })
+		r.Use(Idempotency(memstore.New().Idempotency, test.ttl))
+		r.POST("/orders", func(ctx *gin.Context) {
+			runs++
+			ctx.JSON(http.StatusCreated, gin.H{"run": runs})
+		})
+
+		send := func(body string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(body))
+			if test.key != "" {
+				req.Header.Set("Idempotency-Key", test.key)
+			}
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			return w
+		}
+
+		first := send(`{"n":1}`)
+		second := send(test.body)
+
+		if second.Code != test.status || runs != test.runs {
+			t.Errorf("%s: retry got %d after %d runs, want %d after %d: %s", test.name, second.Code, runs, test.status, test.runs, second.Body.String())
+		}
+
+		if replayed := second.Header().Get("Idempotent-Replayed") == "true"; replayed != test.replay {
+			t.Errorf("%s: replayed = %v, want %v", test.name, replayed, test.replay)
+		}
+
+		if test.replay && second.Body.String() != first.Body.String() {
+			t.Errorf("%s: replayed %s, want %s", test.name, second.Body.String(), first.Body.String())
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"time"
+
+	"github.com/vikas-gouda/go-restraunt-mangement/money"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// A credit note records money coming off a bill after the fact: a REFUND of
+// a payment or the ITEM_VOID of something that was ordered.
+const (
+	CreditRefund   = "REFUND"
+	CreditItemVoid = "ITEM_VOID"
+)
+
+type CreditNote struct {
+	ID     primitive.ObjectID `bson:"_id"`
+	Kind   string             `json:"kind"`
+	Reason string             `json:"reason"`
+	Note   string             `json:"note"`
+	// Amount is what came off the bill: the refunded part of a payment, or
+	// the line total of a voided item.
+	Amount money.Money `json:"amount"`
+	// Tip is the refunded part of the tip, which was never on the bill.
+	Tip money.Money `json:"tip"`
+	// Credited is the part of Amount taken off what the invoice is due; the
+	// rest gave back an overpayment.
+	Credited        money.Money `json:"credited"`
+	Tender          string      `json:"tender,omitempty"`
+	Approved_by     string      `json:"approved_by"`
+	Created_by      string      `json:"created_by"`
+	Role            string      `json:"role"`
+	Provider        string      `json:"provider,omitempty"`
+	Transaction_id  string      `json:"transaction_id,omitempty"`
+	Provider_status string      `json:"provider_status,omitempty"`
+	Created_at      time.Time   `json:"created_at"`
+	Credit_note_id  string      `json:"credit_note_id"`
+	Invoice_id      string      `json:"invoice_id,omitempty"`
+	Order_id        string      `json:"order_id"`
+	Payment_id      string      `json:"payment_id,omitempty"`
+	Order_item_id   string      `json:"order_item_id,omitempty"`
+	Restaurant_id   string      `json:"restaurant_id"`
+}
+
+// RefundRequest gives back some or all of a payment. Without amount and tip
+// everything that is left of the payment is refunded. Manager_id is the
+// manager who approved it, by typing in Manager_password at the till.
+type RefundRequest struct {
+	Amount           *money.Money `json:"amount"`
+	Tip              *money.Money `json:"tip"`
+	Reason           string       `json:"reason" validate:"required,eq=CUSTOMER_COMPLAINT|eq=WRONG_ITEM|eq=QUALITY|eq=DUPLICATE_CHARGE|eq=ENTERED_IN_ERROR|eq=SERVICE_RECOVERY|eq=OTHER"`
+	Note             string       `json:"note" validate:"max=500"`
+	Manager_id       string       `json:"manager_id" validate:"required"`
+	Manager_password string       `json:"manager_password" validate:"required"`
+}
+
+type VoidRequest struct {
+	Reason           string `json:"reason" validate:"required,eq=CUSTOMER_COMPLAINT|eq=WRONG_ITEM|eq=QUALITY|eq=ENTERED_IN_ERROR|eq=SERVICE_RECOVERY|eq=OTHER"`
+	Note             string `json:"note" validate:"max=500"`
+	Manager_id       string `json:"manager_id" validate:"required"`
+	Manager_password string `json:"manager_password" validate:"required"`
+}
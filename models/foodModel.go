@@ -3,16 +3,21 @@ package models
 import (
 	"time"
 
+	"github.com/vikas-gouda/go-restraunt-mangement/money"
+
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type Food struct {
-	ID         primitive.ObjectID `bson:"_id" json`
-	Name       string             `json:"name" validate:"required,min=2,max100"`
-	Price      float64            `json:"price" validate:"require"`
-	Food_image string             `json:"food_image" validate:"required"`
-	Created_at time.Time          `json:"created_at"`
-	Updated_at time.Time          `json:"updated_at"`
-	Food_id    string             `json:"food_id" validate:"required"`
-	Menu_id    *string            `json:"menu_id" validate:"required"`
+	ID              primitive.ObjectID `bson:"_id"`
+	Name            string             `json:"name" validate:"required,min=2,max=100"`
+	Price           money.Money        `json:"price"`
+	Food_image      string             `json:"food_image" validate:"required"`
+	Modifier_groups []ModifierGroup    `json:"modifier_groups" validate:"dive"`
+	Tax_category    string             `json:"tax_category" validate:"omitempty,max=30"`
+	Created_at      time.Time          `json:"created_at"`
+	Updated_at      time.Time          `json:"updated_at"`
+	Food_id         string             `json:"food_id"`
+	Menu_id         *string            `json:"menu_id" validate:"required"`
+	Restaurant_id   string             `json:"restaurant_id"`
 }
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// IdempotencyRecord remembers the response to a create request so that a
+// retry with the same Idempotency-Key gets the same answer.
+type IdempotencyRecord struct {
+	ID            primitive.ObjectID `bson:"_id"`
+	Key           string             `json:"key"`
+	Restaurant_id string             `json:"restaurant_id"`
+	User_id       string             `json:"user_id"`
+	Method        string             `json:"method"`
+	Path          string             `json:"path"`
+	Request_hash  string             `json:"request_hash"`
+	// Status_code stays 0 while the first request is still running.
+	Status_code  int       `json:"status_code"`
+	Content_type string    `json:"content_type"`
+	Body         []byte    `json:"body"`
+	Created_at   time.Time `json:"created_at"`
+	Expires_at   time.Time `json:"expires_at"`
+}
@@ -1,18 +1,173 @@
 package models
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/vikas-gouda/go-restraunt-mangement/money"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// The payment status of an invoice follows from what was paid against what
+// is due; tips don't count.
+const (
+	InvoicePending       = "PENDING"
+	InvoicePartiallyPaid = "PARTIALLY_PAID"
+	InvoicePaid          = "PAID"
+	InvoiceOverpaid      = "OVERPAID"
+	// InvoiceRefunded means credit notes took off everything that was due.
+	InvoiceRefunded = "REFUNDED"
+)
+
+// MixedTender is the payment method of invoices paid with several tenders.
+const MixedTender = "MIXED"
+
+// An order is billed either by one FULL invoice, by ITEMS or SEAT invoices
+// that each take some of its items, or by EVEN invoices that each take some
+// of N equal shares of the total. Items and shares are never on two invoices
+// that are not voided.
+const (
+	SplitFull  = "FULL"
+	SplitItems = "ITEMS"
+	SplitSeat  = "SEAT"
+	SplitEven  = "EVEN"
+)
+
 type Invoice struct {
-	ID               primitive.ObjectID `bson:"_id"`
-	Invoice_id       string             `json:"invoice_id" validate:"requied"`
-	Order_id         *string            `json:"order_id"`
-	Payment_method   string             `json:"payment_method" validate:"eq=CARD|eq=CASH|eq="`
-	Payment_status   string             `json:"payment_status" validate:"required,eq=PENDING|eq=PAID"`
-	Payment_due_date time.Time          `json:"payment_due_date"`
-	Created_at       time.Time          `json:"created_at"`
-	Updated_at       time.Time          `json:"updated_at"`
+	ID         primitive.ObjectID `bson:"_id"`
+	Invoice_id string             `json:"invoice_id"`
+	// Invoice_number is the number the invoice is known by outside, taken
+	// from the counter of the restaurant's Fiscal_year as it is stored.
+	// Invoices from before numbering have none.
+	Invoice_number string  `json:"invoice_number,omitempty" bson:"invoice_number,omitempty"`
+	Fiscal_year    string  `json:"fiscal_year,omitempty" bson:"fiscal_year,omitempty"`
+	Sequence       int64   `json:"sequence,omitempty" bson:"sequence,omitempty"`
+	Order_id       *string `json:"order_id"`
+	Payment_method string  `json:"payment_method" validate:"eq=CARD|eq=CASH|eq=VOUCHER|eq=WALLET|eq=MIXED|eq="`
+	Payment_status string  `json:"payment_status" validate:"required,eq=PENDING|eq=PARTIALLY_PAID|eq=PAID|eq=OVERPAID|eq=REFUNDED"`
+	// Paid adds up the payments taken, without tips. Payment_status and
+	// Payment_method are worked out from the payments whenever one is taken.
+	Paid money.Money `json:"paid"`
+	// Credited adds up what refunds took off what is due.
+	Credited         money.Money `json:"credited"`
+	Payment_due_date time.Time   `json:"payment_due_date"`
+	Split_mode       string      `json:"split_mode"`
+	Order_item_ids   []string    `json:"order_item_ids,omitempty" bson:"order_item_ids,omitempty"`
+	Seat             int         `json:"seat,omitempty" bson:"seat,omitempty"`
+	Parts            int         `json:"parts,omitempty" bson:"parts,omitempty"`
+	Shares           []int       `json:"shares,omitempty" bson:"shares,omitempty"`
+	// Voided_at is set when the invoice was replaced by a split or a merge,
+	// or cancelled. Numbered invoices are never deleted, so the numbers
+	// have no gaps.
+	Voided_at   *time.Time `json:"voided_at,omitempty" bson:"voided_at,omitempty"`
+	Void_reason string     `json:"void_reason,omitempty" bson:"void_reason,omitempty"`
+	Voided_by   string     `json:"voided_by,omitempty" bson:"voided_by,omitempty"`
+	// Bill is what the invoice was made out for. Invoices from before it
+	// existed have none and are billed from the order as it is now.
+	Bill          *InvoiceBill `json:"bill,omitempty" bson:"bill,omitempty"`
+	Created_at    time.Time    `json:"created_at"`
+	Updated_at    time.Time    `json:"updated_at"`
+	Restaurant_id string       `json:"restaurant_id"`
+}
+
+// InvoiceBill keeps the order as it was billed, with the tax category of
+// every line, the discounts, tax rates, money settings and service charge of
+// the time, and what the invoice came to, so later changes to the order, the
+// foods, the rates or the restaurant don't reprice it. Only items voided since
+// are taken off.
+type InvoiceBill struct {
+	Currency              string          `json:"currency"`
+	Rounding              string          `json:"rounding"`
+	Table_number          int             `json:"table_number"`
+	Order_lines           []OrderLine     `json:"order_lines"`
+	Order_discounts       []OrderDiscount `json:"order_discounts"`
+	Tax_rates             []TaxRate       `json:"tax_rates"`
+	Gratuity_basis_points int64           `json:"gratuity_basis_points"`
+	Items_total           money.Money     `json:"items_total"`
+	Discount              money.Money     `json:"discount"`
+	Subtotal              money.Money     `json:"subtotal"`
+	Taxes                 []TaxLine       `json:"taxes"`
+	Tax                   money.Money     `json:"tax"`
+	Gratuity              money.Money     `json:"gratuity"`
+	Total                 money.Money     `json:"total"`
+	// Due is what this invoice asks for: the total, or its shares of it.
+	Due money.Money `json:"due"`
+}
+
+// Mode treats invoices from before splits existed as covering everything.
+func (invoice Invoice) Mode() string {
+	if invoice.Split_mode == "" {
+		return SplitFull
+	}
+
+	return invoice.Split_mode
+}
+
+func (invoice Invoice) Voided() bool {
+	return invoice.Voided_at != nil
+}
+
+// Settled reports whether money was taken or credited on the invoice. It can
+// no longer be voided by a split or merge then.
+func (invoice Invoice) Settled() bool {
+	return invoice.Payment_status == InvoicePaid || !invoice.Paid.IsZero() || !invoice.Credited.IsZero()
+}
+
+// PaymentStatus compares what was paid with what is due after credits.
+func PaymentStatus(paid money.Money, due money.Money, credited money.Money) string {
+	if credited.Amount > 0 && credited.Cmp(due) >= 0 {
+		return InvoiceRefunded
+	}
+
+	switch compared := paid.Cmp(due.Sub(credited)); {
+	case compared > 0:
+		return InvoiceOverpaid
+	case compared == 0:
+		return InvoicePaid
+	case paid.IsZero():
+		return InvoicePending
+	default:
+		return InvoicePartiallyPaid
+	}
+}
+
+// SplitRequest is the body for splitting an order into several invoices.
+// ITEMS takes the item ids of every check, SEAT makes a check per seat and
+// EVEN makes Parts equal checks.
+type SplitRequest struct {
+	Mode   string     `json:"mode" validate:"required,eq=ITEMS|eq=SEAT|eq=EVEN"`
+	Checks [][]string `json:"checks"`
+	Parts  int        `json:"parts" validate:"omitempty,gte=2,lte=50"`
+}
+
+// InvoiceVoidRequest is the body for cancelling an invoice.
+type InvoiceVoidRequest struct {
+	Reason string `json:"reason" validate:"required,max=200"`
+}
+
+// InvoiceNumbering lays out the numbers of the invoices stored together.
+// Sequence numbers are counted per Series, which is the fiscal year unless
+// the format leaves the year out.
+type InvoiceNumbering struct {
+	Format      string
+	Digits      int
+	Code        string
+	Fiscal_year int
+	Series      string
+}
+
+// Number lays out the sequence number seq.
+func (numbering InvoiceNumbering) Number(seq int64) string {
+	return strings.NewReplacer(
+		"{code}", numbering.Code,
+		"{fy}", strconv.Itoa(numbering.Fiscal_year),
+		"{fy_end}", strconv.Itoa(numbering.Fiscal_year+1),
+		"{seq}", fmt.Sprintf("%0*d", numbering.Digits, seq),
+	).Replace(numbering.Format)
+}
+
+type MergeRequest struct {
+	Invoice_ids []string `json:"invoice_ids" validate:"required,min=2"`
 }
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// KitchenTicket is what the kitchen screens show for one order item.
+type KitchenTicket struct {
+	Order_item_id string           `json:"order_item_id"`
+	Order_id      string           `json:"order_id"`
+	Table_number  int              `json:"table_number"`
+	Food_id       string           `json:"food_id"`
+	Food_name     string           `json:"food_name"`
+	Quantity      int              `json:"quantity"`
+	Size          string           `json:"size"`
+	Modifiers     []ChosenModifier `json:"modifiers"`
+	Station       string           `json:"station"`
+	Category      string           `json:"category"`
+	Status        string           `json:"status"`
+	Created_at    time.Time        `json:"created_at"`
+}
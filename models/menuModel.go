@@ -1,18 +1,61 @@
 package models
 
 import (
+	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+const (
+	StationKitchen = "kitchen"
+	StationGrill   = "grill"
+	StationBar     = "bar"
+	StationPastry  = "pastry"
+)
+
+// categoryStations routes the usual menu categories to a station. Anything
+// else goes to the main kitchen unless the menu names a station itself.
+var categoryStations = map[string]string{
+	"grill":     StationGrill,
+	"bbq":       StationGrill,
+	"barbecue":  StationGrill,
+	"steaks":    StationGrill,
+	"burgers":   StationGrill,
+	"bar":       StationBar,
+	"drinks":    StationBar,
+	"beverages": StationBar,
+	"cocktails": StationBar,
+	"wine":      StationBar,
+	"beer":      StationBar,
+	"pastry":    StationPastry,
+	"desserts":  StationPastry,
+	"bakery":    StationPastry,
+	"cakes":     StationPastry,
+}
+
 type Menu struct {
-	ID         primitive.ObjectID `bson:"_id"`
-	Name       string             `json:"name" validate:"required"`
-	Category   string             `json:"category" validate:"required"`
-	Start_date time.Time          `json:"start_date"`
-	End_date   time.Time          `json:"end_date"`
-	Created_at time.Time          `json:"created_at"`
-	Updated_at time.Time          `json:"updated_at"`
-	Menu_id    string             `json:"menu_id"`
+	ID            primitive.ObjectID `bson:"_id"`
+	Name          string             `json:"name" validate:"required"`
+	Category      string             `json:"category" validate:"required"`
+	Station       string             `json:"station" validate:"omitempty,eq=kitchen|eq=grill|eq=bar|eq=pastry"`
+	Start_date    time.Time          `json:"start_date"`
+	End_date      time.Time          `json:"end_date"`
+	Created_at    time.Time          `json:"created_at"`
+	Updated_at    time.Time          `json:"updated_at"`
+	Menu_id       string             `json:"menu_id"`
+	Restaurant_id string             `json:"restaurant_id"`
+}
+
+// KitchenStation tells which station prepares the food on this menu.
+func (menu Menu) KitchenStation() string {
+	if menu.Station != "" {
+		return menu.Station
+	}
+
+	if station, ok := categoryStations[strings.ToLower(strings.TrimSpace(menu.Category))]; ok {
+		return station
+	}
+
+	return StationKitchen
 }
@@ -0,0 +1,165 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/vikas-gouda/go-restraunt-mangement/money"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ModifierGroup is a set of options a guest picks from for one food, e.g. a
+// Size group with exactly one pick or Toppings with up to five.
+type ModifierGroup struct {
+	Group_id   string           `json:"group_id"`
+	Name       string           `json:"name" validate:"required"`
+	Min_select int              `json:"min_select" validate:"gte=0"`
+	Max_select int              `json:"max_select" validate:"gte=1,gtefield=Min_select"`
+	Options    []ModifierOption `json:"options" validate:"required,min=1,dive"`
+}
+
+type ModifierOption struct {
+	Option_id   string      `json:"option_id"`
+	Name        string      `json:"name" validate:"required"`
+	Price_delta money.Money `json:"price_delta"`
+}
+
+// ChosenModifier is an option picked on an order item. The client sends the
+// ids; names and price are copied from the food when the item is created so
+// later menu edits don't change what was ordered.
+type ChosenModifier struct {
+	Group_id    string      `json:"group_id" validate:"required"`
+	Option_id   string      `json:"option_id" validate:"required"`
+	Group_name  string      `json:"group_name"`
+	Name        string      `json:"name"`
+	Price_delta money.Money `json:"price_delta"`
+}
+
+// PrepareModifierGroups gives new groups and options an id, puts the price
+// deltas in the food's currency and checks that the groups make sense
+// together.
+func PrepareModifierGroups(groups []ModifierGroup, currency string) error {
+	groupIds := map[string]bool{}
+
+	for i := range groups {
+		group := &groups[i]
+
+		if group.Group_id == "" {
+			group.Group_id = primitive.NewObjectID().Hex()
+		}
+
+		if groupIds[group.Group_id] {
+			return fmt.Errorf("modifier group %s is listed twice", group.Group_id)
+		}
+		groupIds[group.Group_id] = true
+
+		if group.Min_select > len(group.Options) {
+			return fmt.Errorf("modifier group %s asks for more picks than it has options", group.Name)
+		}
+
+		optionIds := map[string]bool{}
+		for j := range group.Options {
+			option := &group.Options[j]
+
+			if option.Option_id == "" {
+				option.Option_id = primitive.NewObjectID().Hex()
+			}
+
+			if option.Price_delta.Currency == "" {
+				option.Price_delta.Currency = currency
+			}
+
+			if option.Price_delta.Currency != currency {
+				return fmt.Errorf("option %s must be priced in %s", option.Name, currency)
+			}
+
+			if optionIds[option.Option_id] {
+				return fmt.Errorf("option %s is listed twice in modifier group %s", option.Option_id, group.Name)
+			}
+			optionIds[option.Option_id] = true
+		}
+	}
+
+	return nil
+}
+
+// ResolveModifiers checks the picks against the food's modifier groups and
+// returns them with names and prices filled in.
+func (food Food) ResolveModifiers(chosen []ChosenModifier) ([]ChosenModifier, error) {
+	resolved := []ChosenModifier{}
+	picks := map[string]int{}
+	seen := map[string]bool{}
+
+	for _, choice := range chosen {
+		group, ok := food.modifierGroup(choice.Group_id)
+		if !ok {
+			return nil, fmt.Errorf("%s has no modifier group %s", food.Name, choice.Group_id)
+		}
+
+		option, ok := group.option(choice.Option_id)
+		if !ok {
+			return nil, fmt.Errorf("modifier group %s has no option %s", group.Name, choice.Option_id)
+		}
+
+		if seen[group.Group_id+"/"+option.Option_id] {
+			return nil, fmt.Errorf("%s is picked twice", option.Name)
+		}
+		seen[group.Group_id+"/"+option.Option_id] = true
+		picks[group.Group_id]++
+
+		resolved = append(resolved, ChosenModifier{
+			Group_id:    group.Group_id,
+			Option_id:   option.Option_id,
+			Group_name:  group.Name,
+			Name:        option.Name,
+			Price_delta: option.Price_delta,
+		})
+	}
+
+	var errs []error
+	for _, group := range food.Modifier_groups {
+		count := picks[group.Group_id]
+		if count < group.Min_select {
+			errs = append(errs, fmt.Errorf("pick at least %d of %s", group.Min_select, group.Name))
+		}
+		if count > group.Max_select {
+			errs = append(errs, fmt.Errorf("pick at most %d of %s", group.Max_select, group.Name))
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return resolved, nil
+}
+
+func (food Food) modifierGroup(groupId string) (ModifierGroup, bool) {
+	for _, group := range food.Modifier_groups {
+		if group.Group_id == groupId {
+			return group, true
+		}
+	}
+
+	return ModifierGroup{}, false
+}
+
+func (group ModifierGroup) option(optionId string) (ModifierOption, bool) {
+	for _, option := range group.Options {
+		if option.Option_id == optionId {
+			return option, true
+		}
+	}
+
+	return ModifierOption{}, false
+}
+
+// ModifiersTotal adds up the price deltas of the picks.
+func ModifiersTotal(chosen []ChosenModifier, currency string) money.Money {
+	total := money.Zero(currency)
+	for _, choice := range chosen {
+		total = total.Add(choice.Price_delta)
+	}
+
+	return total
+}
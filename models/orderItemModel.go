@@ -3,16 +3,158 @@ package models
 import (
 	"time"
 
+	"github.com/vikas-gouda/go-restraunt-mangement/money"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+const (
+	ItemQueued  = "QUEUED"
+	ItemCooking = "COOKING"
+	ItemReady   = "READY"
+	ItemServed  = "SERVED"
+	ItemVoided  = "VOIDED"
+)
+
+// itemTransitions lists, for every item status, the statuses it may move to
+// next. The first entry is where a bump takes it.
+var itemTransitions = map[string][]string{
+	ItemQueued:  {ItemCooking, ItemVoided},
+	ItemCooking: {ItemReady, ItemVoided},
+	ItemReady:   {ItemServed, ItemVoided},
+}
+
+// itemTransitionRoles says who may move an item into a status. Owners are
+// allowed everything and are not listed.
+var itemTransitionRoles = map[string][]string{
+	ItemCooking: {RoleManager, RoleKitchen},
+	ItemReady:   {RoleManager, RoleKitchen},
+	ItemServed:  {RoleManager, RoleWaiter},
+	ItemVoided:  {RoleManager, RoleWaiter},
+}
+
 type OrderItem struct {
-	ID            primitive.ObjectID `bson:"_id"`
-	Quantity      string             `json:"quantity" validate:"required, eq=S|eq=M|eq=L"`
-	Unit_price    float64            `json:"unit_price" validate:"required"`
-	Created_at    time.Time          `json:"created_at"`
-	Updated_at    time.Time          `json:"updated_at"`
-	Food_id       *string            `json:"food_id" validate:"required"`
-	Order_item_id string             `json:"order_item_id"`
-	Order_id      *string            `json:"order_id" validate:"required"`
+	ID         primitive.ObjectID `bson:"_id"`
+	Quantity   int                `json:"quantity" validate:"required,gte=1,lte=999"`
+	Size       string             `json:"size" validate:"omitempty,eq=S|eq=M|eq=L"`
+	Seat       int                `json:"seat" validate:"gte=0,lte=99"`
+	Unit_price money.Money        `json:"unit_price"`
+	Modifiers  []ChosenModifier   `json:"modifiers" validate:"dive"`
+	// Line_total is (unit price + modifiers) * quantity, worked out when the
+	// item is saved so that later price edits don't change past orders.
+	Line_total    money.Money `json:"line_total"`
+	Status        string      `json:"status"`
+	Queued_at     *time.Time  `json:"queued_at,omitempty"`
+	Cooking_at    *time.Time  `json:"cooking_at,omitempty"`
+	Ready_at      *time.Time  `json:"ready_at,omitempty"`
+	Served_at     *time.Time  `json:"served_at,omitempty"`
+	Voided_at     *time.Time  `json:"voided_at,omitempty"`
+	Created_at    time.Time   `json:"created_at"`
+	Updated_at    time.Time   `json:"updated_at"`
+	Food_id       *string     `json:"food_id" validate:"required"`
+	Order_item_id string      `json:"order_item_id"`
+	Order_id      *string     `json:"order_id" validate:"required"`
+	Restaurant_id string      `json:"restaurant_id"`
+}
+
+// ItemBump is the body of a bump. Without a status the item moves one step
+// along QUEUED, COOKING, READY, SERVED.
+type ItemBump struct {
+	To string `json:"to" validate:"omitempty,eq=COOKING|eq=READY|eq=SERVED|eq=VOIDED"`
+}
+
+// CurrentStatus treats items created before statuses existed as queued.
+func (orderItem OrderItem) CurrentStatus() string {
+	if orderItem.Status == "" {
+		return ItemQueued
+	}
+
+	return orderItem.Status
+}
+
+// NextItemStatus is where a bump moves an item, or "" when it is done.
+func NextItemStatus(status string) string {
+	if next := itemTransitions[status]; len(next) > 0 {
+		return next[0]
+	}
+
+	return ""
+}
+
+func CanTransitionItem(from string, to string) bool {
+	for _, next := range itemTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+func CanMoveItemTo(role string, to string) bool {
+	if role == RoleOwner {
+		return true
+	}
+
+	for _, allowed := range itemTransitionRoles[to] {
+		if allowed == role {
+			return true
+		}
+	}
+
+	return false
+}
+
+// StampItemStatus sets the status and the matching timestamp.
+func StampItemStatus(orderItem *OrderItem, status string, at time.Time) {
+	orderItem.Status = status
+
+	switch status {
+	case ItemQueued:
+		orderItem.Queued_at = &at
+	case ItemCooking:
+		orderItem.Cooking_at = &at
+	case ItemReady:
+		orderItem.Ready_at = &at
+	case ItemServed:
+		orderItem.Served_at = &at
+	case ItemVoided:
+		orderItem.Voided_at = &at
+	}
+}
+
+// RollupOrderStatus derives where the kitchen is with an order from the
+// status of its items. Voided items don't count. It returns "" when the items
+// say nothing yet, i.e. nothing has been started.
+func RollupOrderStatus(orderItems []OrderItem) string {
+	counts := map[string]int{}
+	total := 0
+
+	for _, orderItem := range orderItems {
+		status := orderItem.CurrentStatus()
+		if status == ItemVoided {
+			continue
+		}
+		counts[status]++
+		total++
+	}
+
+	switch {
+	case total == 0:
+		return ""
+	case counts[ItemServed] == total:
+		return OrderServed
+	case counts[ItemReady]+counts[ItemServed] == total:
+		return OrderReady
+	case counts[ItemQueued] == total:
+		return ""
+	default:
+		return OrderPreparing
+	}
+}
+
+// LineTotal is (unit price + modifiers) * quantity. It is exact, nothing is
+// divided.
+func (orderItem OrderItem) LineTotal() money.Money {
+	unit := orderItem.Unit_price.Add(ModifiersTotal(orderItem.Modifiers, orderItem.Unit_price.Currency))
+	return unit.Mul(int64(orderItem.Quantity))
 }
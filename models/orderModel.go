@@ -6,11 +6,124 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+const (
+	OrderOpen          = "OPEN"
+	OrderSentToKitchen = "SENT_TO_KITCHEN"
+	OrderPreparing     = "PREPARING"
+	OrderReady         = "READY"
+	OrderServed        = "SERVED"
+	OrderPaid          = "PAID"
+	OrderCancelled     = "CANCELLED"
+)
+
+// orderTransitions lists, for every status, the statuses an order may move to
+// next. PAID and CANCELLED are final.
+var orderTransitions = map[string][]string{
+	OrderOpen:          {OrderSentToKitchen, OrderCancelled},
+	OrderSentToKitchen: {OrderPreparing, OrderCancelled},
+	OrderPreparing:     {OrderReady, OrderCancelled},
+	OrderReady:         {OrderServed},
+	OrderServed:        {OrderPaid},
+}
+
+// orderTransitionRoles says who may move an order into a status. Owners are
+// allowed everything and are not listed.
+var orderTransitionRoles = map[string][]string{
+	OrderSentToKitchen: {RoleManager, RoleWaiter},
+	OrderPreparing:     {RoleManager, RoleKitchen},
+	OrderReady:         {RoleManager, RoleKitchen},
+	OrderServed:        {RoleManager, RoleWaiter},
+	OrderPaid:          {RoleManager, RoleCashier},
+	OrderCancelled:     {RoleManager, RoleWaiter},
+}
+
 type Order struct {
-	ID         primitive.ObjectID `bson:"_id"`
-	Order_Date time.Time          `json:"order_date"`
-	Created_at time.Time          `json:"created_at"`
-	Updated_at time.Time          `json:"updated_at"`
-	Order_id   string             `json:"order_id"`
-	Table_id   *string            `json:"table_id" validate="required"`
+	ID             primitive.ObjectID `bson:"_id"`
+	Order_Date     time.Time          `json:"order_date"`
+	Created_at     time.Time          `json:"created_at"`
+	Updated_at     time.Time          `json:"updated_at"`
+	Order_id       string             `json:"order_id"`
+	Table_id       *string            `json:"table_id" validate:"required"`
+	Status         string             `json:"status"`
+	Status_history []OrderTransition  `json:"status_history"`
+	Discounts      []OrderDiscount    `json:"discounts"`
+	// Billing_version goes up whenever the invoices or the discounts of the
+	// order change, so two tills can't split or merge the same order at once
+	// and discounts can't change under an invoice being issued.
+	Billing_version int `json:"billing_version"`
+	// Server_id is who looks after the table; tips on the order go to them.
+	Server_id     string `json:"server_id"`
+	Restaurant_id string `json:"restaurant_id"`
+}
+
+type OrderTransition struct {
+	From   string    `json:"from"`
+	To     string    `json:"to" validate:"required,eq=OPEN|eq=SENT_TO_KITCHEN|eq=PREPARING|eq=READY|eq=SERVED|eq=PAID|eq=CANCELLED"`
+	At     time.Time `json:"at"`
+	By     string    `json:"by"`
+	Role   string    `json:"role"`
+	Reason string    `json:"reason,omitempty" bson:"reason,omitempty"`
+}
+
+// orderProgress is the order in which the kitchen moves an order along; the
+// item rollup only ever moves an order forward on it.
+var orderProgress = []string{OrderOpen, OrderSentToKitchen, OrderPreparing, OrderReady, OrderServed}
+
+// CurrentStatus treats orders created before statuses existed as open.
+func (order Order) CurrentStatus() string {
+	if order.Status == "" {
+		return OrderOpen
+	}
+
+	return order.Status
+}
+
+// Closed reports whether the order is paid or cancelled.
+func (order Order) Closed() bool {
+	status := order.CurrentStatus()
+	return status == OrderPaid || status == OrderCancelled
+}
+
+func CanTransitionOrder(from string, to string) bool {
+	for _, next := range orderTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+func CanMoveOrderTo(role string, to string) bool {
+	if role == RoleOwner {
+		return true
+	}
+
+	for _, allowed := range orderTransitionRoles[to] {
+		if allowed == role {
+			return true
+		}
+	}
+
+	return false
+}
+
+// OrderPathTo lists the statuses an order passes through to get from its
+// current status to target, or nil when target is not ahead of it.
+func OrderPathTo(from string, target string) []string {
+	start, end := -1, -1
+	for i, status := range orderProgress {
+		if status == from {
+			start = i
+		}
+		if status == target {
+			end = i
+		}
+	}
+
+	if start < 0 || end <= start {
+		return nil
+	}
+
+	return orderProgress[start+1 : end+1]
 }
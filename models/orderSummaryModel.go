@@ -0,0 +1,34 @@
+package models
+
+import "github.com/vikas-gouda/go-restraunt-mangement/money"
+
+// OrderSummary is the read model behind GetOrderItemsByOrder and the invoice
+// view: the items of one order joined with their food and table.
+type OrderSummary struct {
+	Order_id         string      `json:"order_id"`
+	Table_id         string      `json:"table_id"`
+	Table_number     int         `json:"table_number"`
+	Number_of_guests int         `json:"number_of_guests"`
+	Order_status     string      `json:"order_status"`
+	Payment_due      money.Money `json:"payment_due"`
+	Total_count      int         `json:"total_count"`
+	Ready_count      int         `json:"ready_count"`
+	Order_items      []OrderLine `json:"order_items"`
+}
+
+type OrderLine struct {
+	Order_item_id   string           `json:"order_item_id"`
+	Food_id         string           `json:"food_id"`
+	Food_name       string           `json:"food_name"`
+	Food_image      string           `json:"food_image"`
+	Quantity        int              `json:"quantity"`
+	Size            string           `json:"size"`
+	Seat            int              `json:"seat"`
+	Status          string           `json:"status"`
+	Unit_price      money.Money      `json:"unit_price"`
+	Price           money.Money      `json:"price"`
+	Modifiers       []ChosenModifier `json:"modifiers"`
+	Modifiers_total money.Money      `json:"modifiers_total"`
+	Tax_category    string           `json:"tax_category"`
+	Amount          money.Money      `json:"amount"`
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"github.com/vikas-gouda/go-restraunt-mangement/money"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	TenderCash    = "CASH"
+	TenderCard    = "CARD"
+	TenderVoucher = "VOUCHER"
+	TenderWallet  = "WALLET"
+)
+
+// Payment is one tender taken against an invoice. Amount goes towards the
+// bill and Tip on top of it; only cash gives change, out of Tendered. Card and
+// wallet payments that went through a payment provider carry its transaction.
+type Payment struct {
+	ID     primitive.ObjectID `bson:"_id"`
+	Tender string             `json:"tender" validate:"required,eq=CASH|eq=CARD|eq=VOUCHER|eq=WALLET"`
+	Amount money.Money        `json:"amount"`
+	Tip    money.Money        `json:"tip"`
+	// Tip_basis_points works the tip out as a share of Amount instead.
+	Tip_basis_points int64 `json:"tip_basis_points" validate:"gte=0,lte=10000"`
+	// Gratuity is the part of Amount that paid the automatic service charge.
+	Gratuity money.Money `json:"gratuity"`
+	Tendered money.Money `json:"tendered"`
+	Change   money.Money `json:"change"`
+	// Refunded and Tip_refunded add up the refunds of this payment.
+	Refunded     money.Money `json:"refunded"`
+	Tip_refunded money.Money `json:"tip_refunded"`
+	Reference    string      `json:"reference" validate:"max=100"`
+	// Card_token comes from the card reader or the provider's web form and is
+	// only passed on to the provider, never stored.
+	Card_token      string `json:"card_token,omitempty" bson:"-" validate:"max=200"`
+	Provider        string `json:"provider,omitempty"`
+	Transaction_id  string `json:"transaction_id,omitempty"`
+	Provider_status string `json:"provider_status,omitempty"`
+	Taken_by        string `json:"taken_by"`
+	// Server_id is who the tip and gratuity are attributed to.
+	Server_id     string    `json:"server_id"`
+	Role          string    `json:"role"`
+	Created_at    time.Time `json:"created_at"`
+	Payment_id    string    `json:"payment_id"`
+	Invoice_id    string    `json:"invoice_id"`
+	Order_id      string    `json:"order_id"`
+	Restaurant_id string    `json:"restaurant_id"`
+}
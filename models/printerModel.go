@@ -0,0 +1,59 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	PrinterKitchen = "KITCHEN"
+	PrinterReceipt = "RECEIPT"
+)
+
+// Printer is a network printer of the restaurant. Kitchen printers get a
+// ticket for every new order with the items their rules pick: the items of
+// their Stations and of their menu Categories. A kitchen printer without
+// either gets every item.
+type Printer struct {
+	ID         primitive.ObjectID `bson:"_id"`
+	Name       string             `json:"name" validate:"required,max=50"`
+	Address    string             `json:"address" validate:"required,max=255"`
+	Kind       string             `json:"kind" validate:"required,eq=KITCHEN|eq=RECEIPT"`
+	Stations   []string           `json:"stations" validate:"dive,eq=kitchen|eq=grill|eq=bar|eq=pastry"`
+	Categories []string           `json:"categories" validate:"dive,required,max=50"`
+	// Width is the number of characters per line, 48 on 80 mm paper and
+	// 40 on 58 mm.
+	Width         int       `json:"width" validate:"omitempty,eq=40|eq=48"`
+	Disabled      bool      `json:"disabled"`
+	Created_at    time.Time `json:"created_at"`
+	Updated_at    time.Time `json:"updated_at"`
+	Printer_id    string    `json:"printer_id"`
+	Restaurant_id string    `json:"restaurant_id"`
+}
+
+// Prints tells whether the ticket goes to this printer.
+func (printer Printer) Prints(ticket KitchenTicket) bool {
+	if printer.Disabled || printer.Kind != PrinterKitchen {
+		return false
+	}
+
+	if len(printer.Stations) == 0 && len(printer.Categories) == 0 {
+		return true
+	}
+
+	for _, station := range printer.Stations {
+		if station == ticket.Station {
+			return true
+		}
+	}
+
+	for _, category := range printer.Categories {
+		if strings.EqualFold(strings.TrimSpace(category), strings.TrimSpace(ticket.Category)) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,150 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/vikas-gouda/go-restraunt-mangement/money"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	DiscountPercent  = "PERCENT"
+	DiscountAmount   = "AMOUNT"
+	DiscountBuyXGetY = "BUY_X_GET_Y"
+
+	ScopeOrder = "ORDER"
+	ScopeItem  = "ITEM"
+)
+
+// DiscountRule says how much comes off. Item rules only touch the items of
+// Food_ids, or every item when it is empty; order rules are shared out over
+// whatever is left of the order after the item rules.
+type DiscountRule struct {
+	Kind  string `json:"kind" validate:"required,eq=PERCENT|eq=AMOUNT|eq=BUY_X_GET_Y"`
+	Scope string `json:"scope" validate:"required,eq=ORDER|eq=ITEM"`
+	// Basis_points is the share taken off by PERCENT rules, 1000 being 10%.
+	Basis_points int64 `json:"basis_points" validate:"gte=0,lte=10000"`
+	// Amount is taken off the order, or off every unit for item rules.
+	Amount money.Money `json:"amount"`
+	// Of every Buy_quantity + Free_quantity units the cheapest Free_quantity
+	// are free.
+	Buy_quantity  int      `json:"buy_quantity" validate:"gte=0,lte=100"`
+	Free_quantity int      `json:"free_quantity" validate:"gte=0,lte=100"`
+	Food_ids      []string `json:"food_ids"`
+}
+
+// Check catches rules that validate field by field but make no sense.
+func (rule DiscountRule) Check() error {
+	switch rule.Kind {
+	case DiscountPercent:
+		if rule.Basis_points == 0 {
+			return errors.New("a percent discount needs basis_points")
+		}
+	case DiscountAmount:
+		if rule.Amount.Amount <= 0 {
+			return errors.New("an amount discount needs an amount")
+		}
+	case DiscountBuyXGetY:
+		if rule.Scope != ScopeItem {
+			return errors.New("buy x get y discounts apply to items")
+		}
+		if rule.Buy_quantity < 1 || rule.Free_quantity < 1 {
+			return errors.New("buy x get y discounts need buy_quantity and free_quantity")
+		}
+	}
+
+	if rule.Scope == ScopeOrder && len(rule.Food_ids) > 0 {
+		return errors.New("order discounts can not be limited to foods")
+	}
+
+	return nil
+}
+
+// Targets reports whether an item rule applies to a food.
+func (rule DiscountRule) Targets(foodId string) bool {
+	if len(rule.Food_ids) == 0 {
+		return true
+	}
+
+	for _, id := range rule.Food_ids {
+		if id == foodId {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Promotion is a discount staff can put on an order, either by picking it or
+// by entering its coupon code.
+type Promotion struct {
+	ID           primitive.ObjectID `bson:"_id"`
+	Name         string             `json:"name" validate:"required,max=50"`
+	Code         string             `json:"code" validate:"omitempty,alphanum,max=20"`
+	DiscountRule `bson:",inline"`
+	Starts_at    *time.Time `json:"starts_at"`
+	Ends_at      *time.Time `json:"ends_at"`
+	// Max_uses caps how many orders the promotion goes on, 0 meaning no cap.
+	Max_uses int `json:"max_uses" validate:"gte=0"`
+	Uses     int `json:"uses"`
+	// Requires_approval promotions can only be applied by a manager, with a
+	// reason code.
+	Requires_approval bool      `json:"requires_approval"`
+	Active            bool      `json:"active"`
+	Created_at        time.Time `json:"created_at"`
+	Updated_at        time.Time `json:"updated_at"`
+	Promotion_id      string    `json:"promotion_id"`
+	Restaurant_id     string    `json:"restaurant_id"`
+}
+
+// Redeemable reports whether the promotion can go on another order at a.
+func (promotion Promotion) Redeemable(at time.Time) bool {
+	switch {
+	case !promotion.Active:
+		return false
+	case promotion.Starts_at != nil && at.Before(*promotion.Starts_at):
+		return false
+	case promotion.Ends_at != nil && !at.Before(*promotion.Ends_at):
+		return false
+	case promotion.Max_uses > 0 && promotion.Uses >= promotion.Max_uses:
+		return false
+	}
+
+	return true
+}
+
+// OrderDiscount is a discount put on an order. The rule is copied from the
+// promotion so later edits to it don't change orders already discounted.
+type OrderDiscount struct {
+	Discount_id  string `json:"discount_id"`
+	Promotion_id string `json:"promotion_id"`
+	Code         string `json:"code"`
+	Name         string `json:"name"`
+	DiscountRule `bson:",inline"`
+	Reason       string    `json:"reason,omitempty" bson:"reason,omitempty"`
+	Applied_by   string    `json:"applied_by"`
+	Role         string    `json:"role"`
+	Approved_by  string    `json:"approved_by,omitempty" bson:"approved_by,omitempty"`
+	Applied_at   time.Time `json:"applied_at"`
+}
+
+// DiscountRequest is the body for putting a discount on an order: a coupon
+// code, a promotion, or, for managers, a one-off rule with a reason.
+type DiscountRequest struct {
+	Code         string        `json:"code" validate:"omitempty,max=20"`
+	Promotion_id string        `json:"promotion_id"`
+	Name         string        `json:"name" validate:"omitempty,max=50"`
+	Rule         *DiscountRule `json:"rule"`
+	Reason       string        `json:"reason" validate:"omitempty,eq=STAFF_MEAL|eq=COMPLIMENTARY|eq=SERVICE_RECOVERY|eq=LOYALTY|eq=OTHER"`
+}
+
+// DiscountLine is one discount as it shows on an invoice.
+type DiscountLine struct {
+	Discount_id  string      `json:"discount_id"`
+	Promotion_id string      `json:"promotion_id"`
+	Code         string      `json:"code"`
+	Name         string      `json:"name"`
+	Reason       string      `json:"reason,omitempty"`
+	Amount       money.Money `json:"amount"`
+}
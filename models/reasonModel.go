@@ -0,0 +1,24 @@
+package models
+
+// Reason codes recorded with discounts that need a manager.
+const (
+	ReasonStaffMeal     = "STAFF_MEAL"
+	ReasonComplimentary = "COMPLIMENTARY"
+	ReasonLoyalty       = "LOYALTY"
+)
+
+// Reason codes recorded with refunds and voids. DUPLICATE_CHARGE is for
+// refunds only.
+const (
+	ReasonCustomerComplaint = "CUSTOMER_COMPLAINT"
+	ReasonWrongItem         = "WRONG_ITEM"
+	ReasonQuality           = "QUALITY"
+	ReasonDuplicateCharge   = "DUPLICATE_CHARGE"
+	ReasonEnteredInError    = "ENTERED_IN_ERROR"
+)
+
+// Reason codes for discounts as well as refunds and voids.
+const (
+	ReasonServiceRecovery = "SERVICE_RECOVERY"
+	ReasonOther           = "OTHER"
+)
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"time"
+
+	"github.com/vikas-gouda/go-restraunt-mangement/money"
+)
+
+// SalesReport adds up the money taken from From up to, not including, To.
+// Refunds count on the day they were given, against the tender they went
+// back on.
+type SalesReport struct {
+	From          time.Time     `json:"from"`
+	To            time.Time     `json:"to"`
+	Payments      int           `json:"payments"`
+	Gross         money.Money   `json:"gross"`
+	Tips          money.Money   `json:"tips"`
+	Refunds       money.Money   `json:"refunds"`
+	Tips_refunded money.Money   `json:"tips_refunded"`
+	Net           money.Money   `json:"net"`
+	Voids         int           `json:"voids"`
+	Voided        money.Money   `json:"voided"`
+	Tenders       []TenderTotal `json:"tenders"`
+	Reasons       []ReasonTotal `json:"reasons"`
+}
+
+type TenderTotal struct {
+	Tender        string      `json:"tender"`
+	Payments      int         `json:"payments"`
+	Gross         money.Money `json:"gross"`
+	Tips          money.Money `json:"tips"`
+	Refunds       money.Money `json:"refunds"`
+	Tips_refunded money.Money `json:"tips_refunded"`
+	Net           money.Money `json:"net"`
+}
+
+// ReasonTotal counts the credit notes of one kind and reason.
+type ReasonTotal struct {
+	Kind   string      `json:"kind"`
+	Reason string      `json:"reason"`
+	Count  int         `json:"count"`
+	Amount money.Money `json:"amount"`
+}
+
+// TipReport shares out the tips and service charges taken from From up to,
+// not including, To, by the tip pool rules of the restaurant.
+type TipReport struct {
+	From     time.Time    `json:"from"`
+	To       time.Time    `json:"to"`
+	Rules    TipPoolRules `json:"rules"`
+	Tips     money.Money  `json:"tips"`
+	Gratuity money.Money  `json:"gratuity"`
+	Pool     money.Money  `json:"pool"`
+	// Undistributed is the pool nobody on shift had a share of.
+	Undistributed money.Money `json:"undistributed"`
+	Staff         []TipShare  `json:"staff"`
+}
+
+type TipShare struct {
+	User_id string `json:"user_id"`
+	Name    string `json:"name"`
+	Role    string `json:"role"`
+	Minutes int64  `json:"minutes"`
+	Points  int64  `json:"points"`
+	// Tips and Gratuity were taken on the orders this user served; Kept is
+	// the part of them that stays out of the pool.
+	Tips       money.Money `json:"tips"`
+	Gratuity   money.Money `json:"gratuity"`
+	Kept       money.Money `json:"kept"`
+	Pool_share money.Money `json:"pool_share"`
+	Total      money.Money `json:"total"`
+}
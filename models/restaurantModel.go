@@ -0,0 +1,140 @@
+package models
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vikas-gouda/go-restraunt-mangement/money"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Restaurant struct {
+	ID       primitive.ObjectID `bson:"_id"`
+	Name     string             `json:"name" validate:"required"`
+	Code     string             `json:"code" validate:"required,alphanum,min=2,max=8"`
+	Address  string             `json:"address"`
+	Phone    string             `json:"phone"`
+	Currency string             `json:"currency" validate:"omitempty,iso4217"`
+	// Rounding is applied wherever an amount is divided, e.g. for taxes.
+	Rounding string `json:"rounding" validate:"omitempty,eq=HALF_UP|eq=HALF_EVEN"`
+	// Parties of at least Auto_gratuity_guests, going by the number of guests
+	// of the table, get a service charge of Auto_gratuity_basis_points on
+	// their bill. 0 guests turns it off.
+	Auto_gratuity_guests       int           `json:"auto_gratuity_guests" validate:"gte=0,lte=1000"`
+	Auto_gratuity_basis_points int64         `json:"auto_gratuity_basis_points" validate:"gte=0,lte=10000"`
+	Tip_pool                   *TipPoolRules `json:"tip_pool,omitempty" bson:"tip_pool,omitempty" validate:"omitempty"`
+	// Receipt_qr_url is printed as a QR code on receipts, e.g. to pay or to
+	// leave feedback. {invoice_id} and {restaurant_id} are filled in.
+	Receipt_qr_url string `json:"receipt_qr_url" validate:"omitempty,max=500"`
+	Receipt_footer string `json:"receipt_footer" validate:"omitempty,max=500"`
+	// Invoice_format lays out invoice numbers from {code}, {fy}, {fy_end}
+	// and {seq}, the sequence number padded to Invoice_digits. The fiscal
+	// year starts in month Fiscal_year_start and is named by the calendar
+	// year it starts in.
+	Invoice_format    string    `json:"invoice_format" validate:"omitempty,max=50,contains={seq}"`
+	Invoice_digits    int       `json:"invoice_digits" validate:"gte=0,lte=12"`
+	Fiscal_year_start int       `json:"fiscal_year_start" validate:"gte=0,lte=12"`
+	Created_at        time.Time `json:"created_at"`
+	Updated_at        time.Time `json:"updated_at"`
+	Restaurant_id     string    `json:"restaurant_id"`
+}
+
+// TipPoolRules say how the tips of a shift are shared out. Pool_basis_points
+// of every tip go into the pool and the rest stays with the server of the
+// order. The pool is shared by the role weights of the staff on shift, times
+// the minutes they worked when By_hours is set.
+type TipPoolRules struct {
+	Pool_basis_points int64            `json:"pool_basis_points" validate:"gte=0,lte=10000"`
+	By_hours          bool             `json:"by_hours"`
+	Role_weights      map[string]int64 `json:"role_weights" validate:"dive,keys,eq=OWNER|eq=MANAGER|eq=CASHIER|eq=WAITER|eq=KITCHEN,endkeys,gte=0,lte=1000"`
+}
+
+// TipPool returns the rules of the restaurant, or the default of pooling
+// everything by hours with waiters counting double.
+func (restaurant Restaurant) TipPool() TipPoolRules {
+	if restaurant.Tip_pool != nil {
+		return *restaurant.Tip_pool
+	}
+
+	return TipPoolRules{
+		Pool_basis_points: 10000,
+		By_hours:          true,
+		Role_weights:      map[string]int64{RoleWaiter: 2, RoleCashier: 1, RoleKitchen: 1},
+	}
+}
+
+// AutoGratuity is the service charge in basis points for a party of guests.
+func (restaurant Restaurant) AutoGratuity(guests int) int64 {
+	if restaurant.Auto_gratuity_guests == 0 || guests < restaurant.Auto_gratuity_guests {
+		return 0
+	}
+
+	return restaurant.Auto_gratuity_basis_points
+}
+
+// ReceiptQR returns the QR code link for the receipt of an invoice.
+func (restaurant Restaurant) ReceiptQR(invoiceId string) string {
+	return strings.NewReplacer("{invoice_id}", url.PathEscape(invoiceId), "{restaurant_id}", url.PathEscape(restaurant.Restaurant_id)).Replace(restaurant.Receipt_qr_url)
+}
+
+// Defaults for restaurants that didn't set up invoice numbering.
+const (
+	DefaultInvoiceFormat = "{code}-{fy}-{seq}"
+	DefaultInvoiceDigits = 6
+)
+
+// InvoiceNumbering returns how invoices made at at are numbered. Days are
+// counted in UTC.
+func (restaurant Restaurant) InvoiceNumbering(at time.Time) InvoiceNumbering {
+	numbering := InvoiceNumbering{
+		Format: restaurant.Invoice_format,
+		Digits: restaurant.Invoice_digits,
+		Code:   strings.ToUpper(restaurant.Code),
+	}
+
+	if numbering.Format == "" {
+		numbering.Format = DefaultInvoiceFormat
+	}
+
+	if numbering.Digits == 0 {
+		numbering.Digits = DefaultInvoiceDigits
+	}
+
+	start := time.Month(restaurant.Fiscal_year_start)
+	if start == 0 {
+		start = time.January
+	}
+
+	at = at.UTC()
+	numbering.Fiscal_year = at.Year()
+	if at.Month() < start {
+		numbering.Fiscal_year--
+	}
+
+	// Without the year in the number, counting on across years is the only
+	// way to keep numbers unique.
+	if strings.Contains(numbering.Format, "{fy") {
+		numbering.Series = strconv.Itoa(numbering.Fiscal_year)
+	}
+
+	return numbering
+}
+
+// Money returns the currency and rounding mode of the restaurant, falling
+// back to the defaults for restaurants created before they existed.
+func (restaurant Restaurant) Money() (string, money.Rounding) {
+	currency := restaurant.Currency
+	if currency == "" {
+		currency = money.DefaultCurrency
+	}
+
+	rounding := money.Rounding(restaurant.Rounding)
+	if rounding == "" {
+		rounding = money.HalfUp
+	}
+
+	return currency, rounding
+}
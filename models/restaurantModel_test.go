@@ -0,0 +1,33 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestInvoiceNumbering(t *testing.T) {
+	for _, test := range []struct {
+		name       string
+		restaurant Restaurant
+		at         string
+		series     string
+		number     string
+	}{
+		{"defaults", Restaurant{Code: "cafe"}, "2026-03-14T10:00:00Z", "2026", "CAFE-2026-000042"},
+		{"fiscal year not started yet", Restaurant{Code: "CAFE", Fiscal_year_start: 4}, "2026-03-31T23:59:59Z", "2025", "CAFE-2025-000042"},
+		{"fiscal year started", Restaurant{Code: "CAFE", Fiscal_year_start: 4}, "2026-04-01T00:00:00Z", "2026", "CAFE-2026-000042"},
+		{"days counted in UTC", Restaurant{Code: "CAFE"}, "2026-12-31T23:30:00-02:00", "2027", "CAFE-2027-000042"},
+		{"fiscal year end", Restaurant{Code: "CAFE", Fiscal_year_start: 7, Invoice_format: "{fy}/{fy_end}/{seq}", Invoice_digits: 4}, "2026-08-01T00:00:00Z", "2026", "2026/2027/0042"},
+		{"counted on without the year", Restaurant{Code: "CAFE", Invoice_format: "{code}{seq}", Invoice_digits: 3}, "2026-01-01T00:00:00Z", "", "CAFE042"},
+	} {
+		at, err := time.Parse(time.RFC3339, test.at)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		numbering := test.restaurant.InvoiceNumbering(at)
+		if numbering.Series != test.series || numbering.Number(42) != test.number {
+			t.Errorf("%s: series %q and number %q, want %q and %q", test.name, numbering.Series, numbering.Number(42), test.series, test.number)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Shift is the time one member of staff was clocked in. Open shifts have no
+// Clock_out yet; a user has at most one open shift.
+type Shift struct {
+	ID            primitive.ObjectID `bson:"_id"`
+	User_id       string             `json:"user_id"`
+	Role          string             `json:"role"`
+	Clock_in      time.Time          `json:"clock_in"`
+	Clock_out     *time.Time         `json:"clock_out"`
+	Open          bool               `json:"open"`
+	Created_at    time.Time          `json:"created_at"`
+	Updated_at    time.Time          `json:"updated_at"`
+	Shift_id      string             `json:"shift_id"`
+	Restaurant_id string             `json:"restaurant_id"`
+}
+
+// ShiftRequest clocks a user in or out. Without a user id it is the caller;
+// managers can clock others.
+type ShiftRequest struct {
+	User_id string `json:"user_id"`
+}
+
+// ShiftPatch is how managers correct the times of a shift.
+type ShiftPatch struct {
+	Clock_in  *time.Time `json:"clock_in"`
+	Clock_out *time.Time `json:"clock_out"`
+}
+
+// Minutes is how long the shift overlaps from and to, counting an open shift
+// up to now.
+func (shift Shift) Minutes(from time.Time, to time.Time, now time.Time) int64 {
+	end := now
+	if shift.Clock_out != nil {
+		end = *shift.Clock_out
+	}
+
+	start := shift.Clock_in
+	if start.Before(from) {
+		start = from
+	}
+	if end.After(to) {
+		end = to
+	}
+
+	if !end.After(start) {
+		return 0
+	}
+
+	return int64(end.Sub(start) / time.Minute)
+}
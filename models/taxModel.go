@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"github.com/vikas-gouda/go-restraunt-mangement/money"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TaxRate applies to every item whose food has the same tax category. A rate
+// without a category, such as a service charge, applies to every item.
+type TaxRate struct {
+	ID   primitive.ObjectID `bson:"_id"`
+	Name string             `json:"name" validate:"required,max=50"`
+	// Category is matched against Food.Tax_category, e.g. FOOD or ALCOHOL.
+	Category string `json:"category" validate:"omitempty,max=30"`
+	// Basis_points is the rate, 500 being 5%.
+	Basis_points int64 `json:"basis_points" validate:"gte=0,lte=10000"`
+	// Inclusive rates are already part of the menu price and are carved out
+	// of it; the others are added on top.
+	Inclusive     bool      `json:"inclusive"`
+	Created_at    time.Time `json:"created_at"`
+	Updated_at    time.Time `json:"updated_at"`
+	Tax_rate_id   string    `json:"tax_rate_id"`
+	Restaurant_id string    `json:"restaurant_id"`
+}
+
+// Applies reports whether the rate is charged on items of a tax category.
+func (rate TaxRate) Applies(category string) bool {
+	return rate.Category == "" || rate.Category == category
+}
+
+type TaxLine struct {
+	Tax_rate_id  string      `json:"tax_rate_id"`
+	Name         string      `json:"name"`
+	Category     string      `json:"category"`
+	Basis_points int64       `json:"basis_points"`
+	Inclusive    bool        `json:"inclusive"`
+	Taxable      money.Money `json:"taxable"`
+	Amount       money.Money `json:"amount"`
+}
+
+// TaxBreakdown splits what is due into the amount before tax and the tax
+// owed per rate. Subtotal plus every tax line is the total.
+type TaxBreakdown struct {
+	Subtotal money.Money `json:"subtotal"`
+	Taxes    []TaxLine   `json:"taxes"`
+	Tax      money.Money `json:"tax"`
+	Total    money.Money `json:"total"`
+}
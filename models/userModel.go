@@ -6,17 +6,26 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+const (
+	RoleOwner   = "OWNER"
+	RoleManager = "MANAGER"
+	RoleCashier = "CASHIER"
+	RoleWaiter  = "WAITER"
+	RoleKitchen = "KITCHEN"
+)
+
+// User.Password holds the bcrypt hash and is never sent to clients.
 type User struct {
 	ID            primitive.ObjectID `bson:"_id"`
 	First_name    string             `json:"first_name"`
 	Last_name     string             `json:"last_name"`
-	Password      string             `json:"password" validate:"required"`
+	Password      string             `json:"-"`
 	Email         string             `json:"email" validate:"email"`
 	Avatar        string             `json:"avatar"`
 	Phone         string             `json:"phone" validate:"required"`
-	Token         string             `json:"token"`
-	Refresh_token string             `json:"refresh_token"`
+	Role          string             `json:"role" validate:"omitempty,eq=OWNER|eq=MANAGER|eq=CASHIER|eq=WAITER|eq=KITCHEN"`
 	Created_at    time.Time          `json:"created_at"`
 	Updated_at    time.Time          `json:"updated_at"`
 	User_id       string             `json:"user_id"`
+	Restaurant_id string             `json:"restaurant_id"`
 }
@@ -0,0 +1,282 @@
+// Package money does exact arithmetic on amounts kept as whole minor units
+// (cents, pence, ...) of a currency. Only the operations that divide, like
+// percentages and splits, round, and they take the rounding mode explicitly.
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+type Rounding string
+
+const (
+	// HalfUp rounds halves away from zero: 0.125 becomes 0.13.
+	HalfUp Rounding = "HALF_UP"
+	// HalfEven rounds halves to the even neighbour (banker's rounding):
+	// 0.125 becomes 0.12 and 0.135 becomes 0.14.
+	HalfEven Rounding = "HALF_EVEN"
+)
+
+// truncate drops the remainder; only Allocate uses it.
+const truncate Rounding = "TRUNCATE"
+
+const DefaultCurrency = "USD"
+
+// exponents lists the currencies that don't have two decimals.
+var exponents = map[string]int{
+	"BHD": 3, "CLP": 0, "ISK": 0, "JOD": 3, "JPY": 0, "KRW": 0,
+	"KWD": 3, "OMR": 3, "TND": 3, "UGX": 0, "VND": 0, "XAF": 0, "XOF": 0,
+}
+
+// Exponent is the number of decimals of the currency's minor unit.
+func Exponent(currency string) int {
+	if exponent, ok := exponents[currency]; ok {
+		return exponent
+	}
+
+	return 2
+}
+
+type Money struct {
+	Amount   int64  `json:"amount" bson:"amount"`
+	Currency string `json:"currency" bson:"currency"`
+}
+
+func New(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+func Zero(currency string) Money {
+	return Money{Currency: currency}
+}
+
+// Parse reads a decimal such as "12.5" or "-0.75" into minor units. More
+// decimals than the currency has are an error rather than rounded away.
+func Parse(value string, currency string) (Money, error) {
+	value = strings.TrimSpace(value)
+	exponent := Exponent(currency)
+
+	sign := int64(1)
+	if strings.HasPrefix(value, "-") {
+		sign = -1
+		value = value[1:]
+	}
+
+	whole, fraction, _ := strings.Cut(value, ".")
+	if whole == "" && fraction == "" {
+		return Money{}, errors.New("money: empty amount")
+	}
+
+	if len(fraction) > exponent {
+		return Money{}, fmt.Errorf("money: %s allows %d decimals", currency, exponent)
+	}
+
+	digits := whole + fraction + strings.Repeat("0", exponent-len(fraction))
+	amount, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil || strings.ContainsAny(digits, "+-") {
+		return Money{}, fmt.Errorf("money: invalid amount %q", value)
+	}
+
+	return Money{Amount: sign * amount, Currency: currency}, nil
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+func (m Money) IsNegative() bool {
+	return m.Amount < 0
+}
+
+// Add sums two amounts. An amount without a currency, such as the zero value,
+// takes on the other one; adding two different currencies is a bug in the
+// caller and panics.
+func (m Money) Add(other Money) Money {
+	return Money{Amount: m.Amount + other.Amount, Currency: m.currencyWith(other)}
+}
+
+func (m Money) Sub(other Money) Money {
+	return Money{Amount: m.Amount - other.Amount, Currency: m.currencyWith(other)}
+}
+
+func (m Money) Mul(n int64) Money {
+	return Money{Amount: m.Amount * n, Currency: m.Currency}
+}
+
+func (m Money) Neg() Money {
+	return Money{Amount: -m.Amount, Currency: m.Currency}
+}
+
+// Cmp returns -1, 0 or 1 as m is less than, equal to or more than other.
+func (m Money) Cmp(other Money) int {
+	m.currencyWith(other)
+
+	switch {
+	case m.Amount < other.Amount:
+		return -1
+	case m.Amount > other.Amount:
+		return 1
+	}
+
+	return 0
+}
+
+// Ratio is m * numerator / denominator, rounded to a whole minor unit.
+func (m Money) Ratio(numerator int64, denominator int64, rounding Rounding) Money {
+	return Money{Amount: divide(big.NewInt(m.Amount), numerator, denominator, rounding), Currency: m.Currency}
+}
+
+// Percent takes a share of m given in basis points, 1250 being 12.5%.
+func (m Money) Percent(basisPoints int64, rounding Rounding) Money {
+	return m.Ratio(basisPoints, 10000, rounding)
+}
+
+// Split divides m into n parts that differ by at most one minor unit and add
+// up to m exactly; the first parts get the extra units.
+func (m Money) Split(n int) []Money {
+	if n < 1 {
+		return nil
+	}
+
+	parts := make([]Money, n)
+	share := m.Amount / int64(n)
+	rest := m.Amount % int64(n)
+
+	for i := range parts {
+		parts[i] = Money{Amount: share, Currency: m.Currency}
+		if rest > 0 {
+			parts[i].Amount++
+			rest--
+		} else if rest < 0 {
+			parts[i].Amount--
+			rest++
+		}
+	}
+
+	return parts
+}
+
+// Allocate divides m in proportion to weights. The units lost to rounding
+// down are handed out one by one from the first share, so the parts always
+// add up to m.
+func (m Money) Allocate(weights []int64) []Money {
+	var total int64
+	for _, weight := range weights {
+		total += weight
+	}
+
+	parts := make([]Money, len(weights))
+	if total == 0 {
+		for i := range parts {
+			parts[i] = Zero(m.Currency)
+		}
+		return parts
+	}
+
+	allocated := int64(0)
+	for i, weight := range weights {
+		parts[i] = Money{Amount: divide(big.NewInt(m.Amount), weight, total, truncate), Currency: m.Currency}
+		allocated += parts[i].Amount
+	}
+
+	step := int64(1)
+	if m.Amount < 0 {
+		step = -1
+	}
+
+	for i := 0; allocated != m.Amount; i = (i + 1) % len(parts) {
+		if weights[i] == 0 {
+			continue
+		}
+		parts[i].Amount += step
+		allocated += step
+	}
+
+	return parts
+}
+
+func Sum(currency string, amounts ...Money) Money {
+	total := Zero(currency)
+	for _, amount := range amounts {
+		total = total.Add(amount)
+	}
+
+	return total
+}
+
+// Decimal formats the amount with the currency's decimals, e.g. "12.50".
+func (m Money) Decimal() string {
+	exponent := Exponent(m.Currency)
+
+	amount := m.Amount
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	digits := strconv.FormatInt(amount, 10)
+	if exponent == 0 {
+		return sign + digits
+	}
+
+	if len(digits) <= exponent {
+		digits = strings.Repeat("0", exponent-len(digits)+1) + digits
+	}
+
+	return sign + digits[:len(digits)-exponent] + "." + digits[len(digits)-exponent:]
+}
+
+func (m Money) String() string {
+	return m.Decimal() + " " + m.Currency
+}
+
+func (m Money) currencyWith(other Money) string {
+	switch {
+	case m.Currency == "":
+		return other.Currency
+	case other.Currency == "" || other.Currency == m.Currency:
+		return m.Currency
+	}
+
+	panic(fmt.Sprintf("money: mixing %s and %s", m.Currency, other.Currency))
+}
+
+func divide(value *big.Int, numerator int64, denominator int64, rounding Rounding) int64 {
+	product := new(big.Int).Mul(value, big.NewInt(numerator))
+	den := big.NewInt(denominator)
+
+	quotient, remainder := new(big.Int).QuoRem(product, den, new(big.Int))
+	if remainder.Sign() == 0 || rounding == truncate {
+		return quotient.Int64()
+	}
+
+	// Compare twice the remainder with the denominator to find the halves.
+	twice := new(big.Int).Abs(remainder)
+	twice.Mul(twice, big.NewInt(2))
+	half := twice.Cmp(new(big.Int).Abs(den))
+
+	away := half > 0
+	if half == 0 {
+		switch rounding {
+		case HalfEven:
+			away = quotient.Bit(0) == 1
+		default:
+			away = true
+		}
+	}
+
+	if away {
+		if product.Sign()*den.Sign() < 0 {
+			quotient.Sub(quotient, big.NewInt(1))
+		} else {
+			quotient.Add(quotient, big.NewInt(1))
+		}
+	}
+
+	return quotient.Int64()
+}
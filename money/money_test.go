@@ -0,0 +1,156 @@
+package money
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRatioRounding(t *testing.T) {
+	for _, test := range []struct {
+		amount      int64
+		numerator   int64
+		denominator int64
+		rounding    Rounding
+		want        int64
+	}{
+		{125, 1, 10, HalfUp, 13},
+		{125, 1, 10, HalfEven, 12},
+		{135, 1, 10, HalfEven, 14},
+		{-125, 1, 10, HalfUp, -13},
+		{-125, 1, 10, HalfEven, -12},
+		{124, 1, 10, HalfUp, 12},
+		{126, 1, 10, HalfEven, 13},
+		{100, 1, 3, HalfUp, 33},
+		{200, 1, 3, HalfEven, 67},
+		{1000, 1, 1, HalfUp, 1000},
+	} {
+		got := New(test.amount, "USD").Ratio(test.numerator, test.denominator, test.rounding)
+		if got.Amount != test.want || got.Currency != "USD" {
+			t.Errorf("%d * %d/%d %s = %s, want %d", test.amount, test.numerator, test.denominator, test.rounding, got, test.want)
+		}
+	}
+}
+
+func TestPercent(t *testing.T) {
+	for _, test := range []struct {
+		amount      int64
+		basisPoints int64
+		rounding    Rounding
+		want        int64
+	}{
+		{1000, 1250, HalfUp, 125},
+		{1050, 825, HalfUp, 87},
+		{100, 1250, HalfUp, 13},
+		{100, 1250, HalfEven, 12},
+		{999, 0, HalfUp, 0},
+	} {
+		if got := New(test.amount, "USD").Percent(test.basisPoints, test.rounding); got.Amount != test.want {
+			t.Errorf("%d bp of %d %s = %d, want %d", test.basisPoints, test.amount, test.rounding, got.Amount, test.want)
+		}
+	}
+}
+
+func TestSplit(t *testing.T) {
+	for _, test := range []struct {
+		amount int64
+		n      int
+		want   []int64
+	}{
+		{1000, 3, []int64{334, 333, 333}},
+		{1001, 4, []int64{251, 250, 250, 250}},
+		{-1000, 3, []int64{-334, -333, -333}},
+		{2, 3, []int64{1, 1, 0}},
+		{500, 1, []int64{500}},
+		{500, 0, nil},
+	} {
+		if got := amounts(New(test.amount, "USD").Split(test.n)); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%d split %d ways = %v, want %v", test.amount, test.n, got, test.want)
+		}
+	}
+}
+
+func TestAllocate(t *testing.T) {
+	for _, test := range []struct {
+		amount  int64
+		weights []int64
+		want    []int64
+	}{
+		{100, []int64{1, 1, 1}, []int64{34, 33, 33}},
+		{1000, []int64{600, 400}, []int64{600, 400}},
+		{500, []int64{1, 2}, []int64{167, 333}},
+		{-100, []int64{1, 1, 1}, []int64{-34, -33, -33}},
+		{101, []int64{0, 1, 1}, []int64{0, 51, 50}},
+		{100, []int64{0, 0}, []int64{0, 0}},
+		{7, []int64{1000, 1, 1}, []int64{7, 0, 0}},
+	} {
+		parts := New(test.amount, "USD").Allocate(test.weights)
+		if got := amounts(parts); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%d by %v = %v, want %v", test.amount, test.weights, got, test.want)
+		}
+
+		// Without any weight nothing is handed out.
+		want := test.amount
+		if reflect.DeepEqual(test.weights, make([]int64, len(test.weights))) {
+			want = 0
+		}
+
+		if sum := Sum("USD", parts...); sum.Amount != want {
+			t.Errorf("%d by %v adds up to %d", test.amount, test.weights, sum.Amount)
+		}
+	}
+}
+
+func TestParseAndDecimal(t *testing.T) {
+	for _, test := range []struct {
+		value    string
+		currency string
+		amount   int64
+		decimal  string
+	}{
+		{"12.5", "USD", 1250, "12.50"},
+		{"-0.75", "USD", -75, "-0.75"},
+		{"0.05", "EUR", 5, "0.05"},
+		{"1200", "JPY", 1200, "1200"},
+		{"1.234", "KWD", 1234, "1.234"},
+		{".5", "USD", 50, "0.50"},
+	} {
+		m, err := Parse(test.value, test.currency)
+		if err != nil {
+			t.Errorf("parsing %s %s: %v", test.value, test.currency, err)
+			continue
+		}
+
+		if m.Amount != test.amount || m.Decimal() != test.decimal {
+			t.Errorf("%s %s = %d (%s), want %d (%s)", test.value, test.currency, m.Amount, m.Decimal(), test.amount, test.decimal)
+		}
+	}
+
+	for _, value := range []string{"", "1.005", "abc", "1.-5", "--1", "+1"} {
+		if m, err := Parse(value, "USD"); err == nil {
+			t.Errorf("%q parsed as %s, want an error", value, m)
+		}
+	}
+}
+
+func TestMixingCurrenciesPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("adding USD to EUR did not panic")
+		}
+	}()
+
+	New(100, "USD").Add(New(100, "EUR"))
+}
+
+func amounts(parts []Money) []int64 {
+	if parts == nil {
+		return nil
+	}
+
+	got := make([]int64, len(parts))
+	for i, part := range parts {
+		got[i] = part.Amount
+	}
+
+	return got
+}
@@ -0,0 +1,143 @@
+// Package escpos writes the ESC/POS commands understood by most receipt and
+// kitchen printers. Text is sent in the Windows-1252 code page; characters
+// outside of it print as question marks.
+package escpos
+
+import (
+	"bytes"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+const (
+	esc = 0x1b
+	gs  = 0x1d
+	lf  = 0x0a
+
+	// codePage1252 selects Windows-1252 with ESC t on Epson compatible
+	// printers.
+	codePage1252 = 16
+)
+
+type Align byte
+
+const (
+	Left   Align = 0
+	Center Align = 1
+	Right  Align = 2
+)
+
+// Buffer collects the commands of one print job. The zero value is not
+// usable; call New.
+type Buffer struct {
+	b bytes.Buffer
+}
+
+// New starts a job by resetting the printer and selecting the code page.
+func New() *Buffer {
+	buf := &Buffer{}
+	buf.b.Write([]byte{esc, '@'})
+	buf.b.Write([]byte{esc, 't', codePage1252})
+	return buf
+}
+
+func (buf *Buffer) Align(align Align) *Buffer {
+	buf.b.Write([]byte{esc, 'a', byte(align)})
+	return buf
+}
+
+func (buf *Buffer) Bold(on bool) *Buffer {
+	buf.b.Write([]byte{esc, 'E', flag(on)})
+	return buf
+}
+
+// Size scales the characters, 1 to 8 times in each direction.
+func (buf *Buffer) Size(width int, height int) *Buffer {
+	buf.b.Write([]byte{gs, '!', byte(clamp(width)-1)<<4 | byte(clamp(height)-1)})
+	return buf
+}
+
+// Invert prints white on black, e.g. for allergy warnings.
+func (buf *Buffer) Invert(on bool) *Buffer {
+	buf.b.Write([]byte{gs, 'B', flag(on)})
+	return buf
+}
+
+// Text writes text as is; use Line to end the line.
+func (buf *Buffer) Text(text string) *Buffer {
+	for _, r := range text {
+		b, ok := charmap.Windows1252.EncodeRune(r)
+		if !ok {
+			b = '?'
+		}
+		buf.b.WriteByte(b)
+	}
+
+	return buf
+}
+
+func (buf *Buffer) Line(text string) *Buffer {
+	buf.Text(text)
+	buf.b.WriteByte(lf)
+	return buf
+}
+
+// Feed prints the buffer and feeds lines more paper.
+func (buf *Buffer) Feed(lines int) *Buffer {
+	buf.b.Write([]byte{esc, 'd', byte(lines)})
+	return buf
+}
+
+// QR prints data as a QR code of module size 1 to 16 dots.
+func (buf *Buffer) QR(data string, size int) *Buffer {
+	if size < 1 {
+		size = 1
+	}
+	if size > 16 {
+		size = 16
+	}
+
+	// Model 2, the module size, error correction M, then the data.
+	buf.b.Write([]byte{gs, '(', 'k', 4, 0, '1', 'A', '2', 0})
+	buf.b.Write([]byte{gs, '(', 'k', 3, 0, '1', 'C', byte(size)})
+	buf.b.Write([]byte{gs, '(', 'k', 3, 0, '1', 'E', '1'})
+
+	n := len(data) + 3
+	buf.b.Write([]byte{gs, '(', 'k', byte(n % 256), byte(n / 256), '1', 'P', '0'})
+	buf.b.WriteString(data)
+	buf.b.Write([]byte{gs, '(', 'k', 3, 0, '1', 'Q', '0'})
+	return buf
+}
+
+// Cut feeds the paper past the cutter and cuts it, leaving a small hinge.
+func (buf *Buffer) Cut() *Buffer {
+	buf.b.Write([]byte{gs, 'V', 66, 0})
+	return buf
+}
+
+// Beep sounds the buzzer of kitchen printers that have one.
+func (buf *Buffer) Beep(times int, duration int) *Buffer {
+	buf.b.Write([]byte{esc, 'B', byte(times), byte(duration)})
+	return buf
+}
+
+func (buf *Buffer) Bytes() []byte {
+	return buf.b.Bytes()
+}
+
+func flag(on bool) byte {
+	if on {
+		return 1
+	}
+	return 0
+}
+
+func clamp(scale int) int {
+	if scale < 1 {
+		return 1
+	}
+	if scale > 8 {
+		return 8
+	}
+	return scale
+}
@@ -0,0 +1,113 @@
+package printing
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"syscall"
+)
+
+// ErrAddressNotAllowed is returned for printers outside the allowed networks.
+var ErrAddressNotAllowed = errors.New("printing: printer address is not on an allowed network")
+
+// Networks are the address ranges printers may be on. Printer addresses come
+// from users, so without them the server could be made to send data to any
+// host it can reach.
+type Networks []*net.IPNet
+
+// LAN is the private IPv4 and IPv6 ranges. Loopback and link-local are left
+// out; nothing there is a printer, but other services and cloud metadata are.
+var LAN = mustParseNetworks("10.0.0.0/8,172.16.0.0/12,192.168.0.0/16,fc00::/7")
+
+// ParseNetworks reads a comma separated list of CIDR ranges and single IPs.
+func ParseNetworks(list string) (Networks, error) {
+	var networks Networks
+
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("%q is not an IP address or CIDR range", entry)
+			}
+
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, err
+		}
+		networks = append(networks, network)
+	}
+
+	return networks, nil
+}
+
+func mustParseNetworks(list string) Networks {
+	networks, err := ParseNetworks(list)
+	if err != nil {
+		panic(err)
+	}
+
+	return networks
+}
+
+func (n Networks) String() string {
+	entries := make([]string, len(n))
+	for i, network := range n {
+		entries[i] = network.String()
+	}
+
+	return strings.Join(entries, ",")
+}
+
+func (n Networks) Contains(ip net.IP) bool {
+	for _, network := range n {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Allows tells whether a printer may be set up at address. Host names pass;
+// what they resolve to is checked each time a job is sent.
+func (n Networks) Allows(address string) error {
+	host, _, err := net.SplitHostPort(Address(address))
+	if err != nil {
+		return err
+	}
+
+	if ip := net.ParseIP(host); ip != nil && !n.Contains(ip) {
+		return ErrAddressNotAllowed
+	}
+
+	return nil
+}
+
+// control refuses connections to addresses outside the networks. It runs
+// after the host name was resolved, so a name can't point elsewhere.
+func (n Networks) control(network string, address string, c syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	if !n.Contains(net.ParseIP(host)) {
+		return ErrAddressNotAllowed
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package printing
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vikas-gouda/go-restraunt-mangement/printing/printertest"
+)
+
+func TestNetworksAllow(t *testing.T) {
+	allowlist, err := ParseNetworks("203.0.113.7, 198.51.100.0/24")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		networks Networks
+		address  string
+		allowed  bool
+	}{
+		{LAN, "192.168.1.50", true},
+		{LAN, "10.0.0.8:9100", true},
+		{LAN, "[fd00::12]:9100", true},
+		{LAN, "grill.local", true},
+		{LAN, "127.0.0.1:27017", false},
+		{LAN, "169.254.169.254:80", false},
+		{LAN, "8.8.8.8", false},
+		{LAN, "[::1]:9100", false},
+		{allowlist, "203.0.113.7", true},
+		{allowlist, "203.0.113.8", false},
+		{allowlist, "198.51.100.20", true},
+		{allowlist, "192.168.1.50", false},
+	} {
+		if err := test.networks.Allows(test.address); (err == nil) != test.allowed {
+			t.Errorf("%s on %s: got %v, want allowed %v", test.address, test.networks, err, test.allowed)
+		}
+	}
+
+	if _, err := ParseNetworks("10.0.0.0/8,printer"); err == nil {
+		t.Error("a host name was taken as a network")
+	}
+}
+
+func TestNetworksSendOnlyToAllowedPrinters(t *testing.T) {
+	printer := printertest.NewServer()
+	defer printer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	// The name resolves to loopback, which is not on the LAN.
+	_, port, _ := strings.Cut(printer.Addr(), ":")
+	if err := LAN.Send(ctx, "localhost:"+port, []byte("page")); !errors.Is(err, ErrAddressNotAllowed) {
+		t.Errorf("sending to loopback gave %v, want ErrAddressNotAllowed", err)
+	}
+
+	loopback, _ := ParseNetworks("127.0.0.1")
+	if err := loopback.Send(ctx, printer.Addr(), []byte("page")); err != nil {
+		t.Fatal(err)
+	}
+
+	if jobs := printer.Wait(1, time.Second); len(jobs) != 1 || string(jobs[0]) != "page" {
+		t.Errorf("printer got %q, want only the allowed page", jobs)
+	}
+}
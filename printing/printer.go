@@ -0,0 +1,55 @@
+// Package printing renders kitchen tickets and receipts as ESC/POS and sends
+// them to network printers through a queue that retries while a printer is
+// unreachable.
+package printing
+
+import (
+	"context"
+	"net"
+)
+
+// DefaultPort is the raw printing port, also known as JetDirect or
+// AppSocket, that network receipt printers listen on.
+const DefaultPort = "9100"
+
+// SendFunc delivers one job to the printer at address.
+type SendFunc func(ctx context.Context, address string, data []byte) error
+
+// Address adds the default port to a printer address that has none.
+func Address(address string) string {
+	if _, _, err := net.SplitHostPort(address); err == nil {
+		return address
+	}
+
+	return net.JoinHostPort(address, DefaultPort)
+}
+
+// Send writes data to the printer over raw TCP. The printer has no way to
+// say it printed; a job counts as done once the connection took all of it.
+// It sends to any address; the server sends through Networks.Send.
+func Send(ctx context.Context, address string, data []byte) error {
+	return send(ctx, net.Dialer{}, address, data)
+}
+
+// Send is Send for printers on the networks only.
+func (n Networks) Send(ctx context.Context, address string, data []byte) error {
+	return send(ctx, net.Dialer{Control: n.control}, address, data)
+}
+
+func send(ctx context.Context, dialer net.Dialer, address string, data []byte) error {
+	conn, err := dialer.DialContext(ctx, "tcp", Address(address))
+	if err != nil {
+		return err
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	if _, err := conn.Write(data); err != nil {
+		conn.Close()
+		return err
+	}
+
+	return conn.Close()
+}
@@ -0,0 +1,158 @@
+// Package printertest runs a fake network printer that keeps what it is sent,
+// for tests and local runs without a printer on the desk.
+package printertest
+
+import (
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// Server listens like a raw TCP printer. Every connection is one job; its
+// bytes are kept once the sender closes it. Like a printer it reads one job
+// at a time, in the order the connections came in.
+type Server struct {
+	addr string
+
+	mu       sync.Mutex
+	ln       net.Listener
+	jobs     [][]byte
+	received chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewServer starts a printer on a free local port. It panics when it can't
+// listen, like httptest.NewServer.
+func NewServer() *Server {
+	return NewServerAt("127.0.0.1:0")
+}
+
+// NewServerAt starts a printer on address, e.g. ":9100".
+func NewServerAt(address string) *Server {
+	ln, err := net.Listen("tcp", address)
+	if err != nil {
+		panic("printertest: failed to listen on " + address + ": " + err.Error())
+	}
+
+	s := &Server{addr: ln.Addr().String(), received: make(chan struct{}, 1)}
+	s.serve(ln)
+	return s
+}
+
+// Addr is the host:port to send jobs to.
+func (s *Server) Addr() string {
+	return s.addr
+}
+
+// Offline stops listening, so senders get their connection refused, as when
+// the printer is switched off.
+func (s *Server) Offline() {
+	s.mu.Lock()
+	ln := s.ln
+	s.ln = nil
+	s.mu.Unlock()
+
+	if ln != nil {
+		ln.Close()
+	}
+}
+
+// Online listens again on the same address after Offline.
+func (s *Server) Online() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ln != nil {
+		return nil
+	}
+
+	ln, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return err
+	}
+
+	s.serveLocked(ln)
+	return nil
+}
+
+// Jobs returns the jobs received so far, oldest first.
+func (s *Server) Jobs() [][]byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	jobs := make([][]byte, len(s.jobs))
+	for i, job := range s.jobs {
+		jobs[i] = append([]byte(nil), job...)
+	}
+
+	return jobs
+}
+
+// Wait blocks until at least n jobs came in or timeout passed, and returns
+// the jobs received by then.
+func (s *Server) Wait(n int, timeout time.Duration) [][]byte {
+	deadline := time.After(timeout)
+
+	for {
+		if jobs := s.Jobs(); len(jobs) >= n {
+			return jobs
+		}
+
+		select {
+		case <-s.received:
+		case <-deadline:
+			return s.Jobs()
+		}
+	}
+}
+
+// Close stops the server and waits for the connections being read.
+func (s *Server) Close() {
+	s.Offline()
+	s.wg.Wait()
+}
+
+func (s *Server) serve(ln net.Listener) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.serveLocked(ln)
+}
+
+func (s *Server) serveLocked(ln net.Listener) {
+	s.ln = ln
+	s.wg.Add(1)
+
+	go func() {
+		defer s.wg.Done()
+
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+
+			s.read(conn)
+		}
+	}()
+}
+
+func (s *Server) read(conn net.Conn) {
+	defer conn.Close()
+
+	conn.SetReadDeadline(time.Now().Add(time.Minute))
+	data, err := io.ReadAll(conn)
+	if err != nil || len(data) == 0 {
+		return
+	}
+
+	s.mu.Lock()
+	s.jobs = append(s.jobs, data)
+	s.mu.Unlock()
+
+	select {
+	case s.received <- struct{}{}:
+	default:
+	}
+}
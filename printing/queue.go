@@ -0,0 +1,317 @@
+package printing
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	JobQueued   = "QUEUED"
+	JobPrinting = "PRINTING"
+	JobPrinted  = "PRINTED"
+	JobFailed   = "FAILED"
+)
+
+const (
+	KindKitchen = "KITCHEN"
+	KindReceipt = "RECEIPT"
+	KindTest    = "TEST"
+)
+
+var (
+	ErrJobNotFound = errors.New("printing: job not found")
+	ErrNotFailed   = errors.New("printing: only failed jobs can be retried")
+)
+
+// maxBackoff caps the wait between two attempts.
+const maxBackoff = time.Minute
+
+// idleWorker is how long a printer's worker waits for a next job before it
+// stops; a new one is started with the next job.
+const idleWorker = time.Minute
+
+// history is how many jobs are kept for the job list; the oldest finished
+// ones go first.
+const history = 1000
+
+type Job struct {
+	Job_id        string     `json:"job_id"`
+	Kind          string     `json:"kind"`
+	Reference     string     `json:"reference,omitempty"`
+	Printer_id    string     `json:"printer_id"`
+	Printer_name  string     `json:"printer_name"`
+	Address       string     `json:"address"`
+	Status        string     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	Last_error    string     `json:"last_error,omitempty"`
+	Created_at    time.Time  `json:"created_at"`
+	Printed_at    *time.Time `json:"printed_at,omitempty"`
+	Restaurant_id string     `json:"restaurant_id"`
+	data          []byte
+}
+
+// Queue prints jobs one at a time per printer, in the order they came in, so
+// tickets don't overtake each other. A job that can't be delivered is tried
+// again with a growing pause until it ran out of attempts; it then stays in
+// the list as FAILED until it is retried by hand. Jobs only live in memory.
+type Queue struct {
+	send     SendFunc
+	attempts int
+	backoff  time.Duration
+	timeout  time.Duration
+	idle     time.Duration
+
+	mu     sync.Mutex
+	jobs   map[string]*Job
+	order  []string
+	lines  map[string]*line
+	done   chan struct{}
+	wg     sync.WaitGroup
+	closed bool
+}
+
+// line holds the jobs waiting for one printer.
+type line struct {
+	address string
+	pending []*Job
+	wake    chan struct{}
+}
+
+// NewQueue makes a queue that tries each job up to attempts times, waiting
+// backoff after the first failure and twice as long after every next one,
+// and gives a printer timeout to take a job.
+func NewQueue(send SendFunc, attempts int, backoff time.Duration, timeout time.Duration) *Queue {
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	return &Queue{
+		send:     send,
+		attempts: attempts,
+		backoff:  backoff,
+		timeout:  timeout,
+		idle:     idleWorker,
+		jobs:     map[string]*Job{},
+		lines:    map[string]*line{},
+		done:     make(chan struct{}),
+	}
+}
+
+// Submit queues data for the printer at job.Address and returns the job as
+// queued.
+func (q *Queue) Submit(job Job, data []byte) Job {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	job.Job_id = primitive.NewObjectID().Hex()
+	job.Address = Address(job.Address)
+	job.Status = JobQueued
+	job.Created_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	job.data = data
+
+	q.jobs[job.Job_id] = &job
+	q.order = append(q.order, job.Job_id)
+	q.trim()
+	q.enqueue(&job)
+
+	return job
+}
+
+// List returns the jobs of a restaurant, newest first.
+func (q *Queue) List(restaurantId string) []Job {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	jobs := []Job{}
+	for i := len(q.order) - 1; i >= 0; i-- {
+		if job := q.jobs[q.order[i]]; job.Restaurant_id == restaurantId {
+			jobs = append(jobs, *job)
+		}
+	}
+
+	return jobs
+}
+
+func (q *Queue) Get(restaurantId string, jobId string) (Job, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	job, ok := q.jobs[jobId]
+	if !ok || job.Restaurant_id != restaurantId {
+		return Job{}, false
+	}
+
+	return *job, true
+}
+
+// Retry queues a failed job again with a fresh set of attempts.
+func (q *Queue) Retry(restaurantId string, jobId string) (Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	job, ok := q.jobs[jobId]
+	if !ok || job.Restaurant_id != restaurantId {
+		return Job{}, ErrJobNotFound
+	}
+
+	if job.Status != JobFailed {
+		return *job, ErrNotFailed
+	}
+
+	job.Status = JobQueued
+	q.enqueue(job)
+
+	return *job, nil
+}
+
+// Close stops the workers. Jobs still waiting stay QUEUED; nothing is
+// printed after Close returns.
+func (q *Queue) Close() {
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return
+	}
+	q.closed = true
+	close(q.done)
+	q.mu.Unlock()
+
+	q.wg.Wait()
+}
+
+// enqueue puts the job at the end of its printer's line, starting a worker
+// for printers that have none. q.mu must be held.
+func (q *Queue) enqueue(job *Job) {
+	if q.closed {
+		return
+	}
+
+	l := q.lines[job.Address]
+	if l == nil {
+		l = &line{address: job.Address, wake: make(chan struct{}, 1)}
+		q.lines[job.Address] = l
+		q.wg.Add(1)
+		go q.work(l)
+	}
+
+	l.pending = append(l.pending, job)
+	select {
+	case l.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (q *Queue) work(l *line) {
+	defer q.wg.Done()
+
+	for {
+		q.mu.Lock()
+		if len(l.pending) == 0 {
+			q.mu.Unlock()
+
+			idle := time.NewTimer(q.idle)
+			select {
+			case <-l.wake:
+				idle.Stop()
+				continue
+			case <-idle.C:
+				if q.stopIdle(l) {
+					return
+				}
+				continue
+			case <-q.done:
+				idle.Stop()
+				return
+			}
+		}
+
+		job := l.pending[0]
+		l.pending = l.pending[1:]
+		job.Status = JobPrinting
+		data := job.data
+		q.mu.Unlock()
+
+		if !q.print(job, data) {
+			return
+		}
+	}
+}
+
+// stopIdle lets go of the line when no job came in meanwhile, so printers
+// that are gone or were only tried once don't keep a worker each.
+func (q *Queue) stopIdle(l *line) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(l.pending) > 0 {
+		return false
+	}
+
+	delete(q.lines, l.address)
+	return true
+}
+
+// print tries the job until it is printed or out of attempts. It returns
+// false when the queue was closed in between.
+func (q *Queue) print(job *Job, data []byte) bool {
+	backoff := q.backoff
+
+	for attempt := 1; ; attempt++ {
+		c, cancel := context.WithTimeout(context.Background(), q.timeout)
+		err := q.send(c, job.Address, data)
+		cancel()
+
+		q.mu.Lock()
+		job.Attempts++
+
+		if err == nil {
+			printedAt, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+			job.Status = JobPrinted
+			job.Printed_at = &printedAt
+			job.Last_error = ""
+			job.data = nil
+			q.mu.Unlock()
+			return true
+		}
+
+		job.Last_error = err.Error()
+		if attempt >= q.attempts {
+			job.Status = JobFailed
+			q.mu.Unlock()
+			return true
+		}
+		q.mu.Unlock()
+
+		select {
+		case <-time.After(backoff):
+		case <-q.done:
+			q.mu.Lock()
+			job.Status = JobQueued
+			q.mu.Unlock()
+			return false
+		}
+
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// trim forgets the oldest finished jobs beyond history. q.mu must be held.
+func (q *Queue) trim() {
+	for i := 0; len(q.order) > history && i < len(q.order); {
+		job := q.jobs[q.order[i]]
+		if job.Status != JobPrinted && job.Status != JobFailed {
+			i++
+			continue
+		}
+
+		delete(q.jobs, job.Job_id)
+		q.order = append(q.order[:i], q.order[i+1:]...)
+	}
+}
@@ -0,0 +1,194 @@
+package printing
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vikas-gouda/go-restraunt-mangement/printing/printertest"
+)
+
+// waitFor polls until done holds or a second passed.
+func waitFor(t *testing.T, what string, done func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for !done() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func status(q *Queue, jobId string) func() bool {
+	return func() bool {
+		job, _ := q.Get("r1", jobId)
+		return job.Status == JobPrinted || job.Status == JobFailed
+	}
+}
+
+func TestQueueSendsTheBytesToThePrinter(t *testing.T) {
+	printer := printertest.NewServer()
+	defer printer.Close()
+
+	q := NewQueue(Send, 3, 10*time.Millisecond, time.Second)
+	defer q.Close()
+
+	data := TestPage(testPrinter(printer.Addr()))
+	job := q.Submit(Job{Kind: KindTest, Address: printer.Addr(), Restaurant_id: "r1"}, data)
+
+	jobs := printer.Wait(1, time.Second)
+	if len(jobs) != 1 || !bytes.Equal(jobs[0], data) {
+		t.Fatalf("printer got %q, want %q", jobs, data)
+	}
+
+	waitFor(t, "the job to finish", status(q, job.Job_id))
+	if job, _ = q.Get("r1", job.Job_id); job.Status != JobPrinted || job.Attempts != 1 || job.Printed_at == nil {
+		t.Errorf("job = %+v, want PRINTED at the first attempt", job)
+	}
+
+	if _, ok := q.Get("r2", job.Job_id); ok {
+		t.Error("another restaurant can see the job")
+	}
+}
+
+func TestQueueRetriesWithGrowingPauses(t *testing.T) {
+	var mu sync.Mutex
+	var tries []time.Time
+	failing := func(ctx context.Context, address string, data []byte) error {
+		mu.Lock()
+		defer mu.Unlock()
+		tries = append(tries, time.Now())
+		return errors.New("connection refused")
+	}
+
+	backoff := 20 * time.Millisecond
+	q := NewQueue(failing, 4, backoff, time.Second)
+	defer q.Close()
+
+	job := q.Submit(Job{Kind: KindKitchen, Address: "printer.local", Restaurant_id: "r1"}, []byte("ticket"))
+	waitFor(t, "the job to fail", status(q, job.Job_id))
+
+	job, _ = q.Get("r1", job.Job_id)
+	if job.Status != JobFailed || job.Attempts != 4 || job.Last_error != "connection refused" {
+		t.Fatalf("job = %+v, want FAILED after 4 attempts", job)
+	}
+
+	if job.Address != "printer.local:9100" {
+		t.Errorf("address = %s, want the default port added", job.Address)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for i := 1; i < len(tries); i++ {
+		if gap, want := tries[i].Sub(tries[i-1]), backoff<<(i-1); gap < want {
+			t.Errorf("pause %d was %v, want at least %v", i, gap, want)
+		}
+	}
+}
+
+func TestQueueRetriesUntilThePrinterIsBack(t *testing.T) {
+	printer := printertest.NewServer()
+	defer printer.Close()
+	printer.Offline()
+
+	q := NewQueue(Send, 2, 10*time.Millisecond, time.Second)
+	defer q.Close()
+
+	job := q.Submit(Job{Kind: KindKitchen, Address: printer.Addr(), Restaurant_id: "r1"}, []byte("first try"))
+	waitFor(t, "the job to fail", status(q, job.Job_id))
+
+	if _, err := q.Retry("r1", job.Job_id); err != nil {
+		t.Fatal(err)
+	}
+
+	// Retrying a job that is not failed is refused.
+	if _, err := q.Retry("r1", job.Job_id); !errors.Is(err, ErrNotFailed) {
+		t.Errorf("second retry gave %v, want ErrNotFailed", err)
+	}
+
+	if err := printer.Online(); err != nil {
+		t.Fatal(err)
+	}
+
+	jobs := printer.Wait(1, time.Second)
+	if len(jobs) != 1 || string(jobs[0]) != "first try" {
+		t.Fatalf("printer got %q after the retry", jobs)
+	}
+
+	waitFor(t, "the retried job to print", status(q, job.Job_id))
+	if job, _ = q.Get("r1", job.Job_id); job.Status != JobPrinted {
+		t.Errorf("retried job is %s, want PRINTED", job.Status)
+	}
+}
+
+func TestQueueKeepsTheOrderPerPrinter(t *testing.T) {
+	grill := printertest.NewServer()
+	defer grill.Close()
+	bar := printertest.NewServer()
+	defer bar.Close()
+
+	// The bar printer is off; its jobs wait without holding up the grill.
+	bar.Offline()
+
+	q := NewQueue(Send, 100, 10*time.Millisecond, time.Second)
+	defer q.Close()
+
+	const n = 20
+	for i := 0; i < n; i++ {
+		q.Submit(Job{Kind: KindKitchen, Address: grill.Addr(), Restaurant_id: "r1"}, []byte(fmt.Sprintf("grill %02d", i)))
+		q.Submit(Job{Kind: KindKitchen, Address: bar.Addr(), Restaurant_id: "r1"}, []byte(fmt.Sprintf("bar %02d", i)))
+	}
+
+	assertInOrder := func(name string, printer *printertest.Server) {
+		t.Helper()
+
+		jobs := printer.Wait(n, 2*time.Second)
+		if len(jobs) != n {
+			t.Fatalf("%s printer got %d jobs, want %d", name, len(jobs), n)
+		}
+
+		for i, job := range jobs {
+			if want := fmt.Sprintf("%s %02d", name, i); string(job) != want {
+				t.Errorf("%s job %d is %q, want %q", name, i, job, want)
+			}
+		}
+	}
+
+	assertInOrder("grill", grill)
+
+	if err := bar.Online(); err != nil {
+		t.Fatal(err)
+	}
+	assertInOrder("bar", bar)
+}
+
+func TestQueueStopsIdleWorkers(t *testing.T) {
+	printer := printertest.NewServer()
+	defer printer.Close()
+
+	q := NewQueue(Send, 1, time.Millisecond, time.Second)
+	q.idle = 10 * time.Millisecond
+	defer q.Close()
+
+	workers := func() int {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		return len(q.lines)
+	}
+
+	for i := 1; i <= 2; i++ {
+		job := q.Submit(Job{Kind: KindTest, Address: printer.Addr(), Restaurant_id: "r1"}, []byte("page"))
+		waitFor(t, "the job to print", status(q, job.Job_id))
+		waitFor(t, "the worker to stop", func() bool { return workers() == 0 })
+	}
+
+	if jobs := printer.Wait(2, time.Second); len(jobs) != 2 {
+		t.Errorf("printer got %d jobs, want 2 with a worker started again", len(jobs))
+	}
+}
@@ -0,0 +1,116 @@
+package printing
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/vikas-gouda/go-restraunt-mangement/models"
+	"github.com/vikas-gouda/go-restraunt-mangement/printing/escpos"
+	"github.com/vikas-gouda/go-restraunt-mangement/receipt"
+)
+
+// DefaultWidth is used for printers that don't give theirs.
+const DefaultWidth = receipt.Wide
+
+// Kitchen renders the items of one order that go to one printer as a
+// kitchen ticket: table and order in large print, then every item with its
+// size and modifiers in double height so it reads from across the pass.
+func Kitchen(printer models.Printer, tickets []models.KitchenTicket) []byte {
+	width := lineWidth(printer)
+	rule := strings.Repeat("-", width)
+
+	buf := escpos.New()
+	buf.Align(escpos.Center).Bold(true).Line(strings.ToUpper(printer.Name)).Bold(false)
+
+	if len(tickets) > 0 {
+		first := tickets[0]
+
+		buf.Size(2, 2)
+		if first.Table_number != 0 {
+			buf.Line(fmt.Sprintf("TABLE %d", first.Table_number))
+		} else {
+			buf.Line("TAKEAWAY")
+		}
+		buf.Size(1, 1)
+
+		buf.Line("Order " + shortId(first.Order_id) + "  " + first.Created_at.Local().Format("15:04"))
+	}
+
+	buf.Align(escpos.Left).Line(rule)
+
+	for _, ticket := range tickets {
+		name := ticket.Food_name
+		if ticket.Size != "" {
+			name += " (" + ticket.Size + ")"
+		}
+
+		buf.Size(1, 2).Bold(true).Line(clip(fmt.Sprintf("%d x %s", ticket.Quantity, name), width)).Bold(false).Size(1, 1)
+		for _, modifier := range ticket.Modifiers {
+			buf.Line(clip("    + "+modifier.Name, width))
+		}
+	}
+
+	buf.Line(rule)
+	return buf.Feed(3).Cut().Beep(2, 2).Bytes()
+}
+
+// Receipt renders a receipt for the guest, with the QR code of the document
+// printed by the printer itself.
+func Receipt(printer models.Printer, doc receipt.Document) []byte {
+	qr := doc.QR
+	doc.QR = ""
+
+	buf := escpos.New()
+	for _, line := range strings.Split(strings.TrimRight(receipt.Text(doc, lineWidth(printer)), "\n"), "\n") {
+		buf.Line(line)
+	}
+
+	if qr != "" {
+		buf.Feed(1).Align(escpos.Center).QR(qr, 6).Align(escpos.Left)
+	}
+
+	return buf.Feed(3).Cut().Bytes()
+}
+
+// TestPage shows that a printer is reachable and set up with the right width.
+func TestPage(printer models.Printer) []byte {
+	width := lineWidth(printer)
+
+	buf := escpos.New()
+	buf.Align(escpos.Center).Size(2, 2).Line("TEST").Size(1, 1)
+	buf.Line(clip(printer.Name, width))
+	buf.Line(clip(printer.Address, width))
+	buf.Line(time.Now().Format("2006-01-02 15:04:05"))
+	buf.Align(escpos.Left).Line(strings.Repeat("-", width))
+	buf.Line(clip(fmt.Sprintf("%d columns", width), width))
+	buf.Line(strings.Repeat("0123456789", width/10+1)[:width])
+
+	return buf.Feed(3).Cut().Bytes()
+}
+
+func lineWidth(printer models.Printer) int {
+	if printer.Width == 0 {
+		return DefaultWidth
+	}
+
+	return printer.Width
+}
+
+// shortId keeps the end of an object id, which is the part that differs
+// between orders taken around the same time.
+func shortId(id string) string {
+	if len(id) <= 6 {
+		return id
+	}
+
+	return id[len(id)-6:]
+}
+
+func clip(text string, width int) string {
+	if runes := []rune(text); len(runes) > width {
+		return string(runes[:width])
+	}
+
+	return text
+}
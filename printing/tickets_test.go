@@ -0,0 +1,138 @@
+package printing
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/vikas-gouda/go-restraunt-mangement/models"
+	"github.com/vikas-gouda/go-restraunt-mangement/money"
+	"github.com/vikas-gouda/go-restraunt-mangement/printing/printertest"
+	"github.com/vikas-gouda/go-restraunt-mangement/receipt"
+)
+
+func testPrinter(address string) models.Printer {
+	return models.Printer{Name: "Grill", Address: address, Kind: models.PrinterKitchen, Width: receipt.Narrow}
+}
+
+// printed sends data through a queue to a fake printer and returns what the
+// printer got.
+func printed(t *testing.T, data []byte) []byte {
+	t.Helper()
+
+	printer := printertest.NewServer()
+	defer printer.Close()
+
+	q := NewQueue(Send, 1, time.Millisecond, time.Second)
+	defer q.Close()
+
+	q.Submit(Job{Kind: KindKitchen, Address: printer.Addr(), Restaurant_id: "r1"}, data)
+
+	jobs := printer.Wait(1, time.Second)
+	if len(jobs) != 1 {
+		t.Fatalf("printer got %d jobs, want 1", len(jobs))
+	}
+
+	return jobs[0]
+}
+
+func TestKitchenTicket(t *testing.T) {
+	tickets := []models.KitchenTicket{
+		{
+			Order_id:     "64f0c0ffee0000000a1b2c",
+			Table_number: 12,
+			Food_name:    "Burger",
+			Quantity:     2,
+			Size:         "L",
+			Modifiers:    []models.ChosenModifier{{Name: "Cheese"}},
+		},
+		{Order_id: "64f0c0ffee0000000a1b2c", Table_number: 12, Food_name: "Crème brûlée → to share", Quantity: 1},
+	}
+
+	got := printed(t, Kitchen(testPrinter("grill.local"), tickets))
+
+	for _, want := range []struct {
+		name  string
+		bytes []byte
+	}{
+		{"reset and code page", []byte("\x1b@\x1bt\x10")},
+		{"printer name in bold", []byte("\x1bE\x01GRILL\n\x1bE\x00")},
+		{"table in double size", []byte("\x1d!\x11TABLE 12\n\x1d!\x00")},
+		{"short order id", []byte("Order 0a1b2c")},
+		{"item in double height", []byte("\x1d!\x01\x1bE\x012 x Burger (L)\n\x1bE\x00\x1d!\x00")},
+		{"modifier", []byte("    + Cheese\n")},
+		{"Windows-1252 text", []byte("1 x Cr\xe8me br\xfbl\xe9e ? to share\n")},
+		{"rule", []byte("\x1ba\x00" + string(bytes.Repeat([]byte("-"), receipt.Narrow)) + "\n")},
+	} {
+		if !bytes.Contains(got, want.bytes) {
+			t.Errorf("ticket has no %s %q:\n%q", want.name, want.bytes, got)
+		}
+	}
+
+	if end := []byte("\x1bd\x03\x1dVB\x00\x1bB\x02\x02"); !bytes.HasSuffix(got, end) {
+		t.Errorf("ticket does not end with feed, cut and beep %q:\n%q", end, got)
+	}
+}
+
+func TestKitchenTicketWithoutTable(t *testing.T) {
+	got := Kitchen(testPrinter("grill.local"), []models.KitchenTicket{{Order_id: "abc", Food_name: "Fries", Quantity: 1}})
+
+	if !bytes.Contains(got, []byte("\x1d!\x11TAKEAWAY\n")) {
+		t.Errorf("ticket without a table is not marked takeaway:\n%q", got)
+	}
+}
+
+func TestReceiptPrintsTheQRCode(t *testing.T) {
+	printer := models.Printer{Name: "Front", Address: "front.local", Kind: models.PrinterReceipt}
+	doc := receipt.Document{
+		Restaurant: "Cafe",
+		Number:     "CAFE-2026-000001",
+		Lines:      []receipt.Line{{Quantity: 1, Name: "Soup", Amount: money.New(600, "USD")}},
+		Total:      money.New(600, "USD"),
+		QR:         "https://example.com/pay",
+	}
+
+	got := printed(t, Receipt(printer, doc))
+
+	qr := append([]byte{0x1d, '(', 'k', byte(len(doc.QR) + 3), 0, '1', 'P', '0'}, doc.QR...)
+	if !bytes.Contains(got, qr) {
+		t.Errorf("receipt does not store the QR data %q:\n%q", qr, got)
+	}
+
+	if !bytes.Contains(got, []byte("CAFE-2026-000001")) {
+		t.Errorf("receipt has no invoice number:\n%q", got)
+	}
+
+	if bytes.Count(got, []byte(doc.QR)) != 1 {
+		t.Error("the QR link is printed as text as well as a code")
+	}
+
+	if !bytes.HasSuffix(got, []byte("\x1bd\x03\x1dVB\x00")) {
+		t.Errorf("receipt does not end with feed and cut:\n%q", got)
+	}
+}
+
+func TestPrinterRouting(t *testing.T) {
+	grill := models.KitchenTicket{Station: "grill", Category: "Mains"}
+	bar := models.KitchenTicket{Station: "bar", Category: "Drinks"}
+
+	for _, test := range []struct {
+		name    string
+		printer models.Printer
+		grill   bool
+		bar     bool
+	}{
+		{"no rules takes everything", models.Printer{Kind: models.PrinterKitchen}, true, true},
+		{"by station", models.Printer{Kind: models.PrinterKitchen, Stations: []string{"bar"}}, false, true},
+		{"by category", models.Printer{Kind: models.PrinterKitchen, Categories: []string{" mains "}}, true, false},
+		{"disabled", models.Printer{Kind: models.PrinterKitchen, Disabled: true}, false, false},
+		{"receipt printer", models.Printer{Kind: models.PrinterReceipt}, false, false},
+	} {
+		if got := test.printer.Prints(grill); got != test.grill {
+			t.Errorf("%s: prints grill ticket = %v, want %v", test.name, got, test.grill)
+		}
+		if got := test.printer.Prints(bar); got != test.bar {
+			t.Errorf("%s: prints bar ticket = %v, want %v", test.name, got, test.bar)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package receipt
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jung-kurt/gofpdf"
+	"github.com/skip2/go-qrcode"
+)
+
+const (
+	pageMargin = 15.0
+	lineHeight = 6.0
+	qrSize     = 35.0
+)
+
+// PDF writes the document as an A4 invoice. The core fonts only cover
+// Latin-1; other characters come out as question marks.
+func PDF(w io.Writer, doc Document) error {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(pageMargin, pageMargin, pageMargin)
+	pdf.SetAutoPageBreak(true, pageMargin)
+	pdf.SetTitle(doc.Title+" "+doc.Number, true)
+	pdf.SetCreator(doc.Restaurant, true)
+	pdf.AddPage()
+
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pageWidth, pageHeight := pdf.GetPageSize()
+	width := pageWidth - 2*pageMargin
+	amountWidth := 35.0
+
+	pdf.SetFont("Helvetica", "B", 18)
+	pdf.CellFormat(width, 9, tr(doc.Restaurant), "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	for _, line := range []string{doc.Address, doc.Phone} {
+		if line != "" {
+			pdf.CellFormat(width, 5, tr(line), "", 1, "L", false, 0, "")
+		}
+	}
+	pdf.Ln(4)
+
+	pdf.SetFont("Helvetica", "B", 14)
+	pdf.CellFormat(width/2, 8, tr(doc.Title), "", 0, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.CellFormat(width/2, 8, tr(doc.Number), "", 1, "R", false, 0, "")
+
+	details := doc.Date.Format("2 Jan 2006 15:04")
+	switch {
+	case doc.Table_number != 0 && doc.Seat != 0:
+		details = fmt.Sprintf("Table %d, seat %d  |  %s", doc.Table_number, doc.Seat, details)
+	case doc.Table_number != 0:
+		details = fmt.Sprintf("Table %d  |  %s", doc.Table_number, details)
+	}
+	pdf.CellFormat(width, 5, tr(details), "", 1, "L", false, 0, "")
+	pdf.Ln(4)
+
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.SetFillColor(235, 235, 235)
+	pdf.CellFormat(15, lineHeight+1, "Qty", "B", 0, "L", true, 0, "")
+	pdf.CellFormat(width-15-amountWidth, lineHeight+1, "Item", "B", 0, "L", true, 0, "")
+	pdf.CellFormat(amountWidth, lineHeight+1, "Amount", "B", 1, "R", true, 0, "")
+
+	pdf.SetFont("Helvetica", "", 10)
+	for _, line := range doc.Lines {
+		pdf.CellFormat(15, lineHeight, fmt.Sprint(line.Quantity), "", 0, "L", false, 0, "")
+		pdf.CellFormat(width-15-amountWidth, lineHeight, tr(line.Name), "", 0, "L", false, 0, "")
+		pdf.CellFormat(amountWidth, lineHeight, line.Amount.Decimal(), "", 1, "R", false, 0, "")
+
+		if len(line.Modifiers) > 0 {
+			pdf.SetFont("Helvetica", "I", 8)
+			pdf.SetX(pageMargin + 15)
+			pdf.MultiCell(width-15-amountWidth, 4, tr(strings.Join(line.Modifiers, ", ")), "", "L", false)
+			pdf.SetFont("Helvetica", "", 10)
+		}
+	}
+	pdf.Line(pageMargin, pdf.GetY()+1, pageMargin+width, pdf.GetY()+1)
+	pdf.Ln(3)
+
+	total := func(label string, amount string, bold bool) {
+		style := ""
+		if bold {
+			style = "B"
+		}
+		pdf.SetFont("Helvetica", style, 10)
+		pdf.CellFormat(width-amountWidth, lineHeight, tr(label), "", 0, "R", false, 0, "")
+		pdf.CellFormat(amountWidth, lineHeight, amount, "", 1, "R", false, 0, "")
+	}
+
+	total("Items", doc.Items_total.Decimal(), false)
+	for _, adjustment := range doc.Adjustments {
+		total(adjustment.Label, adjustment.Amount.Decimal(), false)
+	}
+	total("Total "+doc.Total.Currency, doc.Total.Decimal(), true)
+	if !doc.Due.IsZero() {
+		total("Your share", doc.Due.Decimal(), true)
+	}
+
+	if len(doc.Payments) > 0 {
+		pdf.Ln(3)
+		for _, payment := range doc.Payments {
+			total(payment.Tender, payment.Amount.Decimal(), false)
+			if !payment.Tip.IsZero() {
+				total("Tip", payment.Tip.Decimal(), false)
+			}
+			if !payment.Change.IsZero() {
+				total("Change", payment.Change.Decimal(), false)
+			}
+		}
+		total("Paid", doc.Paid.Decimal(), false)
+		if !doc.Credited.IsZero() {
+			total("Refunded", doc.Credited.Decimal(), false)
+		}
+		total("Balance", doc.Balance.Decimal(), true)
+	}
+
+	if doc.Status != "" {
+		pdf.Ln(2)
+		pdf.SetFont("Helvetica", "B", 12)
+		pdf.CellFormat(width, 8, tr(doc.Status), "", 1, "R", false, 0, "")
+	}
+
+	if doc.QR != "" {
+		png, err := qrcode.Encode(doc.QR, qrcode.Medium, 256)
+		if err != nil {
+			return err
+		}
+
+		pdf.Ln(4)
+		if pdf.GetY()+qrSize > pageHeight-pageMargin {
+			pdf.AddPage()
+		}
+
+		options := gofpdf.ImageOptions{ImageType: "PNG"}
+		pdf.RegisterImageOptionsReader("qr", options, bytes.NewReader(png))
+		pdf.ImageOptions("qr", pageMargin+(width-qrSize)/2, pdf.GetY(), qrSize, qrSize, true, options, 0, "")
+	}
+
+	if doc.Footer != "" {
+		pdf.Ln(4)
+		pdf.SetFont("Helvetica", "", 9)
+		pdf.MultiCell(width, 5, tr(doc.Footer), "", "C", false)
+	}
+
+	return pdf.Output(w)
+}
@@ -0,0 +1,172 @@
+// Package receipt lays out invoices for customers: as a PDF and as plain
+// text for 40 or 48 column receipt printers. It knows nothing about the
+// store; callers fill in a Document.
+package receipt
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/vikas-gouda/go-restraunt-mangement/money"
+)
+
+// Widths of the common receipt printers, in characters, for 58 and 80 mm
+// paper.
+const (
+	Narrow = 40
+	Wide   = 48
+)
+
+type Document struct {
+	Restaurant   string
+	Address      string
+	Phone        string
+	Title        string
+	Number       string
+	Table_number int
+	Seat         int
+	Date         time.Time
+	Lines        []Line
+	Items_total  money.Money
+	// Adjustments come between the items and the total: discounts, taxes
+	// and service charges, in that order.
+	Adjustments []Amount
+	Total       money.Money
+	// Due is what this check owes when it is an even share of the order;
+	// zero when it owes the whole total.
+	Due      money.Money
+	Payments []Payment
+	Credited money.Money
+	Paid     money.Money
+	Balance  money.Money
+	Status   string
+	// QR is encoded as a QR code under the totals, e.g. a link to pay or
+	// leave feedback. Empty leaves it out.
+	QR     string
+	Footer string
+}
+
+type Line struct {
+	Quantity  int
+	Name      string
+	Modifiers []string
+	Amount    money.Money
+}
+
+type Amount struct {
+	Label  string
+	Amount money.Money
+}
+
+type Payment struct {
+	Tender string
+	Amount money.Money
+	Tip    money.Money
+	Change money.Money
+}
+
+// Text lays the document out in width columns. Widths below Narrow are
+// taken as Narrow.
+func Text(doc Document, width int) string {
+	if width < Narrow {
+		width = Narrow
+	}
+
+	var b strings.Builder
+	rule := strings.Repeat("-", width)
+
+	for _, line := range []string{doc.Restaurant, doc.Address, doc.Phone} {
+		if line != "" {
+			b.WriteString(center(line, width) + "\n")
+		}
+	}
+	b.WriteString(rule + "\n")
+
+	b.WriteString(row(doc.Title, doc.Number, width) + "\n")
+	table := ""
+	if doc.Table_number != 0 {
+		table = fmt.Sprintf("Table %d", doc.Table_number)
+		if doc.Seat != 0 {
+			table += fmt.Sprintf(" seat %d", doc.Seat)
+		}
+	}
+	b.WriteString(row(table, doc.Date.Format("2006-01-02 15:04"), width) + "\n")
+	b.WriteString(rule + "\n")
+
+	for _, line := range doc.Lines {
+		b.WriteString(row(fmt.Sprintf("%d x %s", line.Quantity, line.Name), line.Amount.Decimal(), width) + "\n")
+		for _, modifier := range line.Modifiers {
+			b.WriteString(clip("    + "+modifier, width) + "\n")
+		}
+	}
+	b.WriteString(rule + "\n")
+
+	b.WriteString(row("Items", doc.Items_total.Decimal(), width) + "\n")
+	for _, adjustment := range doc.Adjustments {
+		b.WriteString(row(adjustment.Label, adjustment.Amount.Decimal(), width) + "\n")
+	}
+	b.WriteString(row("TOTAL "+doc.Total.Currency, doc.Total.Decimal(), width) + "\n")
+	if !doc.Due.IsZero() {
+		b.WriteString(row("Your share", doc.Due.Decimal(), width) + "\n")
+	}
+
+	if len(doc.Payments) > 0 {
+		b.WriteString(rule + "\n")
+		for _, payment := range doc.Payments {
+			b.WriteString(row(payment.Tender, payment.Amount.Decimal(), width) + "\n")
+			if !payment.Tip.IsZero() {
+				b.WriteString(row("  Tip", payment.Tip.Decimal(), width) + "\n")
+			}
+			if !payment.Change.IsZero() {
+				b.WriteString(row("  Change", payment.Change.Decimal(), width) + "\n")
+			}
+		}
+		b.WriteString(row("Paid", doc.Paid.Decimal(), width) + "\n")
+		if !doc.Credited.IsZero() {
+			b.WriteString(row("Refunded", doc.Credited.Decimal(), width) + "\n")
+		}
+		b.WriteString(row("Balance", doc.Balance.Decimal(), width) + "\n")
+	}
+
+	if doc.Status != "" {
+		b.WriteString(rule + "\n")
+		b.WriteString(center(doc.Status, width) + "\n")
+	}
+
+	if doc.Footer != "" {
+		b.WriteString("\n")
+		for _, line := range strings.Split(doc.Footer, "\n") {
+			b.WriteString(center(line, width) + "\n")
+		}
+	}
+
+	return b.String()
+}
+
+// row puts left and right on one line, cutting left short when they don't
+// fit.
+func row(left string, right string, width int) string {
+	room := width - utf8.RuneCountInString(right) - 1
+	left = clip(left, room)
+
+	return left + strings.Repeat(" ", width-utf8.RuneCountInString(left)-utf8.RuneCountInString(right)) + right
+}
+
+func center(text string, width int) string {
+	text = clip(text, width)
+	return strings.Repeat(" ", (width-utf8.RuneCountInString(text))/2) + text
+}
+
+func clip(text string, width int) string {
+	if width < 0 {
+		width = 0
+	}
+
+	if utf8.RuneCountInString(text) <= width {
+		return text
+	}
+
+	return string([]rune(text)[:width])
+}
@@ -3,11 +3,12 @@ package routes
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/vikas-gouda/go-restraunt-mangement/controller"
+	"github.com/vikas-gouda/go-restraunt-mangement/store"
 )
 
-func FoodRoutes(incomingRoutes *gin.Engine) {
-	incomingRoutes.GET("/foods", controller.GetFoods())
-	incomingRoutes.GET("/foods/:food_id", controller.GetFood())
-	incomingRoutes.POST("/foods", controller.CreateFood())
-	incomingRoutes.PATCH("/foods/:food_id", controller.UpdateFood())
+func FoodRoutes(incomingRoutes *gin.Engine, s *store.Store) {
+	incomingRoutes.GET("/foods", allow(anyStaff), controller.GetFoods(s))
+	incomingRoutes.GET("/foods/:food_id", allow(anyStaff), controller.GetFood(s))
+	incomingRoutes.POST("/foods", allow(managers), controller.CreateFood(s))
+	incomingRoutes.PATCH("/foods/:food_id", allow(managers), controller.UpdateFood(s))
 }
@@ -0,0 +1,13 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/vikas-gouda/go-restraunt-mangement/controller"
+	"github.com/vikas-gouda/go-restraunt-mangement/store"
+)
+
+func HealthRoutes(incomingRoutes *gin.Engine, s *store.Store) {
+	incomingRoutes.GET("/healthz", controller.Healthz())
+	incomingRoutes.GET("/readyz", controller.Readyz(s))
+	incomingRoutes.GET("/version", controller.Version())
+}
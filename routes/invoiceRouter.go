@@ -3,11 +3,33 @@ package routes
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/vikas-gouda/go-restraunt-mangement/controller"
+	"github.com/vikas-gouda/go-restraunt-mangement/gateway"
+	"github.com/vikas-gouda/go-restraunt-mangement/store"
 )
 
-func InvoiceRoutes(incomingRoutes *gin.Engine) {
-	incomingRoutes.GET("/invoices", controller.GetInvoices())
-	incomingRoutes.GET("/invoices/:invoice_id", controller.GetInvoice())
-	incomingRoutes.POST("/invoices", controller.CreateInvoice())
-	incomingRoutes.PATCH("/invoices/:invoices_id", controller.UpdateInvoice())
+func InvoiceRoutes(incomingRoutes *gin.Engine, s *store.Store, provider gateway.Provider) {
+	incomingRoutes.GET("/invoices", allow(floorStaff), controller.GetInvoices(s))
+	incomingRoutes.GET("/invoices/:invoice_id", allow(floorStaff), controller.GetInvoice(s))
+	incomingRoutes.POST("/invoices", allow(floorStaff), controller.CreateInvoice(s))
+	incomingRoutes.PATCH("/invoices/:invoice_id", allow(cashiers), controller.UpdateInvoice(s))
+	incomingRoutes.POST("/invoices/:invoice_id/void", allow(cashiers), controller.VoidInvoice(s))
+	incomingRoutes.GET("/invoices/:invoice_id/pdf", allow(floorStaff), controller.GetInvoicePDF(s))
+	incomingRoutes.GET("/invoices/:invoice_id/receipt", allow(floorStaff), controller.GetInvoiceReceipt(s))
+	incomingRoutes.GET("/invoices/:invoice_id/payments", allow(floorStaff), controller.GetInvoicePayments(s))
+	incomingRoutes.POST("/invoices/:invoice_id/payments", allow(cashiers), controller.CreatePayment(s, provider))
+	incomingRoutes.POST("/payments/:payment_id/refunds", allow(cashiers), controller.RefundPayment(s, provider))
+	incomingRoutes.GET("/invoices/:invoice_id/creditNotes", allow(floorStaff), controller.GetInvoiceCreditNotes(s))
+	incomingRoutes.GET("/creditNotes/:credit_note_id", allow(floorStaff), controller.GetCreditNote(s))
+	incomingRoutes.GET("/orders/:order_id/invoices", allow(floorStaff), controller.GetOrderInvoices(s))
+	incomingRoutes.POST("/orders/:order_id/split", allow(floorStaff), controller.SplitOrder(s))
+	incomingRoutes.POST("/orders/:order_id/merge", allow(floorStaff), controller.MergeInvoices(s))
+}
+
+// WebhookRoutes go before authentication; providers sign their calls instead.
+func WebhookRoutes(incomingRoutes *gin.Engine, s *store.Store, provider gateway.Provider) {
+	if provider == nil {
+		return
+	}
+
+	incomingRoutes.POST("/webhooks/payments/"+provider.Name(), controller.PaymentWebhook(s, provider))
 }
@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/vikas-gouda/go-restraunt-mangement/controller"
+	"github.com/vikas-gouda/go-restraunt-mangement/events"
+	"github.com/vikas-gouda/go-restraunt-mangement/middleware"
+	"github.com/vikas-gouda/go-restraunt-mangement/store"
+)
+
+// KitchenRoutes authenticate on their own, the feeds also take a stream token
+// in the URL or a cookie.
+func KitchenRoutes(incomingRoutes *gin.Engine, s *store.Store, broker *events.Broker) {
+	incomingRoutes.POST("/kitchen/token", middleware.Authentication(s.Sessions), allow(orderStaff), controller.KitchenToken())
+	incomingRoutes.GET("/kitchen/events", middleware.StreamAuthentication(s.Sessions), allow(orderStaff), controller.KitchenEvents(broker))
+	incomingRoutes.GET("/kitchen/ws", middleware.StreamAuthentication(s.Sessions), allow(orderStaff), controller.KitchenSocket(broker))
+}
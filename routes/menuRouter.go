@@ -3,11 +3,12 @@ package routes
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/vikas-gouda/go-restraunt-mangement/controller"
+	"github.com/vikas-gouda/go-restraunt-mangement/store"
 )
 
-func MenuRoutes(incomingRoutes *gin.Engine) {
-	incomingRoutes.GET("/menus", controller.GetMenus())
-	incomingRoutes.GET("/menus/:menu_id", controller.GetMenu())
-	incomingRoutes.POST("/menus", controller.CreateMenu())
-	incomingRoutes.PATCH("/menus/:menu_id", controller.UpdateMenu())
+func MenuRoutes(incomingRoutes *gin.Engine, s *store.Store) {
+	incomingRoutes.GET("/menus", allow(anyStaff), controller.GetMenus(s))
+	incomingRoutes.GET("/menus/:menu_id", allow(anyStaff), controller.GetMenu(s))
+	incomingRoutes.POST("/menus", allow(managers), controller.CreateMenu(s))
+	incomingRoutes.PATCH("/menus/:menu_id", allow(managers), controller.UpdateMenu(s))
 }
@@ -3,12 +3,17 @@ package routes
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/vikas-gouda/go-restraunt-mangement/controller"
+	"github.com/vikas-gouda/go-restraunt-mangement/events"
+	"github.com/vikas-gouda/go-restraunt-mangement/printing"
+	"github.com/vikas-gouda/go-restraunt-mangement/store"
 )
 
-func OrderItemRoutes(incomingRoutes *gin.Engine) {
-	incomingRoutes.GET("/orderItems", controller.GetOrderItems())
-	incomingRoutes.GET("orderItems/:orderItems_id", controller.GetOrderItem())
-	incomingRoutes.GET("/orderItems-order/:order_id", controller.GetOrderItemsByOrder())
-	incomingRoutes.POST("/orderItems", controller.CreateOrderItem())
-	incomingRoutes.PATCH("/orderItems/:orderItem_id", controller.UpdateOrderItem())
+func OrderItemRoutes(incomingRoutes *gin.Engine, s *store.Store, broker *events.Broker, queue *printing.Queue) {
+	incomingRoutes.GET("/orderItems", allow(anyStaff), controller.GetOrderItems(s))
+	incomingRoutes.GET("/orderItems/:order_item_id", allow(anyStaff), controller.GetOrderItem(s))
+	incomingRoutes.GET("/orderItems-order/:order_id", allow(anyStaff), controller.GetOrderItemsByOrder(s))
+	incomingRoutes.POST("/orderItems", allow(floorStaff), controller.CreateOrderItem(s, broker, queue))
+	incomingRoutes.PATCH("/orderItems/:order_item_id", allow(orderStaff), controller.UpdateOrderItem(s))
+	incomingRoutes.POST("/orderItems/:order_item_id/bump", allow(orderStaff), controller.BumpOrderItem(s, broker))
+	incomingRoutes.POST("/orderItems/:order_item_id/void", allow(floorStaff), controller.VoidOrderItem(s, broker))
 }
@@ -3,11 +3,14 @@ package routes
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/vikas-gouda/go-restraunt-mangement/controller"
+	"github.com/vikas-gouda/go-restraunt-mangement/events"
+	"github.com/vikas-gouda/go-restraunt-mangement/store"
 )
 
-func OrderRoutes(incomingRoutes *gin.Engine) {
-	incomingRoutes.GET("/orders", controller.GetOrders())
-	incomingRoutes.GET("orders/:order_id", controller.GetOrder())
-	incomingRoutes.POST("/orders", controller.CreateOrder())
-	incomingRoutes.PATCH("/orders/:order_id", controller.UpdateOrder())
+func OrderRoutes(incomingRoutes *gin.Engine, s *store.Store, broker *events.Broker) {
+	incomingRoutes.GET("/orders", allow(anyStaff), controller.GetOrders(s))
+	incomingRoutes.GET("orders/:order_id", allow(anyStaff), controller.GetOrder(s))
+	incomingRoutes.POST("/orders", allow(floorStaff), controller.CreateOrder(s))
+	incomingRoutes.PATCH("/orders/:order_id", allow(floorStaff), controller.UpdateOrder(s))
+	incomingRoutes.POST("/orders/:order_id/transitions", allow(anyStaff), controller.TransitionOrder(s, broker))
 }
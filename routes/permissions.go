@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/vikas-gouda/go-restraunt-mangement/middleware"
+	"github.com/vikas-gouda/go-restraunt-mangement/models"
+)
+
+// Owners pass every check, so they are not listed in the groups below.
+var (
+	anyStaff   = []string{models.RoleManager, models.RoleCashier, models.RoleWaiter, models.RoleKitchen}
+	managers   = []string{models.RoleManager}
+	cashiers   = []string{models.RoleCashier}
+	floorStaff = []string{models.RoleManager, models.RoleCashier, models.RoleWaiter}
+	orderStaff = []string{models.RoleManager, models.RoleWaiter, models.RoleKitchen}
+	owners     = []string{}
+)
+
+func allow(roles []string) gin.HandlerFunc {
+	return middleware.Authorization(roles...)
+}
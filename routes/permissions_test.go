@@ -0,0 +1,244 @@
+package routes_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vikas-gouda/go-restraunt-mangement/app"
+	"github.com/vikas-gouda/go-restraunt-mangement/config"
+	"github.com/vikas-gouda/go-restraunt-mangement/helpers"
+	"github.com/vikas-gouda/go-restraunt-mangement/models"
+	"github.com/vikas-gouda/go-restraunt-mangement/money"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var roles = []string{models.RoleOwner, models.RoleManager, models.RoleCashier, models.RoleWaiter, models.RoleKitchen}
+
+// testServer is the whole app on the in-memory store with a signed in user
+// of every role in one restaurant.
+type testServer struct {
+	t          *testing.T
+	app        *app.App
+	restaurant models.Restaurant
+	tokens     map[string]string
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	cfg := config.Default()
+	cfg.Store = config.StoreMemory
+	cfg.Secret_key = "test-secret"
+
+	a, err := app.New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		a.Events.Close()
+		a.Printing.Close()
+	})
+
+	s := &testServer{t: t, app: a, tokens: map[string]string{}}
+
+	s.restaurant = models.Restaurant{ID: primitive.NewObjectID(), Name: "Cafe", Code: "CAFE", Currency: "USD"}
+	s.restaurant.Restaurant_id = s.restaurant.ID.Hex()
+	if err := a.Store.Restaurants.Create(context.Background(), s.restaurant); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, role := range roles {
+		s.tokens[role], _ = s.signIn(role)
+	}
+
+	return s
+}
+
+// signIn stores a user of role with an open session and returns its tokens.
+func (s *testServer) signIn(role string) (string, string) {
+	s.t.Helper()
+
+	id := primitive.NewObjectID()
+	user := models.User{
+		ID:            id,
+		First_name:    role,
+		Email:         id.Hex() + "@example.com",
+		Phone:         id.Hex(),
+		Role:          role,
+		User_id:       id.Hex(),
+		Restaurant_id: s.restaurant.Restaurant_id,
+	}
+	if err := s.app.Store.Users.Create(context.Background(), user); err != nil {
+		s.t.Fatal(err)
+	}
+
+	sessionId := helpers.NewSessionId()
+	token, refreshToken, err := helpers.GenerateAllTokens(user.Email, user.First_name, "", user.User_id, role, user.Restaurant_id, sessionId)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+
+	if err := s.app.Store.Sessions.Create(context.Background(), helpers.NewSession(sessionId, user.User_id, refreshToken, "test", "127.0.0.1")); err != nil {
+		s.t.Fatal(err)
+	}
+
+	return token, refreshToken
+}
+
+func (s *testServer) do(role string, method string, path string, body interface{}) *httptest.ResponseRecorder {
+	s.t.Helper()
+
+	var payload bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&payload).Encode(body); err != nil {
+			s.t.Fatal(err)
+		}
+	}
+
+	req := httptest.NewRequest(method, path, &payload)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("token", s.tokens[role])
+
+	w := httptest.NewRecorder()
+	s.app.Router.ServeHTTP(w, req)
+	return w
+}
+
+// invoice has the owner make out an invoice for a new order.
+func (s *testServer) invoice() string {
+	s.t.Helper()
+
+	food := models.Food{ID: primitive.NewObjectID(), Name: "Soup", Price: money.New(600, "USD"), Food_image: "https://example.com/soup.png", Restaurant_id: s.restaurant.Restaurant_id}
+	food.Food_id = food.ID.Hex()
+	table := models.Table{ID: primitive.NewObjectID(), Number_of_guests: 2, Table_number: 1, Restaurant_id: s.restaurant.Restaurant_id}
+	table.Table_id = table.ID.Hex()
+	if err := s.app.Store.Foods.Create(context.Background(), food); err != nil {
+		s.t.Fatal(err)
+	}
+	if err := s.app.Store.Tables.Create(context.Background(), table); err != nil {
+		s.t.Fatal(err)
+	}
+
+	var created struct {
+		Order models.Order `json:"order"`
+	}
+	w := s.do(models.RoleOwner, http.MethodPost, "/orderItems", gin.H{
+		"table_id":   table.Table_id,
+		"oder_items": []gin.H{{"food_id": food.Food_id, "quantity": 1}},
+	})
+	if w.Code != http.StatusOK || json.Unmarshal(w.Body.Bytes(), &created) != nil {
+		s.t.Fatalf("ordering: %d %s", w.Code, w.Body.String())
+	}
+
+	var invoice models.Invoice
+	w = s.do(models.RoleOwner, http.MethodPost, "/invoices", gin.H{"order_id": created.Order.Order_id})
+	if w.Code != http.StatusOK || json.Unmarshal(w.Body.Bytes(), &invoice) != nil {
+		s.t.Fatalf("invoicing: %d %s", w.Code, w.Body.String())
+	}
+
+	return invoice.Invoice_id
+}
+
+// TestRoleGroups calls one route of every role group as every role. Roles
+// outside the group are turned away with 403; the others, and owners always,
+// get through to a successful response.
+func TestRoleGroups(t *testing.T) {
+	s := newTestServer(t)
+	restaurants := 0
+
+	for _, group := range []struct {
+		name    string
+		allowed []string
+		method  string
+		request func() (string, interface{})
+	}{
+		{
+			name:    "anyStaff",
+			allowed: roles,
+			method:  http.MethodGet,
+			request: func() (string, interface{}) { return "/foods", nil },
+		},
+		{
+			name:    "managers",
+			allowed: []string{models.RoleOwner, models.RoleManager},
+			method:  http.MethodGet,
+			request: func() (string, interface{}) { return "/shifts", nil },
+		},
+		{
+			name:    "cashiers",
+			allowed: []string{models.RoleOwner, models.RoleCashier},
+			method:  http.MethodPost,
+			request: func() (string, interface{}) {
+				return "/invoices/" + s.invoice() + "/void", gin.H{"reason": "wrong table"}
+			},
+		},
+		{
+			name:    "floorStaff",
+			allowed: []string{models.RoleOwner, models.RoleManager, models.RoleCashier, models.RoleWaiter},
+			method:  http.MethodGet,
+			request: func() (string, interface{}) { return "/invoices", nil },
+		},
+		{
+			name:    "orderStaff",
+			allowed: []string{models.RoleOwner, models.RoleManager, models.RoleWaiter, models.RoleKitchen},
+			method:  http.MethodPost,
+			request: func() (string, interface{}) { return "/kitchen/token", nil },
+		},
+		{
+			name:    "owners",
+			allowed: []string{models.RoleOwner},
+			method:  http.MethodPost,
+			request: func() (string, interface{}) {
+				restaurants++
+				return "/restaurants", gin.H{"name": "Branch", "code": fmt.Sprintf("BR%d", restaurants)}
+			},
+		},
+	} {
+		for _, role := range roles {
+			path, body := group.request()
+			w := s.do(role, group.method, path, body)
+
+			if allowed(group.allowed, role) {
+				if w.Code < 200 || w.Code > 299 {
+					t.Errorf("%s: %s %s as %s got %d, want success: %s", group.name, group.method, path, role, w.Code, w.Body.String())
+				}
+				continue
+			}
+
+			if w.Code != http.StatusForbidden {
+				t.Errorf("%s: %s %s as %s got %d, want 403: %s", group.name, group.method, path, role, w.Code, w.Body.String())
+			}
+		}
+	}
+}
+
+func TestSignedOutUserIsTurnedAway(t *testing.T) {
+	s := newTestServer(t)
+	s.tokens[models.RoleOwner] = ""
+
+	if w := s.do(models.RoleOwner, http.MethodGet, "/foods", nil); w.Code == http.StatusOK {
+		t.Errorf("a request without a token got %d", w.Code)
+	}
+
+	s.tokens[models.RoleOwner] = "not-a-token"
+	if w := s.do(models.RoleOwner, http.MethodGet, "/foods", nil); w.Code == http.StatusOK {
+		t.Errorf("a request with a bad token got %d", w.Code)
+	}
+}
+
+func allowed(roles []string, role string) bool {
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+
+	return false
+}
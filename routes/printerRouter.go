@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/vikas-gouda/go-restraunt-mangement/controller"
+	"github.com/vikas-gouda/go-restraunt-mangement/printing"
+	"github.com/vikas-gouda/go-restraunt-mangement/store"
+)
+
+func PrinterRoutes(incomingRoutes *gin.Engine, s *store.Store, queue *printing.Queue, networks printing.Networks) {
+	incomingRoutes.GET("/printers", allow(anyStaff), controller.GetPrinters(s))
+	incomingRoutes.GET("/printers/:printer_id", allow(anyStaff), controller.GetPrinter(s))
+	incomingRoutes.POST("/printers", allow(managers), controller.CreatePrinter(s, networks))
+	incomingRoutes.PATCH("/printers/:printer_id", allow(managers), controller.UpdatePrinter(s, networks))
+	incomingRoutes.POST("/printers/:printer_id/test", allow(managers), controller.TestPrinter(s, queue))
+	incomingRoutes.GET("/printJobs", allow(anyStaff), controller.GetPrintJobs(queue))
+	incomingRoutes.GET("/printJobs/:job_id", allow(anyStaff), controller.GetPrintJob(queue))
+	incomingRoutes.POST("/printJobs/:job_id/retry", allow(anyStaff), controller.RetryPrintJob(queue))
+	incomingRoutes.POST("/invoices/:invoice_id/print", allow(floorStaff), controller.PrintInvoice(s, queue))
+}
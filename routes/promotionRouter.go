@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/vikas-gouda/go-restraunt-mangement/controller"
+	"github.com/vikas-gouda/go-restraunt-mangement/store"
+)
+
+func PromotionRoutes(incomingRoutes *gin.Engine, s *store.Store) {
+	incomingRoutes.GET("/promotions", allow(floorStaff), controller.GetPromotions(s))
+	incomingRoutes.GET("/promotions/:promotion_id", allow(floorStaff), controller.GetPromotion(s))
+	incomingRoutes.POST("/promotions", allow(managers), controller.CreatePromotion(s))
+	incomingRoutes.PATCH("/promotions/:promotion_id", allow(managers), controller.UpdatePromotion(s))
+	incomingRoutes.POST("/orders/:order_id/discounts", allow(floorStaff), controller.ApplyDiscount(s))
+	incomingRoutes.DELETE("/orders/:order_id/discounts/:discount_id", allow(floorStaff), controller.RemoveDiscount(s))
+}
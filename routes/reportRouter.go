@@ -0,0 +1,12 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/vikas-gouda/go-restraunt-mangement/controller"
+	"github.com/vikas-gouda/go-restraunt-mangement/store"
+)
+
+func ReportRoutes(incomingRoutes *gin.Engine, s *store.Store) {
+	incomingRoutes.GET("/reports/sales", allow(managers), controller.GetSalesReport(s))
+	incomingRoutes.GET("/reports/tips", allow(managers), controller.GetTipReport(s))
+}
@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/vikas-gouda/go-restraunt-mangement/controller"
+	"github.com/vikas-gouda/go-restraunt-mangement/store"
+)
+
+func RestaurantRoutes(incomingRoutes *gin.Engine, s *store.Store) {
+	incomingRoutes.GET("/restaurants", allow(anyStaff), controller.GetRestaurants(s))
+	incomingRoutes.GET("/restaurants/:restaurant_id", allow(anyStaff), controller.GetRestaurant(s))
+	incomingRoutes.POST("/restaurants", allow(owners), controller.CreateRestaurant(s))
+	incomingRoutes.PATCH("/restaurants/:restaurant_id", allow(owners), controller.UpdateRestaurant(s))
+}
@@ -0,0 +1,142 @@
+package routes_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vikas-gouda/go-restraunt-mangement/helpers"
+	"github.com/vikas-gouda/go-restraunt-mangement/models"
+)
+
+// refresh exchanges a refresh token and returns the new tokens on success.
+func (s *testServer) refresh(refreshToken string) (int, string, string) {
+	s.t.Helper()
+
+	w := s.do("", http.MethodPost, "/users/refresh", gin.H{"refresh_token": refreshToken})
+
+	var tokens struct {
+		Token         string `json:"token"`
+		Refresh_token string `json:"refresh_token"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &tokens)
+
+	return w.Code, tokens.Token, tokens.Refresh_token
+}
+
+func TestRefreshTokenRotation(t *testing.T) {
+	s := newTestServer(t)
+	token, first := s.signIn(models.RoleWaiter)
+
+	status, _, second := s.refresh(first)
+	if status != http.StatusOK || second == first {
+		t.Fatalf("first refresh got %d, a new refresh token %v", status, second != first)
+	}
+
+	status, current, third := s.refresh(second)
+	if status != http.StatusOK || third == second {
+		t.Fatalf("second refresh got %d, a new refresh token %v", status, third != second)
+	}
+
+	for _, test := range []struct {
+		name    string
+		refresh string
+		status  int
+	}{
+		{"access token", token, http.StatusUnauthorized},
+		{"garbage", "not-a-token", http.StatusUnauthorized},
+		{"reused refresh token", second, http.StatusUnauthorized},
+		// The reuse took the session down; the current token goes with it.
+		{"current refresh token after a reuse", third, http.StatusUnauthorized},
+	} {
+		if status, _, _ := s.refresh(test.refresh); status != test.status {
+			t.Errorf("%s: got %d, want %d", test.name, status, test.status)
+		}
+	}
+
+	s.tokens[models.RoleWaiter] = current
+	if w := s.do(models.RoleWaiter, http.MethodGet, "/foods", nil); w.Code != http.StatusUnauthorized {
+		t.Errorf("access token of a revoked session got %d, want 401", w.Code)
+	}
+}
+
+func TestSessionRevocation(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		// revoke is done as by, on the session of a waiter.
+		by      string
+		revoke  func(s *testServer, waiter models.User, sessionId string) (string, string)
+		status  int
+		revoked bool
+	}{
+		{
+			name:    "logout",
+			by:      models.RoleWaiter,
+			revoke:  func(*testServer, models.User, string) (string, string) { return http.MethodPost, "/users/logout" },
+			status:  http.StatusOK,
+			revoked: true,
+		},
+		{
+			name: "own session",
+			by:   models.RoleWaiter,
+			revoke: func(_ *testServer, waiter models.User, sessionId string) (string, string) {
+				return http.MethodDelete, "/users/" + waiter.User_id + "/sessions/" + sessionId
+			},
+			status:  http.StatusOK,
+			revoked: true,
+		},
+		{
+			name: "all sessions by a manager",
+			by:   models.RoleManager,
+			revoke: func(_ *testServer, waiter models.User, _ string) (string, string) {
+				return http.MethodDelete, "/users/" + waiter.User_id + "/sessions"
+			},
+			status:  http.StatusOK,
+			revoked: true,
+		},
+		{
+			name: "another waiter's sessions",
+			by:   models.RoleCashier,
+			revoke: func(_ *testServer, waiter models.User, _ string) (string, string) {
+				return http.MethodDelete, "/users/" + waiter.User_id + "/sessions"
+			},
+			status: http.StatusForbidden,
+		},
+		{
+			name: "unknown session",
+			by:   models.RoleWaiter,
+			revoke: func(_ *testServer, waiter models.User, _ string) (string, string) {
+				return http.MethodDelete, "/users/" + waiter.User_id + "/sessions/" + helpers.NewSessionId()
+			},
+			status: http.StatusNotFound,
+		},
+	} {
+		s := newTestServer(t)
+		token, refreshToken := s.signIn(models.RoleWaiter)
+
+		claims, msg := helpers.ValidateToken(token)
+		if msg != "" {
+			t.Fatal(msg)
+		}
+		waiter := models.User{User_id: claims.Uid}
+
+		if test.by == models.RoleWaiter {
+			s.tokens[models.RoleWaiter] = token
+		}
+
+		method, path := test.revoke(s, waiter, claims.Id)
+		if w := s.do(test.by, method, path, nil); w.Code != test.status {
+			t.Errorf("%s: got %d, want %d: %s", test.name, w.Code, test.status, w.Body.String())
+			continue
+		}
+
+		s.tokens[models.RoleWaiter] = token
+		w := s.do(models.RoleWaiter, http.MethodGet, "/foods", nil)
+		status, _, _ := s.refresh(refreshToken)
+
+		if revoked := w.Code == http.StatusUnauthorized && status == http.StatusUnauthorized; revoked != test.revoked {
+			t.Errorf("%s: access got %d and refresh %d, want revoked %v", test.name, w.Code, status, test.revoked)
+		}
+	}
+}
@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/vikas-gouda/go-restraunt-mangement/controller"
+	"github.com/vikas-gouda/go-restraunt-mangement/store"
+)
+
+func ShiftRoutes(incomingRoutes *gin.Engine, s *store.Store) {
+	incomingRoutes.GET("/shifts", allow(managers), controller.GetShifts(s))
+	incomingRoutes.POST("/shifts/clockIn", allow(anyStaff), controller.ClockIn(s))
+	incomingRoutes.POST("/shifts/clockOut", allow(anyStaff), controller.ClockOut(s))
+	incomingRoutes.PATCH("/shifts/:shift_id", allow(managers), controller.UpdateShift(s))
+}
@@ -3,11 +3,12 @@ package routes
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/vikas-gouda/go-restraunt-mangement/controller"
+	"github.com/vikas-gouda/go-restraunt-mangement/store"
 )
 
-func TableRoutes(incomingRoutes *gin.Engine) {
-	incomingRoutes.GET("/tables", controller.GetTables())
-	incomingRoutes.GET("tables/:table_id", controller.GetTable())
-	incomingRoutes.POST("/tables", controller.CreateTable())
-	incomingRoutes.PATCH("/tables/:table_id", controller.UpdateTable())
+func TableRoutes(incomingRoutes *gin.Engine, s *store.Store) {
+	incomingRoutes.GET("/tables", allow(anyStaff), controller.GetTables(s))
+	incomingRoutes.GET("tables/:table_id", allow(anyStaff), controller.GetTable(s))
+	incomingRoutes.POST("/tables", allow(managers), controller.CreateTable(s))
+	incomingRoutes.PATCH("/tables/:table_id", allow(managers), controller.UpdateTable(s))
 }
@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/vikas-gouda/go-restraunt-mangement/controller"
+	"github.com/vikas-gouda/go-restraunt-mangement/store"
+)
+
+func TaxRoutes(incomingRoutes *gin.Engine, s *store.Store) {
+	incomingRoutes.GET("/taxRates", allow(anyStaff), controller.GetTaxRates(s))
+	incomingRoutes.GET("/taxRates/:tax_rate_id", allow(anyStaff), controller.GetTaxRate(s))
+	incomingRoutes.POST("/taxRates", allow(managers), controller.CreateTaxRate(s))
+	incomingRoutes.PATCH("/taxRates/:tax_rate_id", allow(managers), controller.UpdateTaxRate(s))
+}
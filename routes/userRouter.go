@@ -3,11 +3,21 @@ package routes
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/vikas-gouda/go-restraunt-mangement/controller"
+	"github.com/vikas-gouda/go-restraunt-mangement/middleware"
+	"github.com/vikas-gouda/go-restraunt-mangement/store"
 )
 
-func UserRoutes(incomingRoutes *gin.Engine) {
-	incomingRoutes.GET("/users", controller.GetUsers())
-	incomingRoutes.GET("/users/:user_id", controller.GetUser())
-	incomingRoutes.POST("/users/signup", controller.SignUp())
-	incomingRoutes.POST("/users/login", controller.Login())
+func UserRoutes(incomingRoutes *gin.Engine, s *store.Store) {
+	incomingRoutes.GET("/users", middleware.Authentication(s.Sessions), allow(anyStaff), controller.GetUsers(s))
+	incomingRoutes.GET("/users/:user_id", middleware.Authentication(s.Sessions), allow(anyStaff), controller.GetUser(s))
+	incomingRoutes.POST("/users", middleware.Authentication(s.Sessions), allow(managers), controller.CreateUser(s))
+	incomingRoutes.POST("/users/signup", controller.SignUp(s))
+	incomingRoutes.POST("/users/login", controller.Login(s))
+	incomingRoutes.POST("/users/refresh", controller.RefreshToken(s))
+	incomingRoutes.POST("/users/logout", middleware.Authentication(s.Sessions), controller.Logout(s))
+	incomingRoutes.GET("/users/:user_id/sessions", middleware.Authentication(s.Sessions), allow(anyStaff), controller.GetUserSessions(s))
+	incomingRoutes.DELETE("/users/:user_id/sessions", middleware.Authentication(s.Sessions), allow(anyStaff), controller.RevokeUserSessions(s))
+	incomingRoutes.DELETE("/users/:user_id/sessions/:session_id", middleware.Authentication(s.Sessions), allow(anyStaff), controller.RevokeUserSession(s))
+	incomingRoutes.PATCH("/users/:user_id/role", middleware.Authentication(s.Sessions), allow(owners), controller.UpdateUserRole(s))
+	incomingRoutes.PATCH("/users/:user_id/restaurant", middleware.Authentication(s.Sessions), allow(owners), controller.UpdateUserRestaurant(s))
 }
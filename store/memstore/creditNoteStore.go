@@ -0,0 +1,35 @@
+package memstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/vikas-gouda/go-restraunt-mangement/models"
+)
+
+type creditNoteStore struct {
+	docs collection[models.CreditNote]
+}
+
+func (s *creditNoteStore) List(ctx context.Context, restaurantId string, from time.Time, to time.Time) ([]models.CreditNote, error) {
+	return s.docs.find(func(creditNote models.CreditNote) bool {
+		return creditNote.Restaurant_id == restaurantId && !creditNote.Created_at.Before(from) && creditNote.Created_at.Before(to)
+	}), nil
+}
+
+func (s *creditNoteStore) ListByInvoice(ctx context.Context, restaurantId string, invoiceId string) ([]models.CreditNote, error) {
+	return s.docs.find(func(creditNote models.CreditNote) bool {
+		return creditNote.Invoice_id == invoiceId && creditNote.Restaurant_id == restaurantId
+	}), nil
+}
+
+func (s *creditNoteStore) Get(ctx context.Context, restaurantId string, creditNoteId string) (models.CreditNote, error) {
+	return s.docs.findOne(func(creditNote models.CreditNote) bool {
+		return creditNote.Credit_note_id == creditNoteId && creditNote.Restaurant_id == restaurantId
+	})
+}
+
+func (s *creditNoteStore) Create(ctx context.Context, creditNote models.CreditNote) error {
+	s.docs.insert(creditNote)
+	return nil
+}
@@ -0,0 +1,34 @@
+package memstore
+
+import (
+	"context"
+
+	"github.com/vikas-gouda/go-restraunt-mangement/models"
+)
+
+type foodStore struct {
+	docs collection[models.Food]
+}
+
+func (s *foodStore) List(ctx context.Context, restaurantId string, start int, limit int) (int64, []models.Food, error) {
+	foods := s.docs.find(func(food models.Food) bool { return food.Restaurant_id == restaurantId })
+
+	return int64(len(foods)), page(foods, start, limit), nil
+}
+
+func (s *foodStore) Get(ctx context.Context, restaurantId string, foodId string) (models.Food, error) {
+	return s.docs.findOne(func(food models.Food) bool {
+		return food.Food_id == foodId && food.Restaurant_id == restaurantId
+	})
+}
+
+func (s *foodStore) Create(ctx context.Context, food models.Food) error {
+	s.docs.insert(food)
+	return nil
+}
+
+func (s *foodStore) Update(ctx context.Context, food models.Food) error {
+	return s.docs.replace(func(doc models.Food) bool {
+		return doc.Food_id == food.Food_id && doc.Restaurant_id == food.Restaurant_id
+	}, food)
+}
@@ -0,0 +1,36 @@
+package memstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/vikas-gouda/go-restraunt-mangement/models"
+)
+
+type idempotencyStore struct {
+	docs collection[models.IdempotencyRecord]
+}
+
+func (s *idempotencyStore) Reserve(ctx context.Context, record models.IdempotencyRecord) (models.IdempotencyRecord, bool, error) {
+	now := time.Now()
+	s.docs.delete(func(doc models.IdempotencyRecord) bool { return !doc.Expires_at.After(now) })
+
+	existing, reserved := s.docs.insertUnless(func(doc models.IdempotencyRecord) bool {
+		return doc.Restaurant_id == record.Restaurant_id && doc.Key == record.Key
+	}, record)
+
+	return existing, reserved, nil
+}
+
+func (s *idempotencyStore) Complete(ctx context.Context, record models.IdempotencyRecord) error {
+	return s.docs.replace(func(doc models.IdempotencyRecord) bool {
+		return doc.Restaurant_id == record.Restaurant_id && doc.Key == record.Key
+	}, record)
+}
+
+func (s *idempotencyStore) Release(ctx context.Context, restaurantId string, key string) error {
+	s.docs.delete(func(doc models.IdempotencyRecord) bool {
+		return doc.Restaurant_id == restaurantId && doc.Key == key
+	})
+	return nil
+}
@@ -0,0 +1,184 @@
+package memstore
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/vikas-gouda/go-restraunt-mangement/models"
+	"github.com/vikas-gouda/go-restraunt-mangement/money"
+	"github.com/vikas-gouda/go-restraunt-mangement/store"
+)
+
+type invoiceStore struct {
+	docs   collection[models.Invoice]
+	orders *orderStore
+	// mu makes Replace one step; nothing else writes several invoices.
+	mu sync.Mutex
+	// counters holds the last invoice number of every restaurant and series.
+	counters map[string]int64
+}
+
+func (s *invoiceStore) List(ctx context.Context, restaurantId string) ([]models.Invoice, error) {
+	return s.docs.find(func(invoice models.Invoice) bool { return invoice.Restaurant_id == restaurantId }), nil
+}
+
+func (s *invoiceStore) ListByOrder(ctx context.Context, restaurantId string, orderId string) ([]models.Invoice, error) {
+	return s.docs.find(func(invoice models.Invoice) bool {
+		return invoice.Order_id != nil && *invoice.Order_id == orderId && invoice.Restaurant_id == restaurantId
+	}), nil
+}
+
+func (s *invoiceStore) Get(ctx context.Context, restaurantId string, invoiceId string) (models.Invoice, error) {
+	return s.docs.findOne(func(invoice models.Invoice) bool {
+		return invoice.Invoice_id == invoiceId && invoice.Restaurant_id == restaurantId
+	})
+}
+
+func (s *invoiceStore) Create(ctx context.Context, invoice models.Invoice) error {
+	s.docs.insert(invoice)
+	return nil
+}
+
+func (s *invoiceStore) Update(ctx context.Context, invoice models.Invoice) error {
+	return s.docs.replace(func(doc models.Invoice) bool {
+		return doc.Invoice_id == invoice.Invoice_id && doc.Restaurant_id == invoice.Restaurant_id
+	}, invoice)
+}
+
+func (s *invoiceStore) Replace(ctx context.Context, restaurantId string, orderId string, version int, voidIds []string, at time.Time, invoices []models.Invoice, numbering models.InvoiceNumbering) ([]models.Invoice, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	voiding := map[string]bool{}
+	for _, id := range voidIds {
+		voiding[id] = true
+	}
+
+	open := s.docs.count(func(invoice models.Invoice) bool {
+		return voiding[invoice.Invoice_id] && invoice.Restaurant_id == restaurantId &&
+			!invoice.Voided() && !invoice.Settled()
+	})
+	if open != int64(len(voiding)) {
+		return nil, false, nil
+	}
+
+	bumped := s.orders.docs.update(func(order models.Order) bool {
+		return order.Order_id == orderId && order.Restaurant_id == restaurantId
+	}, func(order *models.Order) bool {
+		if order.Billing_version != version {
+			return false
+		}
+
+		order.Billing_version++
+		return true
+	})
+	if !bumped {
+		return nil, false, nil
+	}
+
+	for id := range voiding {
+		s.docs.update(func(invoice models.Invoice) bool {
+			return invoice.Invoice_id == id && invoice.Restaurant_id == restaurantId
+		}, func(invoice *models.Invoice) bool {
+			invoice.Voided_at = &at
+			invoice.Updated_at = at
+			return true
+		})
+	}
+
+	if s.counters == nil {
+		s.counters = map[string]int64{}
+	}
+
+	key := restaurantId + "/" + numbering.Series
+	numbered := make([]models.Invoice, len(invoices))
+	for i, invoice := range invoices {
+		s.counters[key]++
+		invoice.Sequence = s.counters[key]
+		invoice.Fiscal_year = numbering.Series
+		invoice.Invoice_number = numbering.Number(invoice.Sequence)
+		numbered[i] = invoice
+	}
+
+	s.docs.insert(numbered...)
+	return numbered, true, nil
+}
+
+func (s *invoiceStore) Void(ctx context.Context, restaurantId string, invoiceId string, at time.Time, reason string, voidedBy string) (models.Invoice, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var voided models.Invoice
+
+	found := s.docs.update(func(invoice models.Invoice) bool {
+		return invoice.Invoice_id == invoiceId && invoice.Restaurant_id == restaurantId
+	}, func(invoice *models.Invoice) bool {
+		if invoice.Voided() || invoice.Settled() {
+			voided = *invoice
+			return false
+		}
+
+		invoice.Voided_at = &at
+		invoice.Void_reason = reason
+		invoice.Voided_by = voidedBy
+		invoice.Updated_at = at
+		voided = *invoice
+		return true
+	})
+
+	return voided, found, nil
+}
+
+func (s *invoiceStore) AddPaid(ctx context.Context, restaurantId string, invoiceId string, amount money.Money) (models.Invoice, bool, error) {
+	var updated models.Invoice
+
+	added := s.docs.update(func(invoice models.Invoice) bool {
+		return invoice.Invoice_id == invoiceId && invoice.Restaurant_id == restaurantId
+	}, func(invoice *models.Invoice) bool {
+		if invoice.Voided() {
+			return false
+		}
+
+		invoice.Paid = invoice.Paid.Add(amount)
+		updated = *invoice
+		return true
+	})
+
+	return updated, added, nil
+}
+
+func (s *invoiceStore) AddRefund(ctx context.Context, restaurantId string, invoiceId string, refunded money.Money, credited money.Money) (models.Invoice, error) {
+	var updated models.Invoice
+
+	found := s.docs.update(func(invoice models.Invoice) bool {
+		return invoice.Invoice_id == invoiceId && invoice.Restaurant_id == restaurantId
+	}, func(invoice *models.Invoice) bool {
+		invoice.Paid = invoice.Paid.Sub(refunded)
+		invoice.Credited = invoice.Credited.Add(credited)
+		updated = *invoice
+		return true
+	})
+
+	if !found {
+		return updated, store.ErrNotFound
+	}
+
+	return updated, nil
+}
+
+func (s *invoiceStore) SetPaymentStatus(ctx context.Context, restaurantId string, invoiceId string, paid money.Money, status string, method string) error {
+	s.docs.update(func(invoice models.Invoice) bool {
+		return invoice.Invoice_id == invoiceId && invoice.Restaurant_id == restaurantId
+	}, func(invoice *models.Invoice) bool {
+		if invoice.Paid.Amount != paid.Amount {
+			return false
+		}
+
+		invoice.Payment_status = status
+		invoice.Payment_method = method
+		return true
+	})
+
+	return nil
+}
@@ -0,0 +1,192 @@
+// Package memstore implements the store interfaces in process memory. It is
+// safe for concurrent use and meant for local runs and handler tests; nothing
+// survives a restart.
+package memstore
+
+import (
+	"sync"
+
+	"github.com/vikas-gouda/go-restraunt-mangement/store"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func New() *store.Store {
+	items := &orderItemStore{}
+	orders := &orderStore{items: items}
+
+	return &store.Store{
+		Restaurants: &restaurantStore{},
+		Users:       &userStore{},
+		Sessions:    &sessionStore{},
+		Foods:       &foodStore{},
+		Menus:       &menuStore{},
+		Tables:      &tableStore{},
+		Orders:      orders,
+		OrderItems:  items,
+		Invoices:    &invoiceStore{orders: orders},
+		TaxRates:    &taxRateStore{},
+		Promotions:  &promotionStore{},
+		Payments:    &paymentStore{},
+		CreditNotes: &creditNoteStore{},
+		Shifts:      &shiftStore{},
+		Printers:    &printerStore{},
+		Health:      healthStore{},
+		Idempotency: &idempotencyStore{},
+	}
+}
+
+// collection keeps documents in insertion order. Every document is copied on
+// the way in and out so callers never share memory with the store.
+type collection[T any] struct {
+	mu   sync.RWMutex
+	docs []T
+}
+
+func (c *collection[T]) find(match func(T) bool) []T {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	docs := []T{}
+	for _, doc := range c.docs {
+		if match(doc) {
+			docs = append(docs, clone(doc))
+		}
+	}
+
+	return docs
+}
+
+func (c *collection[T]) findOne(match func(T) bool) (T, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, doc := range c.docs {
+		if match(doc) {
+			return clone(doc), nil
+		}
+	}
+
+	var zero T
+	return zero, store.ErrNotFound
+}
+
+func (c *collection[T]) count(match func(T) bool) int64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var count int64
+	for _, doc := range c.docs {
+		if match(doc) {
+			count++
+		}
+	}
+
+	return count
+}
+
+func (c *collection[T]) insert(docs ...T) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, doc := range docs {
+		c.docs = append(c.docs, clone(doc))
+	}
+}
+
+// insertUnless adds doc when nothing matches yet, the way a unique index
+// would. Otherwise it returns the document already there.
+func (c *collection[T]) insertUnless(match func(T) bool, doc T) (T, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, existing := range c.docs {
+		if match(existing) {
+			return clone(existing), false
+		}
+	}
+
+	c.docs = append(c.docs, clone(doc))
+	return doc, true
+}
+
+func (c *collection[T]) replace(match func(T) bool, doc T) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i := range c.docs {
+		if match(c.docs[i]) {
+			c.docs[i] = clone(doc)
+			return nil
+		}
+	}
+
+	return store.ErrNotFound
+}
+
+// update applies fn to the first matching document under the write lock; fn
+// returning false leaves the document untouched.
+func (c *collection[T]) update(match func(T) bool, fn func(*T) bool) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i := range c.docs {
+		if match(c.docs[i]) {
+			doc := clone(c.docs[i])
+			if !fn(&doc) {
+				return false
+			}
+			c.docs[i] = doc
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c *collection[T]) delete(match func(T) bool) int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var deleted int64
+	kept := c.docs[:0]
+	for _, doc := range c.docs {
+		if match(doc) {
+			deleted++
+			continue
+		}
+		kept = append(kept, doc)
+	}
+	c.docs = kept
+
+	return deleted
+}
+
+// clone deep copies a document by round tripping it through BSON, which is
+// exactly what a trip through MongoDB would do to it as well.
+func clone[T any](doc T) T {
+	var copied T
+
+	raw, err := bson.Marshal(doc)
+	if err != nil {
+		panic(err)
+	}
+
+	if err := bson.Unmarshal(raw, &copied); err != nil {
+		panic(err)
+	}
+
+	return copied
+}
+
+func page[T any](docs []T, start int, limit int) []T {
+	if start > len(docs) {
+		start = len(docs)
+	}
+
+	end := start + limit
+	if end > len(docs) {
+		end = len(docs)
+	}
+
+	return docs[start:end]
+}
@@ -0,0 +1,28 @@
+package memstore_test
+
+import (
+	"testing"
+
+	"github.com/vikas-gouda/go-restraunt-mangement/store/memstore"
+	"github.com/vikas-gouda/go-restraunt-mangement/store/storetest"
+)
+
+func TestIdempotency(t *testing.T) {
+	storetest.Idempotency(t, memstore.New().Idempotency)
+}
+
+func TestFirstUser(t *testing.T) {
+	storetest.FirstUser(t, memstore.New().Users)
+}
+
+func TestBillingVersion(t *testing.T) {
+	storetest.BillingVersion(t, memstore.New())
+}
+
+func TestInvoiceNumbering(t *testing.T) {
+	storetest.InvoiceNumbering(t, memstore.New())
+}
+
+func TestSessions(t *testing.T) {
+	storetest.Sessions(t, memstore.New().Sessions)
+}
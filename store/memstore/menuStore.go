@@ -0,0 +1,32 @@
+package memstore
+
+import (
+	"context"
+
+	"github.com/vikas-gouda/go-restraunt-mangement/models"
+)
+
+type menuStore struct {
+	docs collection[models.Menu]
+}
+
+func (s *menuStore) List(ctx context.Context, restaurantId string) ([]models.Menu, error) {
+	return s.docs.find(func(menu models.Menu) bool { return menu.Restaurant_id == restaurantId }), nil
+}
+
+func (s *menuStore) Get(ctx context.Context, restaurantId string, menuId string) (models.Menu, error) {
+	return s.docs.findOne(func(menu models.Menu) bool {
+		return menu.Menu_id == menuId && menu.Restaurant_id == restaurantId
+	})
+}
+
+func (s *menuStore) Create(ctx context.Context, menu models.Menu) error {
+	s.docs.insert(menu)
+	return nil
+}
+
+func (s *menuStore) Update(ctx context.Context, menu models.Menu) error {
+	return s.docs.replace(func(doc models.Menu) bool {
+		return doc.Menu_id == menu.Menu_id && doc.Restaurant_id == menu.Restaurant_id
+	}, menu)
+}
@@ -0,0 +1,55 @@
+package memstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/vikas-gouda/go-restraunt-mangement/models"
+)
+
+type orderItemStore struct {
+	docs collection[models.OrderItem]
+}
+
+func (s *orderItemStore) List(ctx context.Context, restaurantId string) ([]models.OrderItem, error) {
+	return s.docs.find(func(orderItem models.OrderItem) bool { return orderItem.Restaurant_id == restaurantId }), nil
+}
+
+func (s *orderItemStore) ListByOrder(ctx context.Context, restaurantId string, orderId string) ([]models.OrderItem, error) {
+	return s.docs.find(func(orderItem models.OrderItem) bool {
+		return orderItem.Order_id != nil && *orderItem.Order_id == orderId && orderItem.Restaurant_id == restaurantId
+	}), nil
+}
+
+func (s *orderItemStore) Get(ctx context.Context, restaurantId string, orderItemId string) (models.OrderItem, error) {
+	return s.docs.findOne(func(orderItem models.OrderItem) bool {
+		return orderItem.Order_item_id == orderItemId && orderItem.Restaurant_id == restaurantId
+	})
+}
+
+func (s *orderItemStore) CreateMany(ctx context.Context, orderItems []models.OrderItem) error {
+	s.docs.insert(orderItems...)
+	return nil
+}
+
+func (s *orderItemStore) Update(ctx context.Context, orderItem models.OrderItem) error {
+	return s.docs.replace(func(doc models.OrderItem) bool {
+		return doc.Order_item_id == orderItem.Order_item_id && doc.Restaurant_id == orderItem.Restaurant_id
+	}, orderItem)
+}
+
+func (s *orderItemStore) Transition(ctx context.Context, restaurantId string, orderItemId string, from string, to string, at time.Time) (bool, error) {
+	moved := s.docs.update(func(orderItem models.OrderItem) bool {
+		return orderItem.Order_item_id == orderItemId && orderItem.Restaurant_id == restaurantId
+	}, func(orderItem *models.OrderItem) bool {
+		if orderItem.CurrentStatus() != from {
+			return false
+		}
+
+		models.StampItemStatus(orderItem, to, at)
+		orderItem.Updated_at = at
+		return true
+	})
+
+	return moved, nil
+}
@@ -0,0 +1,102 @@
+package memstore
+
+import (
+	"context"
+
+	"github.com/vikas-gouda/go-restraunt-mangement/models"
+)
+
+type orderStore struct {
+	docs  collection[models.Order]
+	items *orderItemStore
+}
+
+func (s *orderStore) List(ctx context.Context, restaurantId string) ([]models.Order, error) {
+	return s.docs.find(func(order models.Order) bool { return order.Restaurant_id == restaurantId }), nil
+}
+
+func (s *orderStore) Get(ctx context.Context, restaurantId string, orderId string) (models.Order, error) {
+	return s.docs.findOne(func(order models.Order) bool {
+		return order.Order_id == orderId && order.Restaurant_id == restaurantId
+	})
+}
+
+func (s *orderStore) Create(ctx context.Context, order models.Order) error {
+	s.docs.insert(order)
+	return nil
+}
+
+// CreateWithItems can't fail half way here; the items go in first so that
+// readers never see the order without them.
+func (s *orderStore) CreateWithItems(ctx context.Context, order models.Order, orderItems []models.OrderItem) error {
+	s.items.docs.insert(orderItems...)
+	s.docs.insert(order)
+	return nil
+}
+
+func (s *orderStore) Update(ctx context.Context, order models.Order) error {
+	return s.docs.replace(func(doc models.Order) bool {
+		return doc.Order_id == order.Order_id && doc.Restaurant_id == order.Restaurant_id
+	}, order)
+}
+
+func (s *orderStore) Transition(ctx context.Context, restaurantId string, orderId string, transition models.OrderTransition) (bool, error) {
+	moved := s.docs.update(func(order models.Order) bool {
+		return order.Order_id == orderId && order.Restaurant_id == restaurantId
+	}, func(order *models.Order) bool {
+		if order.CurrentStatus() != transition.From {
+			return false
+		}
+
+		order.Status = transition.To
+		order.Updated_at = transition.At
+		order.Status_history = append(order.Status_history, transition)
+		return true
+	})
+
+	return moved, nil
+}
+
+func (s *orderStore) AddDiscount(ctx context.Context, restaurantId string, orderId string, version int, discount models.OrderDiscount) (bool, error) {
+	added := s.docs.update(func(order models.Order) bool {
+		return order.Order_id == orderId && order.Restaurant_id == restaurantId
+	}, func(order *models.Order) bool {
+		if order.Closed() || order.Billing_version != version {
+			return false
+		}
+
+		for _, existing := range order.Discounts {
+			if discount.Promotion_id != "" && existing.Promotion_id == discount.Promotion_id {
+				return false
+			}
+		}
+
+		order.Discounts = append(order.Discounts, discount)
+		order.Billing_version++
+		return true
+	})
+
+	return added, nil
+}
+
+func (s *orderStore) RemoveDiscount(ctx context.Context, restaurantId string, orderId string, version int, discountId string) (bool, error) {
+	removed := s.docs.update(func(order models.Order) bool {
+		return order.Order_id == orderId && order.Restaurant_id == restaurantId
+	}, func(order *models.Order) bool {
+		if order.Closed() || order.Billing_version != version {
+			return false
+		}
+
+		for i, discount := range order.Discounts {
+			if discount.Discount_id == discountId {
+				order.Discounts = append(order.Discounts[:i], order.Discounts[i+1:]...)
+				order.Billing_version++
+				return true
+			}
+		}
+
+		return false
+	})
+
+	return removed, nil
+}
@@ -0,0 +1,77 @@
+package memstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/vikas-gouda/go-restraunt-mangement/models"
+	"github.com/vikas-gouda/go-restraunt-mangement/money"
+	"github.com/vikas-gouda/go-restraunt-mangement/store"
+)
+
+type paymentStore struct {
+	docs collection[models.Payment]
+}
+
+func (s *paymentStore) List(ctx context.Context, restaurantId string) ([]models.Payment, error) {
+	return s.docs.find(func(payment models.Payment) bool { return payment.Restaurant_id == restaurantId }), nil
+}
+
+func (s *paymentStore) ListByInvoice(ctx context.Context, restaurantId string, invoiceId string) ([]models.Payment, error) {
+	return s.docs.find(func(payment models.Payment) bool {
+		return payment.Invoice_id == invoiceId && payment.Restaurant_id == restaurantId
+	}), nil
+}
+
+func (s *paymentStore) Get(ctx context.Context, restaurantId string, paymentId string) (models.Payment, error) {
+	return s.docs.findOne(func(payment models.Payment) bool {
+		return payment.Payment_id == paymentId && payment.Restaurant_id == restaurantId
+	})
+}
+
+func (s *paymentStore) ListBetween(ctx context.Context, restaurantId string, from time.Time, to time.Time) ([]models.Payment, error) {
+	return s.docs.find(func(payment models.Payment) bool {
+		return payment.Restaurant_id == restaurantId && !payment.Created_at.Before(from) && payment.Created_at.Before(to)
+	}), nil
+}
+
+func (s *paymentStore) Create(ctx context.Context, payment models.Payment) error {
+	s.docs.insert(payment)
+	return nil
+}
+
+func (s *paymentStore) SetProviderStatus(ctx context.Context, provider string, transactionId string, status string) error {
+	updated := s.docs.update(func(payment models.Payment) bool {
+		return payment.Provider == provider && payment.Transaction_id == transactionId
+	}, func(payment *models.Payment) bool {
+		payment.Provider_status = status
+		return true
+	})
+
+	if !updated {
+		return store.ErrNotFound
+	}
+
+	return nil
+}
+
+func (s *paymentStore) Refund(ctx context.Context, restaurantId string, paymentId string, amount money.Money, tip money.Money) (models.Payment, bool, error) {
+	var updated models.Payment
+
+	refunded := s.docs.update(func(payment models.Payment) bool {
+		return payment.Payment_id == paymentId && payment.Restaurant_id == restaurantId
+	}, func(payment *models.Payment) bool {
+		total := payment.Refunded.Add(amount)
+		tips := payment.Tip_refunded.Add(tip)
+		if total.Cmp(payment.Amount) > 0 || tips.Cmp(payment.Tip) > 0 {
+			return false
+		}
+
+		payment.Refunded = total
+		payment.Tip_refunded = tips
+		updated = *payment
+		return true
+	})
+
+	return updated, refunded, nil
+}
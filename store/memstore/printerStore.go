@@ -0,0 +1,32 @@
+package memstore
+
+import (
+	"context"
+
+	"github.com/vikas-gouda/go-restraunt-mangement/models"
+)
+
+type printerStore struct {
+	docs collection[models.Printer]
+}
+
+func (s *printerStore) List(ctx context.Context, restaurantId string) ([]models.Printer, error) {
+	return s.docs.find(func(printer models.Printer) bool { return printer.Restaurant_id == restaurantId }), nil
+}
+
+func (s *printerStore) Get(ctx context.Context, restaurantId string, printerId string) (models.Printer, error) {
+	return s.docs.findOne(func(printer models.Printer) bool {
+		return printer.Printer_id == printerId && printer.Restaurant_id == restaurantId
+	})
+}
+
+func (s *printerStore) Create(ctx context.Context, printer models.Printer) error {
+	s.docs.insert(printer)
+	return nil
+}
+
+func (s *printerStore) Update(ctx context.Context, printer models.Printer) error {
+	return s.docs.replace(func(doc models.Printer) bool {
+		return doc.Printer_id == printer.Printer_id && doc.Restaurant_id == printer.Restaurant_id
+	}, printer)
+}
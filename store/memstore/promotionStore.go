@@ -0,0 +1,69 @@
+package memstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/vikas-gouda/go-restraunt-mangement/models"
+)
+
+type promotionStore struct {
+	docs collection[models.Promotion]
+}
+
+func (s *promotionStore) List(ctx context.Context, restaurantId string) ([]models.Promotion, error) {
+	return s.docs.find(func(promotion models.Promotion) bool { return promotion.Restaurant_id == restaurantId }), nil
+}
+
+func (s *promotionStore) Get(ctx context.Context, restaurantId string, promotionId string) (models.Promotion, error) {
+	return s.docs.findOne(func(promotion models.Promotion) bool {
+		return promotion.Promotion_id == promotionId && promotion.Restaurant_id == restaurantId
+	})
+}
+
+func (s *promotionStore) GetByCode(ctx context.Context, restaurantId string, code string) (models.Promotion, error) {
+	return s.docs.findOne(func(promotion models.Promotion) bool {
+		return promotion.Code == code && promotion.Restaurant_id == restaurantId
+	})
+}
+
+func (s *promotionStore) Create(ctx context.Context, promotion models.Promotion) error {
+	s.docs.insert(promotion)
+	return nil
+}
+
+func (s *promotionStore) Update(ctx context.Context, promotion models.Promotion) error {
+	return s.docs.replace(func(doc models.Promotion) bool {
+		return doc.Promotion_id == promotion.Promotion_id && doc.Restaurant_id == promotion.Restaurant_id
+	}, promotion)
+}
+
+func (s *promotionStore) Redeem(ctx context.Context, restaurantId string, promotionId string, at time.Time) (bool, error) {
+	redeemed := s.docs.update(func(promotion models.Promotion) bool {
+		return promotion.Promotion_id == promotionId && promotion.Restaurant_id == restaurantId
+	}, func(promotion *models.Promotion) bool {
+		if !promotion.Redeemable(at) {
+			return false
+		}
+
+		promotion.Uses++
+		return true
+	})
+
+	return redeemed, nil
+}
+
+func (s *promotionStore) Unredeem(ctx context.Context, restaurantId string, promotionId string) error {
+	s.docs.update(func(promotion models.Promotion) bool {
+		return promotion.Promotion_id == promotionId && promotion.Restaurant_id == restaurantId
+	}, func(promotion *models.Promotion) bool {
+		if promotion.Uses == 0 {
+			return false
+		}
+
+		promotion.Uses--
+		return true
+	})
+
+	return nil
+}
@@ -0,0 +1,32 @@
+package memstore
+
+import (
+	"context"
+
+	"github.com/vikas-gouda/go-restraunt-mangement/models"
+)
+
+type restaurantStore struct {
+	docs collection[models.Restaurant]
+}
+
+func (s *restaurantStore) List(ctx context.Context) ([]models.Restaurant, error) {
+	return s.docs.find(func(models.Restaurant) bool { return true }), nil
+}
+
+func (s *restaurantStore) Get(ctx context.Context, restaurantId string) (models.Restaurant, error) {
+	return s.docs.findOne(func(r models.Restaurant) bool { return r.Restaurant_id == restaurantId })
+}
+
+func (s *restaurantStore) GetByCode(ctx context.Context, code string) (models.Restaurant, error) {
+	return s.docs.findOne(func(r models.Restaurant) bool { return r.Code == code })
+}
+
+func (s *restaurantStore) Create(ctx context.Context, restaurant models.Restaurant) error {
+	s.docs.insert(restaurant)
+	return nil
+}
+
+func (s *restaurantStore) Update(ctx context.Context, restaurant models.Restaurant) error {
+	return s.docs.replace(func(r models.Restaurant) bool { return r.Restaurant_id == restaurant.Restaurant_id }, restaurant)
+}
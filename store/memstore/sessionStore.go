@@ -0,0 +1,65 @@
+package memstore
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/vikas-gouda/go-restraunt-mangement/models"
+)
+
+type sessionStore struct {
+	docs collection[models.Session]
+}
+
+func (s *sessionStore) Create(ctx context.Context, session models.Session) error {
+	s.docs.insert(session)
+	return nil
+}
+
+func (s *sessionStore) Active(ctx context.Context, sessionId string) (bool, error) {
+	now := time.Now()
+	count := s.docs.count(func(session models.Session) bool {
+		return session.Session_id == sessionId && session.Expires_at.After(now)
+	})
+
+	return count == 1, nil
+}
+
+func (s *sessionStore) Rotate(ctx context.Context, sessionId string, oldHash string, newHash string, expiresAt time.Time) (bool, error) {
+	rotated := s.docs.update(func(session models.Session) bool {
+		return session.Session_id == sessionId && session.Refresh_token_hash == oldHash
+	}, func(session *models.Session) bool {
+		session.Refresh_token_hash = newHash
+		session.Last_seen_at = time.Now().UTC().Truncate(time.Second)
+		session.Expires_at = expiresAt
+		return true
+	})
+
+	return rotated, nil
+}
+
+func (s *sessionStore) List(ctx context.Context, userId string) ([]models.Session, error) {
+	now := time.Now()
+	sessions := s.docs.find(func(session models.Session) bool {
+		return session.User_id == userId && session.Expires_at.After(now)
+	})
+
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].Last_seen_at.After(sessions[j].Last_seen_at)
+	})
+
+	return sessions, nil
+}
+
+func (s *sessionStore) Revoke(ctx context.Context, userId string, sessionId string) (bool, error) {
+	deleted := s.docs.delete(func(session models.Session) bool {
+		return session.User_id == userId && session.Session_id == sessionId
+	})
+
+	return deleted == 1, nil
+}
+
+func (s *sessionStore) RevokeAll(ctx context.Context, userId string) (int64, error) {
+	return s.docs.delete(func(session models.Session) bool { return session.User_id == userId }), nil
+}
@@ -0,0 +1,63 @@
+package memstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/vikas-gouda/go-restraunt-mangement/models"
+	"github.com/vikas-gouda/go-restraunt-mangement/store"
+)
+
+type shiftStore struct {
+	docs collection[models.Shift]
+}
+
+func (s *shiftStore) List(ctx context.Context, restaurantId string, from time.Time, to time.Time) ([]models.Shift, error) {
+	return s.docs.find(func(shift models.Shift) bool {
+		return shift.Restaurant_id == restaurantId && shift.Clock_in.Before(to) && (shift.Open || shift.Clock_out != nil && shift.Clock_out.After(from))
+	}), nil
+}
+
+func (s *shiftStore) Get(ctx context.Context, restaurantId string, shiftId string) (models.Shift, error) {
+	return s.docs.findOne(func(shift models.Shift) bool {
+		return shift.Shift_id == shiftId && shift.Restaurant_id == restaurantId
+	})
+}
+
+func (s *shiftStore) ClockIn(ctx context.Context, shift models.Shift) error {
+	_, opened := s.docs.insertUnless(func(existing models.Shift) bool {
+		return existing.Open && existing.User_id == shift.User_id && existing.Restaurant_id == shift.Restaurant_id
+	}, shift)
+
+	if !opened {
+		return store.ErrDuplicate
+	}
+
+	return nil
+}
+
+func (s *shiftStore) ClockOut(ctx context.Context, restaurantId string, userId string, at time.Time) (models.Shift, error) {
+	var closed models.Shift
+
+	found := s.docs.update(func(shift models.Shift) bool {
+		return shift.Open && shift.User_id == userId && shift.Restaurant_id == restaurantId
+	}, func(shift *models.Shift) bool {
+		shift.Open = false
+		shift.Clock_out = &at
+		shift.Updated_at = at
+		closed = *shift
+		return true
+	})
+
+	if !found {
+		return closed, store.ErrNotFound
+	}
+
+	return closed, nil
+}
+
+func (s *shiftStore) Update(ctx context.Context, shift models.Shift) error {
+	return s.docs.replace(func(existing models.Shift) bool {
+		return existing.Shift_id == shift.Shift_id && existing.Restaurant_id == shift.Restaurant_id
+	}, shift)
+}
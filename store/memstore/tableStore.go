@@ -0,0 +1,32 @@
+package memstore
+
+import (
+	"context"
+
+	"github.com/vikas-gouda/go-restraunt-mangement/models"
+)
+
+type tableStore struct {
+	docs collection[models.Table]
+}
+
+func (s *tableStore) List(ctx context.Context, restaurantId string) ([]models.Table, error) {
+	return s.docs.find(func(table models.Table) bool { return table.Restaurant_id == restaurantId }), nil
+}
+
+func (s *tableStore) Get(ctx context.Context, restaurantId string, tableId string) (models.Table, error) {
+	return s.docs.findOne(func(table models.Table) bool {
+		return table.Table_id == tableId && table.Restaurant_id == restaurantId
+	})
+}
+
+func (s *tableStore) Create(ctx context.Context, table models.Table) error {
+	s.docs.insert(table)
+	return nil
+}
+
+func (s *tableStore) Update(ctx context.Context, table models.Table) error {
+	return s.docs.replace(func(doc models.Table) bool {
+		return doc.Table_id == table.Table_id && doc.Restaurant_id == table.Restaurant_id
+	}, table)
+}
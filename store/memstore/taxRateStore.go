@@ -0,0 +1,32 @@
+package memstore
+
+import (
+	"context"
+
+	"github.com/vikas-gouda/go-restraunt-mangement/models"
+)
+
+type taxRateStore struct {
+	docs collection[models.TaxRate]
+}
+
+func (s *taxRateStore) List(ctx context.Context, restaurantId string) ([]models.TaxRate, error) {
+	return s.docs.find(func(taxRate models.TaxRate) bool { return taxRate.Restaurant_id == restaurantId }), nil
+}
+
+func (s *taxRateStore) Get(ctx context.Context, restaurantId string, taxRateId string) (models.TaxRate, error) {
+	return s.docs.findOne(func(taxRate models.TaxRate) bool {
+		return taxRate.Tax_rate_id == taxRateId && taxRate.Restaurant_id == restaurantId
+	})
+}
+
+func (s *taxRateStore) Create(ctx context.Context, taxRate models.TaxRate) error {
+	s.docs.insert(taxRate)
+	return nil
+}
+
+func (s *taxRateStore) Update(ctx context.Context, taxRate models.TaxRate) error {
+	return s.docs.replace(func(doc models.TaxRate) bool {
+		return doc.Tax_rate_id == taxRate.Tax_rate_id && doc.Restaurant_id == taxRate.Restaurant_id
+	}, taxRate)
+}
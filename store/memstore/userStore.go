@@ -0,0 +1,47 @@
+package memstore
+
+import (
+	"context"
+
+	"github.com/vikas-gouda/go-restraunt-mangement/models"
+)
+
+type userStore struct {
+	docs collection[models.User]
+}
+
+func (s *userStore) Count(ctx context.Context) (int64, error) {
+	return s.docs.count(func(models.User) bool { return true }), nil
+}
+
+func (s *userStore) Get(ctx context.Context, userId string) (models.User, error) {
+	return s.docs.findOne(func(u models.User) bool { return u.User_id == userId })
+}
+
+func (s *userStore) GetByEmail(ctx context.Context, email string) (models.User, error) {
+	return s.docs.findOne(func(u models.User) bool { return u.Email == email })
+}
+
+func (s *userStore) GetByPhone(ctx context.Context, phone string) (models.User, error) {
+	return s.docs.findOne(func(u models.User) bool { return u.Phone == phone })
+}
+
+func (s *userStore) List(ctx context.Context, restaurantId string, start int, limit int) (int64, []models.User, error) {
+	users := s.docs.find(func(u models.User) bool { return u.Restaurant_id == restaurantId })
+
+	return int64(len(users)), page(users, start, limit), nil
+}
+
+func (s *userStore) Create(ctx context.Context, user models.User) error {
+	s.docs.insert(user)
+	return nil
+}
+
+func (s *userStore) CreateFirst(ctx context.Context, user models.User) (bool, error) {
+	_, created := s.docs.insertUnless(func(models.User) bool { return true }, user)
+	return created, nil
+}
+
+func (s *userStore) Update(ctx context.Context, user models.User) error {
+	return s.docs.replace(func(u models.User) bool { return u.User_id == user.User_id }, user)
+}
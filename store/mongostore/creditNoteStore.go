@@ -0,0 +1,34 @@
+package mongostore
+
+import (
+	"context"
+	"time"
+
+	"github.com/vikas-gouda/go-restraunt-mangement/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type creditNoteStore struct {
+	c *mongo.Collection
+}
+
+func (s *creditNoteStore) List(ctx context.Context, restaurantId string, from time.Time, to time.Time) ([]models.CreditNote, error) {
+	return find[models.CreditNote](ctx, s.c, bson.M{
+		"restaurant_id": restaurantId,
+		"created_at":    bson.M{"$gte": from, "$lt": to},
+	}, options.Find().SetSort(byInsertion))
+}
+
+func (s *creditNoteStore) ListByInvoice(ctx context.Context, restaurantId string, invoiceId string) ([]models.CreditNote, error) {
+	return find[models.CreditNote](ctx, s.c, bson.M{"invoice_id": invoiceId, "restaurant_id": restaurantId}, options.Find().SetSort(byInsertion))
+}
+
+func (s *creditNoteStore) Get(ctx context.Context, restaurantId string, creditNoteId string) (models.CreditNote, error) {
+	return findOne[models.CreditNote](ctx, s.c, bson.M{"credit_note_id": creditNoteId, "restaurant_id": restaurantId})
+}
+
+func (s *creditNoteStore) Create(ctx context.Context, creditNote models.CreditNote) error {
+	return insert(ctx, s.c, creditNote)
+}
@@ -0,0 +1,40 @@
+package mongostore
+
+import (
+	"context"
+
+	"github.com/vikas-gouda/go-restraunt-mangement/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type foodStore struct {
+	c *mongo.Collection
+}
+
+func (s *foodStore) List(ctx context.Context, restaurantId string, start int, limit int) (int64, []models.Food, error) {
+	filter := bson.M{"restaurant_id": restaurantId}
+
+	total, err := s.c.CountDocuments(ctx, filter)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	opt := options.Find().SetSort(byInsertion).SetSkip(int64(start)).SetLimit(int64(limit))
+	foods, err := find[models.Food](ctx, s.c, filter, opt)
+
+	return total, foods, err
+}
+
+func (s *foodStore) Get(ctx context.Context, restaurantId string, foodId string) (models.Food, error) {
+	return findOne[models.Food](ctx, s.c, bson.M{"food_id": foodId, "restaurant_id": restaurantId})
+}
+
+func (s *foodStore) Create(ctx context.Context, food models.Food) error {
+	return insert(ctx, s.c, food)
+}
+
+func (s *foodStore) Update(ctx context.Context, food models.Food) error {
+	return replace(ctx, s.c, bson.M{"food_id": food.Food_id, "restaurant_id": food.Restaurant_id}, food)
+}
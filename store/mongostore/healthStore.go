@@ -0,0 +1,54 @@
+package mongostore
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// requiredIndexes lists, per collection, the index names created by the
+// ensureIndexes functions.
+var requiredIndexes = map[string][]string{
+	"session":     {"expires_at_1", "session_id_1", "user_id_1"},
+	"idempotency": {"expires_at_1", "restaurant_id_1_key_1"},
+	"invoice":     {"unique_invoice_number"},
+}
+
+type healthStore struct {
+	db *mongo.Database
+}
+
+func (s *healthStore) Ping(ctx context.Context) error {
+	return s.db.Client().Ping(ctx, nil)
+}
+
+func (s *healthStore) CheckIndexes(ctx context.Context) error {
+	for collection, names := range requiredIndexes {
+		cursor, err := s.db.Collection(collection).Indexes().List(ctx)
+		if err != nil {
+			return err
+		}
+
+		var indexes []bson.M
+		if err := cursor.All(ctx, &indexes); err != nil {
+			return err
+		}
+
+		found := map[string]bool{}
+		for _, index := range indexes {
+			if name, ok := index["name"].(string); ok {
+				found[name] = true
+			}
+		}
+
+		for _, name := range names {
+			if !found[name] {
+				return fmt.Errorf("index %s on %s is missing", name, collection)
+			}
+		}
+	}
+
+	return nil
+}
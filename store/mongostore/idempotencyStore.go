@@ -0,0 +1,77 @@
+package mongostore
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/vikas-gouda/go-restraunt-mangement/models"
+	"github.com/vikas-gouda/go-restraunt-mangement/store"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type idempotencyStore struct {
+	c *mongo.Collection
+}
+
+// ensureIndexes makes keys unique per restaurant and lets MongoDB drop the
+// records once they expire.
+func (s *idempotencyStore) ensureIndexes(ctx context.Context) error {
+	_, err := s.c.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+		{
+			Keys:    bson.D{{Key: "restaurant_id", Value: 1}, {Key: "key", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	})
+
+	return err
+}
+
+func (s *idempotencyStore) Reserve(ctx context.Context, record models.IdempotencyRecord) (models.IdempotencyRecord, bool, error) {
+	err := insert(ctx, s.c, record)
+	if err == nil {
+		return record, true, nil
+	}
+
+	if !errors.Is(err, store.ErrDuplicate) {
+		return record, false, err
+	}
+
+	// The TTL monitor runs once a minute, so an expired record may still be
+	// around; drop it and try again. Replacing it would change its _id.
+	result, err := s.c.DeleteOne(ctx, bson.M{
+		"restaurant_id": record.Restaurant_id,
+		"key":           record.Key,
+		"expires_at":    bson.M{"$lte": time.Now()},
+	})
+	if err != nil {
+		return record, false, err
+	}
+
+	if result.DeletedCount == 1 {
+		return s.Reserve(ctx, record)
+	}
+
+	existing, err := findOne[models.IdempotencyRecord](ctx, s.c, bson.M{"restaurant_id": record.Restaurant_id, "key": record.Key})
+	if errors.Is(err, store.ErrNotFound) {
+		// Released in the meantime; the caller can simply try again.
+		return s.Reserve(ctx, record)
+	}
+
+	return existing, false, err
+}
+
+func (s *idempotencyStore) Complete(ctx context.Context, record models.IdempotencyRecord) error {
+	return replace(ctx, s.c, bson.M{"restaurant_id": record.Restaurant_id, "key": record.Key}, record)
+}
+
+func (s *idempotencyStore) Release(ctx context.Context, restaurantId string, key string) error {
+	_, err := s.c.DeleteOne(ctx, bson.M{"restaurant_id": restaurantId, "key": key})
+	return err
+}
@@ -0,0 +1,232 @@
+package mongostore
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/vikas-gouda/go-restraunt-mangement/models"
+	"github.com/vikas-gouda/go-restraunt-mangement/money"
+	"github.com/vikas-gouda/go-restraunt-mangement/store"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type invoiceStore struct {
+	c        *mongo.Collection
+	orders   *mongo.Collection
+	counters *mongo.Collection
+}
+
+// errBilledMeanwhile aborts the Replace transaction.
+var errBilledMeanwhile = errors.New("mongostore: order was billed meanwhile")
+
+// counter is the last number taken in one series of a restaurant.
+type counter struct {
+	ID            string `bson:"_id"`
+	Restaurant_id string `bson:"restaurant_id"`
+	Series        string `bson:"series"`
+	Value         int64  `bson:"value"`
+}
+
+// ensureIndexes makes a number unique per restaurant, whatever happens to
+// the counters.
+func (s *invoiceStore) ensureIndexes(ctx context.Context) error {
+	_, err := s.c.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "restaurant_id", Value: 1}, {Key: "invoice_number", Value: 1}},
+		Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"invoice_number": bson.M{"$exists": true}}).
+			SetName("unique_invoice_number"),
+	})
+
+	return err
+}
+
+func (s *invoiceStore) List(ctx context.Context, restaurantId string) ([]models.Invoice, error) {
+	return find[models.Invoice](ctx, s.c, bson.M{"restaurant_id": restaurantId}, options.Find().SetSort(byInsertion))
+}
+
+func (s *invoiceStore) ListByOrder(ctx context.Context, restaurantId string, orderId string) ([]models.Invoice, error) {
+	return find[models.Invoice](ctx, s.c, bson.M{"order_id": orderId, "restaurant_id": restaurantId}, options.Find().SetSort(byInsertion))
+}
+
+func (s *invoiceStore) Get(ctx context.Context, restaurantId string, invoiceId string) (models.Invoice, error) {
+	return findOne[models.Invoice](ctx, s.c, bson.M{"invoice_id": invoiceId, "restaurant_id": restaurantId})
+}
+
+func (s *invoiceStore) Create(ctx context.Context, invoice models.Invoice) error {
+	return insert(ctx, s.c, invoice)
+}
+
+func (s *invoiceStore) Update(ctx context.Context, invoice models.Invoice) error {
+	return replace(ctx, s.c, bson.M{"invoice_id": invoice.Invoice_id, "restaurant_id": invoice.Restaurant_id}, invoice)
+}
+
+// Replace numbers the invoices in the same transaction that stores them, so
+// a failed replace gives its numbers back. A standalone server can't take a
+// number back once somebody took the next one, so it makes no invoices.
+func (s *invoiceStore) Replace(ctx context.Context, restaurantId string, orderId string, version int, voidIds []string, at time.Time, invoices []models.Invoice, numbering models.InvoiceNumbering) ([]models.Invoice, bool, error) {
+	session, err := s.c.Database().Client().StartSession()
+	if err != nil {
+		return nil, false, err
+	}
+	defer session.EndSession(ctx)
+
+	var numbered []models.Invoice
+
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		numbered, err = s.replace(sc, restaurantId, orderId, version, voidIds, at, invoices, numbering)
+		return nil, err
+	})
+	if transactionsUnsupported(err) {
+		return nil, false, store.ErrNoTransactions
+	}
+
+	if errors.Is(err, errBilledMeanwhile) {
+		return nil, false, nil
+	}
+
+	if err != nil {
+		return nil, false, err
+	}
+
+	return numbered, true, nil
+}
+
+func (s *invoiceStore) replace(ctx context.Context, restaurantId string, orderId string, version int, voidIds []string, at time.Time, invoices []models.Invoice, numbering models.InvoiceNumbering) ([]models.Invoice, error) {
+	result, err := s.orders.UpdateOne(ctx, bson.M{
+		"order_id":        orderId,
+		"restaurant_id":   restaurantId,
+		"billing_version": billingVersion(version),
+	}, bson.M{"$inc": bson.M{"billing_version": 1}})
+	if err != nil {
+		return nil, err
+	}
+
+	if result.MatchedCount == 0 {
+		return nil, errBilledMeanwhile
+	}
+
+	if len(voidIds) > 0 {
+		result, err = s.c.UpdateMany(ctx, bson.M{
+			"invoice_id":      bson.M{"$in": voidIds},
+			"restaurant_id":   restaurantId,
+			"voided_at":       nil,
+			"payment_status":  bson.M{"$ne": models.InvoicePaid},
+			"paid.amount":     bson.M{"$in": bson.A{0, nil}},
+			"credited.amount": bson.M{"$in": bson.A{0, nil}},
+		}, bson.M{"$set": bson.M{"voided_at": at, "updated_at": at}})
+		if err != nil {
+			return nil, err
+		}
+
+		if result.ModifiedCount != int64(len(voidIds)) {
+			return nil, errBilledMeanwhile
+		}
+	}
+
+	id := restaurantId + ":invoice:" + numbering.Series
+	var taken counter
+	err = s.counters.FindOneAndUpdate(ctx, bson.M{"_id": id}, bson.M{
+		"$inc":         bson.M{"value": int64(len(invoices))},
+		"$setOnInsert": bson.M{"restaurant_id": restaurantId, "series": numbering.Series},
+	}, options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)).Decode(&taken)
+	if err != nil {
+		return nil, err
+	}
+
+	numbered := make([]models.Invoice, len(invoices))
+	docs := make([]interface{}, 0, len(invoices))
+	for i, invoice := range invoices {
+		invoice.Sequence = taken.Value - int64(len(invoices)) + int64(i) + 1
+		invoice.Fiscal_year = numbering.Series
+		invoice.Invoice_number = numbering.Number(invoice.Sequence)
+		numbered[i] = invoice
+		docs = append(docs, invoice)
+	}
+
+	if _, err = s.c.InsertMany(ctx, docs); err != nil {
+		return nil, err
+	}
+
+	return numbered, nil
+}
+
+func (s *invoiceStore) Void(ctx context.Context, restaurantId string, invoiceId string, at time.Time, reason string, voidedBy string) (models.Invoice, bool, error) {
+	var invoice models.Invoice
+
+	err := s.c.FindOneAndUpdate(ctx, bson.M{
+		"invoice_id":      invoiceId,
+		"restaurant_id":   restaurantId,
+		"voided_at":       nil,
+		"payment_status":  bson.M{"$ne": models.InvoicePaid},
+		"paid.amount":     bson.M{"$in": bson.A{0, nil}},
+		"credited.amount": bson.M{"$in": bson.A{0, nil}},
+	}, bson.M{"$set": bson.M{
+		"voided_at":   at,
+		"void_reason": reason,
+		"voided_by":   voidedBy,
+		"updated_at":  at,
+	}}, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&invoice)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return invoice, false, nil
+	}
+
+	return invoice, err == nil, err
+}
+
+func (s *invoiceStore) AddPaid(ctx context.Context, restaurantId string, invoiceId string, amount money.Money) (models.Invoice, bool, error) {
+	var invoice models.Invoice
+
+	// A pipeline, so invoices from before payments get their paid set up.
+	err := s.c.FindOneAndUpdate(ctx, bson.M{
+		"invoice_id":    invoiceId,
+		"restaurant_id": restaurantId,
+		"voided_at":     nil,
+	}, bson.A{
+		bson.M{"$set": bson.M{"paid": bson.M{
+			"amount":   bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$paid.amount", int64(0)}}, amount.Amount}},
+			"currency": amount.Currency,
+		}}},
+	}, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&invoice)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return invoice, false, nil
+	}
+
+	return invoice, err == nil, err
+}
+
+func (s *invoiceStore) AddRefund(ctx context.Context, restaurantId string, invoiceId string, refunded money.Money, credited money.Money) (models.Invoice, error) {
+	var invoice models.Invoice
+
+	err := s.c.FindOneAndUpdate(ctx, bson.M{
+		"invoice_id":    invoiceId,
+		"restaurant_id": restaurantId,
+	}, bson.A{
+		bson.M{"$set": bson.M{
+			"paid": bson.M{
+				"amount":   bson.M{"$subtract": bson.A{bson.M{"$ifNull": bson.A{"$paid.amount", int64(0)}}, refunded.Amount}},
+				"currency": refunded.Currency,
+			},
+			"credited": bson.M{
+				"amount":   bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$credited.amount", int64(0)}}, credited.Amount}},
+				"currency": credited.Currency,
+			},
+		}},
+	}, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&invoice)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return invoice, store.ErrNotFound
+	}
+
+	return invoice, err
+}
+
+func (s *invoiceStore) SetPaymentStatus(ctx context.Context, restaurantId string, invoiceId string, paid money.Money, status string, method string) error {
+	_, err := s.c.UpdateOne(ctx, bson.M{
+		"invoice_id":    invoiceId,
+		"restaurant_id": restaurantId,
+		"paid.amount":   paid.Amount,
+	}, bson.M{"$set": bson.M{"payment_status": status, "payment_method": method}})
+
+	return err
+}
@@ -0,0 +1,30 @@
+package mongostore
+
+import (
+	"context"
+
+	"github.com/vikas-gouda/go-restraunt-mangement/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type menuStore struct {
+	c *mongo.Collection
+}
+
+func (s *menuStore) List(ctx context.Context, restaurantId string) ([]models.Menu, error) {
+	return find[models.Menu](ctx, s.c, bson.M{"restaurant_id": restaurantId}, options.Find().SetSort(byInsertion))
+}
+
+func (s *menuStore) Get(ctx context.Context, restaurantId string, menuId string) (models.Menu, error) {
+	return findOne[models.Menu](ctx, s.c, bson.M{"menu_id": menuId, "restaurant_id": restaurantId})
+}
+
+func (s *menuStore) Create(ctx context.Context, menu models.Menu) error {
+	return insert(ctx, s.c, menu)
+}
+
+func (s *menuStore) Update(ctx context.Context, menu models.Menu) error {
+	return replace(ctx, s.c, bson.M{"menu_id": menu.Menu_id, "restaurant_id": menu.Restaurant_id}, menu)
+}
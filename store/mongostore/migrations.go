@@ -0,0 +1,188 @@
+package mongostore
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/vikas-gouda/go-restraunt-mangement/models"
+	"github.com/vikas-gouda/go-restraunt-mangement/money"
+	"github.com/vikas-gouda/go-restraunt-mangement/store"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// migrate brings documents written by older versions up to date. Every step
+// only matches documents still in the old shape, so running it on every
+// start is cheap and safe.
+func migrate(ctx context.Context, db *mongo.Database) error {
+	if err := migrateRestaurant(ctx, db); err != nil {
+		return err
+	}
+
+	// Order items used to keep the size code (S/M/L) in quantity and had no
+	// line total; they were always for a single piece.
+	_, err := db.Collection("orderItem").UpdateMany(ctx, bson.M{"quantity": bson.M{"$type": "string"}}, bson.A{
+		bson.M{"$set": bson.M{
+			"size":       "$quantity",
+			"quantity":   1,
+			"line_total": bson.M{"$ifNull": bson.A{"$line_total", "$unit_price"}},
+		}},
+	})
+	if err != nil {
+		return err
+	}
+
+	// Users used to keep a plain copy of their latest tokens; sessions only
+	// keep a hash of the refresh token.
+	_, err = db.Collection("user").UpdateMany(ctx, bson.M{"$or": bson.A{
+		bson.M{"token": bson.M{"$exists": true}},
+		bson.M{"refresh_token": bson.M{"$exists": true}},
+	}}, bson.M{"$unset": bson.M{"token": "", "refresh_token": ""}})
+	if err != nil {
+		return err
+	}
+
+	return migrateMoney(ctx, db)
+}
+
+// tenantCollections hold the documents that belong to one restaurant.
+var tenantCollections = []string{"user", "food", "menu", "table", "order", "orderItem", "invoice"}
+
+// migrateRestaurant puts documents from before restaurants existed into the
+// first restaurant, creating one when there is none yet. Documents written
+// since always carry the field, even the owner's before any restaurant exists.
+func migrateRestaurant(ctx context.Context, db *mongo.Database) error {
+	legacy := bson.M{"restaurant_id": bson.M{"$exists": false}}
+
+	found := false
+	for _, name := range tenantCollections {
+		count, err := db.Collection(name).CountDocuments(ctx, legacy, options.Count().SetLimit(1))
+		if err != nil {
+			return err
+		}
+
+		if count > 0 {
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		return nil
+	}
+
+	restaurant, err := findOne[models.Restaurant](ctx, db.Collection("restaurant"), bson.M{}, options.FindOne().SetSort(byInsertion))
+	if errors.Is(err, store.ErrNotFound) {
+		restaurant = models.Restaurant{Name: "Main", Code: "MAIN"}
+		currency, rounding := restaurant.Money()
+		restaurant.Currency = currency
+		restaurant.Rounding = string(rounding)
+		restaurant.Created_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		restaurant.Updated_at = restaurant.Created_at
+		restaurant.ID = primitive.NewObjectID()
+		restaurant.Restaurant_id = restaurant.ID.Hex()
+
+		err = insert(ctx, db.Collection("restaurant"), restaurant)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	for _, name := range tenantCollections {
+		if _, err := db.Collection(name).UpdateMany(ctx, legacy, bson.M{"$set": bson.M{"restaurant_id": restaurant.Restaurant_id}}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// migrateMoney turns prices stored as floating point numbers into money in
+// the minor units of the restaurant's currency. Documents of restaurants that
+// no longer exist are converted with the default currency.
+func migrateMoney(ctx context.Context, db *mongo.Database) error {
+	// Restaurants without a currency were priced in the default one; keep it
+	// so it can't be changed under their prices.
+	_, err := db.Collection("restaurant").UpdateMany(ctx, bson.M{"$or": bson.A{
+		bson.M{"currency": bson.M{"$exists": false}},
+		bson.M{"currency": ""},
+	}}, bson.M{"$set": bson.M{"currency": money.DefaultCurrency}})
+	if err != nil {
+		return err
+	}
+
+	restaurants, err := find[models.Restaurant](ctx, db.Collection("restaurant"), bson.M{})
+	if err != nil {
+		return err
+	}
+
+	for _, restaurant := range restaurants {
+		currency, _ := restaurant.Money()
+		if err := convertMoney(ctx, db, bson.M{"restaurant_id": restaurant.Restaurant_id}, currency); err != nil {
+			return err
+		}
+	}
+
+	return convertMoney(ctx, db, bson.M{}, money.DefaultCurrency)
+}
+
+func convertMoney(ctx context.Context, db *mongo.Database, filter bson.M, currency string) error {
+	toMoney := func(field string) bson.M {
+		return bson.M{"$cond": bson.A{
+			bson.M{"$isNumber": field},
+			bson.M{
+				"amount":   bson.M{"$toLong": bson.M{"$round": bson.A{bson.M{"$multiply": bson.A{field, math.Pow10(money.Exponent(currency))}}, 0}}},
+				"currency": currency,
+			},
+			field,
+		}}
+	}
+
+	// eachOf applies in to every element of an array field, leaving documents
+	// without the array alone.
+	eachOf := func(field string, as string, in bson.M) bson.M {
+		return bson.M{"$cond": bson.A{
+			bson.M{"$isArray": field},
+			bson.M{"$map": bson.M{"input": field, "as": as, "in": in}},
+			field,
+		}}
+	}
+
+	_, err := db.Collection("food").UpdateMany(ctx, bson.M{"$and": bson.A{filter, bson.M{"$or": bson.A{
+		bson.M{"price": bson.M{"$type": "number"}},
+		bson.M{"modifier_groups.options.price_delta": bson.M{"$type": "number"}},
+	}}}}, bson.A{
+		bson.M{"$set": bson.M{
+			"price": toMoney("$price"),
+			"modifier_groups": eachOf("$modifier_groups", "group", bson.M{"$mergeObjects": bson.A{"$$group", bson.M{
+				"options": eachOf("$$group.options", "option", bson.M{"$mergeObjects": bson.A{"$$option", bson.M{
+					"price_delta": toMoney("$$option.price_delta"),
+				}}}),
+			}}}),
+		}},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Collection("orderItem").UpdateMany(ctx, bson.M{"$and": bson.A{filter, bson.M{"$or": bson.A{
+		bson.M{"unit_price": bson.M{"$type": "number"}},
+		bson.M{"line_total": bson.M{"$type": "number"}},
+		bson.M{"modifiers.price_delta": bson.M{"$type": "number"}},
+	}}}}, bson.A{
+		bson.M{"$set": bson.M{
+			"unit_price": toMoney("$unit_price"),
+			"line_total": toMoney("$line_total"),
+			"modifiers": eachOf("$modifiers", "modifier", bson.M{"$mergeObjects": bson.A{"$$modifier", bson.M{
+				"price_delta": toMoney("$$modifier.price_delta"),
+			}}}),
+		}},
+	})
+
+	return err
+}
@@ -0,0 +1,135 @@
+// Package mongostore implements the store interfaces on top of MongoDB.
+package mongostore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/vikas-gouda/go-restraunt-mangement/store"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// New wires every repository to its collection in db, upgrades documents left
+// by older versions and makes sure the indexes the repositories rely on exist.
+// Invoices are numbered in transactions, so a server without them is refused
+// with store.ErrNoTransactions.
+func New(ctx context.Context, db *mongo.Database) (*store.Store, error) {
+	if err := checkTransactions(ctx, db); err != nil {
+		return nil, err
+	}
+
+	sessions := &sessionStore{c: db.Collection("session")}
+	if err := sessions.ensureIndexes(ctx); err != nil {
+		return nil, err
+	}
+
+	if err := migrate(ctx, db); err != nil {
+		return nil, err
+	}
+
+	idempotency := &idempotencyStore{c: db.Collection("idempotency")}
+	if err := idempotency.ensureIndexes(ctx); err != nil {
+		return nil, err
+	}
+
+	invoices := &invoiceStore{c: db.Collection("invoice"), orders: db.Collection("order"), counters: db.Collection("counter")}
+	if err := invoices.ensureIndexes(ctx); err != nil {
+		return nil, err
+	}
+
+	shifts := &shiftStore{c: db.Collection("shift")}
+	if err := shifts.ensureIndexes(ctx); err != nil {
+		return nil, err
+	}
+
+	return &store.Store{
+		Restaurants: &restaurantStore{c: db.Collection("restaurant")},
+		Users:       &userStore{c: db.Collection("user"), bootstrap: db.Collection("bootstrap")},
+		Sessions:    sessions,
+		Foods:       &foodStore{c: db.Collection("food")},
+		Menus:       &menuStore{c: db.Collection("menu")},
+		Tables:      &tableStore{c: db.Collection("table")},
+		Orders:      &orderStore{c: db.Collection("order"), items: db.Collection("orderItem")},
+		OrderItems:  &orderItemStore{c: db.Collection("orderItem")},
+		Invoices:    invoices,
+		TaxRates:    &taxRateStore{c: db.Collection("taxRate")},
+		Promotions:  &promotionStore{c: db.Collection("promotion")},
+		Payments:    &paymentStore{c: db.Collection("payment")},
+		CreditNotes: &creditNoteStore{c: db.Collection("creditNote")},
+		Shifts:      shifts,
+		Printers:    &printerStore{c: db.Collection("printer")},
+		Health:      &healthStore{db: db},
+		Idempotency: idempotency,
+	}, nil
+}
+
+var byInsertion = bson.D{{Key: "_id", Value: 1}}
+
+func findOne[T any](ctx context.Context, c *mongo.Collection, filter interface{}, opts ...*options.FindOneOptions) (T, error) {
+	var doc T
+
+	err := c.FindOne(ctx, filter, opts...).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return doc, store.ErrNotFound
+	}
+
+	return doc, err
+}
+
+func find[T any](ctx context.Context, c *mongo.Collection, filter interface{}, opts ...*options.FindOptions) ([]T, error) {
+	cursor, err := c.Find(ctx, filter, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	docs := []T{}
+	if err = cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+
+	return docs, nil
+}
+
+func insert(ctx context.Context, c *mongo.Collection, doc interface{}) error {
+	_, err := c.InsertOne(ctx, doc)
+	if mongo.IsDuplicateKeyError(err) {
+		return store.ErrDuplicate
+	}
+
+	return err
+}
+
+func replace(ctx context.Context, c *mongo.Collection, filter interface{}, doc interface{}) error {
+	result, err := c.ReplaceOne(ctx, filter, doc)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return store.ErrNotFound
+	}
+
+	return nil
+}
+
+// checkTransactions fails unless the server is part of a replica set or a
+// sharded cluster, the deployments that can run transactions.
+func checkTransactions(ctx context.Context, db *mongo.Database) error {
+	var hello struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}
+
+	if err := db.RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello); err != nil {
+		return err
+	}
+
+	if hello.SetName == "" && hello.Msg != "isdbgrid" {
+		return fmt.Errorf("%w: run MongoDB as a replica set, a single node one will do", store.ErrNoTransactions)
+	}
+
+	return nil
+}
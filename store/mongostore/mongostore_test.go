@@ -0,0 +1,67 @@
+package mongostore_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/vikas-gouda/go-restraunt-mangement/store"
+	"github.com/vikas-gouda/go-restraunt-mangement/store/mongostore"
+	"github.com/vikas-gouda/go-restraunt-mangement/store/storetest"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// testStore opens a fresh database on the server in TEST_DB_URI, e.g.
+// mongodb://localhost:27017/?replicaSet=rs0, and drops it afterwards.
+func testStore(t *testing.T) *store.Store {
+	t.Helper()
+
+	uri := os.Getenv("TEST_DB_URI")
+	if uri == "" {
+		t.Skip("TEST_DB_URI is not set")
+	}
+
+	var c, cancel = context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	client, err := mongo.Connect(c, options.Client().ApplyURI(uri))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db := client.Database("test_" + primitive.NewObjectID().Hex())
+	t.Cleanup(func() {
+		db.Drop(context.Background())
+		client.Disconnect(context.Background())
+	})
+
+	s, err := mongostore.New(c, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return s
+}
+
+func TestIdempotency(t *testing.T) {
+	storetest.Idempotency(t, testStore(t).Idempotency)
+}
+
+func TestFirstUser(t *testing.T) {
+	storetest.FirstUser(t, testStore(t).Users)
+}
+
+func TestBillingVersion(t *testing.T) {
+	storetest.BillingVersion(t, testStore(t))
+}
+
+func TestInvoiceNumbering(t *testing.T) {
+	storetest.InvoiceNumbering(t, testStore(t))
+}
+
+func TestSessions(t *testing.T) {
+	storetest.Sessions(t, testStore(t).Sessions)
+}
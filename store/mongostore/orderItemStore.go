@@ -0,0 +1,62 @@
+package mongostore
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/vikas-gouda/go-restraunt-mangement/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type orderItemStore struct {
+	c *mongo.Collection
+}
+
+func (s *orderItemStore) List(ctx context.Context, restaurantId string) ([]models.OrderItem, error) {
+	return find[models.OrderItem](ctx, s.c, bson.M{"restaurant_id": restaurantId}, options.Find().SetSort(byInsertion))
+}
+
+func (s *orderItemStore) ListByOrder(ctx context.Context, restaurantId string, orderId string) ([]models.OrderItem, error) {
+	return find[models.OrderItem](ctx, s.c, bson.M{"order_id": orderId, "restaurant_id": restaurantId}, options.Find().SetSort(byInsertion))
+}
+
+func (s *orderItemStore) Get(ctx context.Context, restaurantId string, orderItemId string) (models.OrderItem, error) {
+	return findOne[models.OrderItem](ctx, s.c, bson.M{"order_item_id": orderItemId, "restaurant_id": restaurantId})
+}
+
+func (s *orderItemStore) CreateMany(ctx context.Context, orderItems []models.OrderItem) error {
+	docs := make([]interface{}, 0, len(orderItems))
+	for _, orderItem := range orderItems {
+		docs = append(docs, orderItem)
+	}
+
+	_, err := s.c.InsertMany(ctx, docs)
+
+	return err
+}
+
+func (s *orderItemStore) Update(ctx context.Context, orderItem models.OrderItem) error {
+	return replace(ctx, s.c, bson.M{"order_item_id": orderItem.Order_item_id, "restaurant_id": orderItem.Restaurant_id}, orderItem)
+}
+
+func (s *orderItemStore) Transition(ctx context.Context, restaurantId string, orderItemId string, from string, to string, at time.Time) (bool, error) {
+	filter := bson.M{"order_item_id": orderItemId, "restaurant_id": restaurantId, "status": from}
+	if from == models.ItemQueued {
+		// Items from before statuses existed have none and count as queued.
+		filter["status"] = bson.M{"$in": bson.A{models.ItemQueued, "", nil}}
+	}
+
+	result, err := s.c.UpdateOne(ctx, filter, bson.M{"$set": bson.M{
+		"status":                    to,
+		strings.ToLower(to) + "_at": at,
+		"updated_at":                at,
+	}})
+	if err != nil {
+		return false, err
+	}
+
+	return result.MatchedCount == 1, nil
+}
@@ -0,0 +1,159 @@
+package mongostore
+
+import (
+	"context"
+	"errors"
+
+	"github.com/vikas-gouda/go-restraunt-mangement/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type orderStore struct {
+	c     *mongo.Collection
+	items *mongo.Collection
+}
+
+func (s *orderStore) List(ctx context.Context, restaurantId string) ([]models.Order, error) {
+	return find[models.Order](ctx, s.c, bson.M{"restaurant_id": restaurantId}, options.Find().SetSort(byInsertion))
+}
+
+func (s *orderStore) Get(ctx context.Context, restaurantId string, orderId string) (models.Order, error) {
+	return findOne[models.Order](ctx, s.c, bson.M{"order_id": orderId, "restaurant_id": restaurantId})
+}
+
+func (s *orderStore) Create(ctx context.Context, order models.Order) error {
+	return insert(ctx, s.c, order)
+}
+
+func (s *orderStore) CreateWithItems(ctx context.Context, order models.Order, orderItems []models.OrderItem) error {
+	session, err := s.c.Database().Client().StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		return nil, s.insertWithItems(sc, order, orderItems)
+	})
+	if !transactionsUnsupported(err) {
+		return err
+	}
+
+	// A standalone server can't run transactions. Write the items first so the
+	// order only shows up complete, and take them back out if anything fails.
+	err = s.insertWithItems(ctx, order, orderItems)
+	if err != nil {
+		s.items.DeleteMany(context.Background(), bson.M{"order_id": order.Order_id, "restaurant_id": order.Restaurant_id})
+	}
+
+	return err
+}
+
+func (s *orderStore) insertWithItems(ctx context.Context, order models.Order, orderItems []models.OrderItem) error {
+	docs := make([]interface{}, 0, len(orderItems))
+	for _, orderItem := range orderItems {
+		docs = append(docs, orderItem)
+	}
+
+	if len(docs) > 0 {
+		if _, err := s.items.InsertMany(ctx, docs); err != nil {
+			return err
+		}
+	}
+
+	return insert(ctx, s.c, order)
+}
+
+// transactionsUnsupported tells whether err comes from a server that is not
+// part of a replica set.
+func transactionsUnsupported(err error) bool {
+	var commandErr mongo.CommandError
+	return errors.As(err, &commandErr) && commandErr.Code == 20
+}
+
+func (s *orderStore) Update(ctx context.Context, order models.Order) error {
+	return replace(ctx, s.c, bson.M{"order_id": order.Order_id, "restaurant_id": order.Restaurant_id}, order)
+}
+
+func (s *orderStore) Transition(ctx context.Context, restaurantId string, orderId string, transition models.OrderTransition) (bool, error) {
+	filter := bson.M{"order_id": orderId, "restaurant_id": restaurantId, "status": transition.From}
+	if transition.From == models.OrderOpen {
+		// Orders from before statuses existed have none and count as open.
+		filter["status"] = bson.M{"$in": bson.A{models.OrderOpen, "", nil}}
+	}
+
+	// A pipeline update, because replacing an order without history stores
+	// status_history as null and $push refuses to append to that.
+	result, err := s.c.UpdateOne(ctx, filter, bson.A{
+		bson.M{"$set": bson.M{
+			"status":     transition.To,
+			"updated_at": transition.At,
+			"status_history": bson.M{"$concatArrays": bson.A{
+				bson.M{"$ifNull": bson.A{"$status_history", bson.A{}}},
+				bson.M{"$literal": bson.A{transition}},
+			}},
+		}},
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return result.MatchedCount == 1, nil
+}
+
+func (s *orderStore) AddDiscount(ctx context.Context, restaurantId string, orderId string, version int, discount models.OrderDiscount) (bool, error) {
+	filter := bson.M{
+		"order_id":        orderId,
+		"restaurant_id":   restaurantId,
+		"status":          bson.M{"$nin": bson.A{models.OrderPaid, models.OrderCancelled}},
+		"billing_version": billingVersion(version),
+	}
+	if discount.Promotion_id != "" {
+		filter["discounts.promotion_id"] = bson.M{"$ne": discount.Promotion_id}
+	}
+
+	result, err := s.c.UpdateOne(ctx, filter, bson.A{
+		bson.M{"$set": bson.M{
+			"discounts": bson.M{"$concatArrays": bson.A{
+				bson.M{"$ifNull": bson.A{"$discounts", bson.A{}}},
+				bson.M{"$literal": bson.A{discount}},
+			}},
+			"billing_version": bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$billing_version", 0}}, 1}},
+		}},
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return result.MatchedCount == 1, nil
+}
+
+func (s *orderStore) RemoveDiscount(ctx context.Context, restaurantId string, orderId string, version int, discountId string) (bool, error) {
+	result, err := s.c.UpdateOne(ctx, bson.M{
+		"order_id":              orderId,
+		"restaurant_id":         restaurantId,
+		"status":                bson.M{"$nin": bson.A{models.OrderPaid, models.OrderCancelled}},
+		"billing_version":       billingVersion(version),
+		"discounts.discount_id": discountId,
+	}, bson.M{
+		"$pull": bson.M{"discounts": bson.M{"discount_id": discountId}},
+		"$inc":  bson.M{"billing_version": 1},
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount == 1, nil
+}
+
+// billingVersion matches orders at billing version version. Orders from
+// before it existed have none, which counts as 0.
+func billingVersion(version int) interface{} {
+	if version == 0 {
+		return bson.M{"$in": bson.A{0, nil}}
+	}
+
+	return version
+}
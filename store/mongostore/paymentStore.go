@@ -0,0 +1,80 @@
+package mongostore
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/vikas-gouda/go-restraunt-mangement/models"
+	"github.com/vikas-gouda/go-restraunt-mangement/money"
+	"github.com/vikas-gouda/go-restraunt-mangement/store"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type paymentStore struct {
+	c *mongo.Collection
+}
+
+func (s *paymentStore) List(ctx context.Context, restaurantId string) ([]models.Payment, error) {
+	return find[models.Payment](ctx, s.c, bson.M{"restaurant_id": restaurantId}, options.Find().SetSort(byInsertion))
+}
+
+func (s *paymentStore) ListByInvoice(ctx context.Context, restaurantId string, invoiceId string) ([]models.Payment, error) {
+	return find[models.Payment](ctx, s.c, bson.M{"invoice_id": invoiceId, "restaurant_id": restaurantId}, options.Find().SetSort(byInsertion))
+}
+
+func (s *paymentStore) Get(ctx context.Context, restaurantId string, paymentId string) (models.Payment, error) {
+	return findOne[models.Payment](ctx, s.c, bson.M{"payment_id": paymentId, "restaurant_id": restaurantId})
+}
+
+func (s *paymentStore) ListBetween(ctx context.Context, restaurantId string, from time.Time, to time.Time) ([]models.Payment, error) {
+	return find[models.Payment](ctx, s.c, bson.M{
+		"restaurant_id": restaurantId,
+		"created_at":    bson.M{"$gte": from, "$lt": to},
+	}, options.Find().SetSort(byInsertion))
+}
+
+func (s *paymentStore) Create(ctx context.Context, payment models.Payment) error {
+	return insert(ctx, s.c, payment)
+}
+
+func (s *paymentStore) SetProviderStatus(ctx context.Context, provider string, transactionId string, status string) error {
+	result, err := s.c.UpdateOne(ctx, bson.M{"provider": provider, "transaction_id": transactionId}, bson.M{"$set": bson.M{"provider_status": status}})
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return store.ErrNotFound
+	}
+
+	return nil
+}
+
+func (s *paymentStore) Refund(ctx context.Context, restaurantId string, paymentId string, amount money.Money, tip money.Money) (models.Payment, bool, error) {
+	var payment models.Payment
+
+	refunded := bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$refunded.amount", int64(0)}}, amount.Amount}}
+	tipRefunded := bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$tip_refunded.amount", int64(0)}}, tip.Amount}}
+
+	err := s.c.FindOneAndUpdate(ctx, bson.M{
+		"payment_id":    paymentId,
+		"restaurant_id": restaurantId,
+		"$expr": bson.M{"$and": bson.A{
+			bson.M{"$lte": bson.A{refunded, "$amount.amount"}},
+			bson.M{"$lte": bson.A{tipRefunded, "$tip.amount"}},
+		}},
+	}, bson.A{
+		bson.M{"$set": bson.M{
+			"refunded":     bson.M{"amount": refunded, "currency": amount.Currency},
+			"tip_refunded": bson.M{"amount": tipRefunded, "currency": tip.Currency},
+		}},
+	}, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&payment)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return payment, false, nil
+	}
+
+	return payment, err == nil, err
+}
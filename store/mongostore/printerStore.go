@@ -0,0 +1,30 @@
+package mongostore
+
+import (
+	"context"
+
+	"github.com/vikas-gouda/go-restraunt-mangement/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type printerStore struct {
+	c *mongo.Collection
+}
+
+func (s *printerStore) List(ctx context.Context, restaurantId string) ([]models.Printer, error) {
+	return find[models.Printer](ctx, s.c, bson.M{"restaurant_id": restaurantId}, options.Find().SetSort(byInsertion))
+}
+
+func (s *printerStore) Get(ctx context.Context, restaurantId string, printerId string) (models.Printer, error) {
+	return findOne[models.Printer](ctx, s.c, bson.M{"printer_id": printerId, "restaurant_id": restaurantId})
+}
+
+func (s *printerStore) Create(ctx context.Context, printer models.Printer) error {
+	return insert(ctx, s.c, printer)
+}
+
+func (s *printerStore) Update(ctx context.Context, printer models.Printer) error {
+	return replace(ctx, s.c, bson.M{"printer_id": printer.Printer_id, "restaurant_id": printer.Restaurant_id}, printer)
+}
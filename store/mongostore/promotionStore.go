@@ -0,0 +1,65 @@
+package mongostore
+
+import (
+	"context"
+	"time"
+
+	"github.com/vikas-gouda/go-restraunt-mangement/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type promotionStore struct {
+	c *mongo.Collection
+}
+
+func (s *promotionStore) List(ctx context.Context, restaurantId string) ([]models.Promotion, error) {
+	return find[models.Promotion](ctx, s.c, bson.M{"restaurant_id": restaurantId}, options.Find().SetSort(byInsertion))
+}
+
+func (s *promotionStore) Get(ctx context.Context, restaurantId string, promotionId string) (models.Promotion, error) {
+	return findOne[models.Promotion](ctx, s.c, bson.M{"promotion_id": promotionId, "restaurant_id": restaurantId})
+}
+
+func (s *promotionStore) GetByCode(ctx context.Context, restaurantId string, code string) (models.Promotion, error) {
+	return findOne[models.Promotion](ctx, s.c, bson.M{"code": code, "restaurant_id": restaurantId})
+}
+
+func (s *promotionStore) Create(ctx context.Context, promotion models.Promotion) error {
+	return insert(ctx, s.c, promotion)
+}
+
+func (s *promotionStore) Update(ctx context.Context, promotion models.Promotion) error {
+	return replace(ctx, s.c, bson.M{"promotion_id": promotion.Promotion_id, "restaurant_id": promotion.Restaurant_id}, promotion)
+}
+
+// Redeem checks the window and the cap in the filter so that two tills can't
+// both take the last use.
+func (s *promotionStore) Redeem(ctx context.Context, restaurantId string, promotionId string, at time.Time) (bool, error) {
+	result, err := s.c.UpdateOne(ctx, bson.M{
+		"promotion_id":  promotionId,
+		"restaurant_id": restaurantId,
+		"active":        true,
+		"$and": bson.A{
+			bson.M{"$or": bson.A{bson.M{"starts_at": nil}, bson.M{"starts_at": bson.M{"$lte": at}}}},
+			bson.M{"$or": bson.A{bson.M{"ends_at": nil}, bson.M{"ends_at": bson.M{"$gt": at}}}},
+			bson.M{"$or": bson.A{bson.M{"max_uses": 0}, bson.M{"$expr": bson.M{"$lt": bson.A{"$uses", "$max_uses"}}}}},
+		},
+	}, bson.M{"$inc": bson.M{"uses": 1}})
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount == 1, nil
+}
+
+func (s *promotionStore) Unredeem(ctx context.Context, restaurantId string, promotionId string) error {
+	_, err := s.c.UpdateOne(ctx, bson.M{
+		"promotion_id":  promotionId,
+		"restaurant_id": restaurantId,
+		"uses":          bson.M{"$gt": 0},
+	}, bson.M{"$inc": bson.M{"uses": -1}})
+
+	return err
+}
@@ -0,0 +1,34 @@
+package mongostore
+
+import (
+	"context"
+
+	"github.com/vikas-gouda/go-restraunt-mangement/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type restaurantStore struct {
+	c *mongo.Collection
+}
+
+func (s *restaurantStore) List(ctx context.Context) ([]models.Restaurant, error) {
+	return find[models.Restaurant](ctx, s.c, bson.M{}, options.Find().SetSort(byInsertion))
+}
+
+func (s *restaurantStore) Get(ctx context.Context, restaurantId string) (models.Restaurant, error) {
+	return findOne[models.Restaurant](ctx, s.c, bson.M{"restaurant_id": restaurantId})
+}
+
+func (s *restaurantStore) GetByCode(ctx context.Context, code string) (models.Restaurant, error) {
+	return findOne[models.Restaurant](ctx, s.c, bson.M{"code": code})
+}
+
+func (s *restaurantStore) Create(ctx context.Context, restaurant models.Restaurant) error {
+	return insert(ctx, s.c, restaurant)
+}
+
+func (s *restaurantStore) Update(ctx context.Context, restaurant models.Restaurant) error {
+	return replace(ctx, s.c, bson.M{"restaurant_id": restaurant.Restaurant_id}, restaurant)
+}
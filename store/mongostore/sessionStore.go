@@ -0,0 +1,91 @@
+package mongostore
+
+import (
+	"context"
+	"time"
+
+	"github.com/vikas-gouda/go-restraunt-mangement/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type sessionStore struct {
+	c *mongo.Collection
+}
+
+// ensureIndexes lets MongoDB drop sessions on their own once the refresh token
+// behind them has expired.
+func (s *sessionStore) ensureIndexes(ctx context.Context) error {
+	_, err := s.c.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+		{
+			Keys:    bson.D{{Key: "session_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}},
+		},
+	})
+
+	return err
+}
+
+func (s *sessionStore) Create(ctx context.Context, session models.Session) error {
+	return insert(ctx, s.c, session)
+}
+
+func (s *sessionStore) Active(ctx context.Context, sessionId string) (bool, error) {
+	// The TTL monitor only runs once a minute, so expiry is checked here as well.
+	count, err := s.c.CountDocuments(ctx, bson.M{
+		"session_id": sessionId,
+		"expires_at": bson.M{"$gt": time.Now()},
+	})
+
+	return count == 1, err
+}
+
+func (s *sessionStore) Rotate(ctx context.Context, sessionId string, oldHash string, newHash string, expiresAt time.Time) (bool, error) {
+	result, err := s.c.UpdateOne(ctx, bson.M{"session_id": sessionId, "refresh_token_hash": oldHash}, bson.M{
+		"$set": bson.M{
+			"refresh_token_hash": newHash,
+			"last_seen_at":       time.Now().UTC().Truncate(time.Second),
+			"expires_at":         expiresAt,
+		},
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return result.MatchedCount == 1, nil
+}
+
+func (s *sessionStore) List(ctx context.Context, userId string) ([]models.Session, error) {
+	opt := options.Find().SetSort(bson.D{{Key: "last_seen_at", Value: -1}})
+
+	return find[models.Session](ctx, s.c, bson.M{
+		"user_id":    userId,
+		"expires_at": bson.M{"$gt": time.Now()},
+	}, opt)
+}
+
+func (s *sessionStore) Revoke(ctx context.Context, userId string, sessionId string) (bool, error) {
+	result, err := s.c.DeleteOne(ctx, bson.M{"user_id": userId, "session_id": sessionId})
+	if err != nil {
+		return false, err
+	}
+
+	return result.DeletedCount == 1, nil
+}
+
+func (s *sessionStore) RevokeAll(ctx context.Context, userId string) (int64, error) {
+	result, err := s.c.DeleteMany(ctx, bson.M{"user_id": userId})
+	if err != nil {
+		return 0, err
+	}
+
+	return result.DeletedCount, nil
+}
@@ -0,0 +1,69 @@
+package mongostore
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/vikas-gouda/go-restraunt-mangement/models"
+	"github.com/vikas-gouda/go-restraunt-mangement/store"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type shiftStore struct {
+	c *mongo.Collection
+}
+
+// ensureIndexes keeps every user down to one open shift per restaurant.
+func (s *shiftStore) ensureIndexes(ctx context.Context) error {
+	_, err := s.c.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "restaurant_id", Value: 1}, {Key: "user_id", Value: 1}},
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"open": true}).
+				SetName("one_open_shift"),
+		},
+		{
+			Keys: bson.D{{Key: "restaurant_id", Value: 1}, {Key: "clock_in", Value: 1}},
+		},
+	})
+
+	return err
+}
+
+func (s *shiftStore) List(ctx context.Context, restaurantId string, from time.Time, to time.Time) ([]models.Shift, error) {
+	return find[models.Shift](ctx, s.c, bson.M{
+		"restaurant_id": restaurantId,
+		"clock_in":      bson.M{"$lt": to},
+		"$or":           bson.A{bson.M{"open": true}, bson.M{"clock_out": bson.M{"$gt": from}}},
+	}, options.Find().SetSort(bson.D{{Key: "clock_in", Value: 1}}))
+}
+
+func (s *shiftStore) Get(ctx context.Context, restaurantId string, shiftId string) (models.Shift, error) {
+	return findOne[models.Shift](ctx, s.c, bson.M{"shift_id": shiftId, "restaurant_id": restaurantId})
+}
+
+func (s *shiftStore) ClockIn(ctx context.Context, shift models.Shift) error {
+	return insert(ctx, s.c, shift)
+}
+
+func (s *shiftStore) ClockOut(ctx context.Context, restaurantId string, userId string, at time.Time) (models.Shift, error) {
+	var shift models.Shift
+
+	err := s.c.FindOneAndUpdate(ctx, bson.M{
+		"restaurant_id": restaurantId,
+		"user_id":       userId,
+		"open":          true,
+	}, bson.M{"$set": bson.M{"open": false, "clock_out": at, "updated_at": at}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&shift)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return shift, store.ErrNotFound
+	}
+
+	return shift, err
+}
+
+func (s *shiftStore) Update(ctx context.Context, shift models.Shift) error {
+	return replace(ctx, s.c, bson.M{"shift_id": shift.Shift_id, "restaurant_id": shift.Restaurant_id}, shift)
+}
@@ -0,0 +1,30 @@
+package mongostore
+
+import (
+	"context"
+
+	"github.com/vikas-gouda/go-restraunt-mangement/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type tableStore struct {
+	c *mongo.Collection
+}
+
+func (s *tableStore) List(ctx context.Context, restaurantId string) ([]models.Table, error) {
+	return find[models.Table](ctx, s.c, bson.M{"restaurant_id": restaurantId}, options.Find().SetSort(byInsertion))
+}
+
+func (s *tableStore) Get(ctx context.Context, restaurantId string, tableId string) (models.Table, error) {
+	return findOne[models.Table](ctx, s.c, bson.M{"table_id": tableId, "restaurant_id": restaurantId})
+}
+
+func (s *tableStore) Create(ctx context.Context, table models.Table) error {
+	return insert(ctx, s.c, table)
+}
+
+func (s *tableStore) Update(ctx context.Context, table models.Table) error {
+	return replace(ctx, s.c, bson.M{"table_id": table.Table_id, "restaurant_id": table.Restaurant_id}, table)
+}
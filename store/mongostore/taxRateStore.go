@@ -0,0 +1,30 @@
+package mongostore
+
+import (
+	"context"
+
+	"github.com/vikas-gouda/go-restraunt-mangement/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type taxRateStore struct {
+	c *mongo.Collection
+}
+
+func (s *taxRateStore) List(ctx context.Context, restaurantId string) ([]models.TaxRate, error) {
+	return find[models.TaxRate](ctx, s.c, bson.M{"restaurant_id": restaurantId}, options.Find().SetSort(byInsertion))
+}
+
+func (s *taxRateStore) Get(ctx context.Context, restaurantId string, taxRateId string) (models.TaxRate, error) {
+	return findOne[models.TaxRate](ctx, s.c, bson.M{"tax_rate_id": taxRateId, "restaurant_id": restaurantId})
+}
+
+func (s *taxRateStore) Create(ctx context.Context, taxRate models.TaxRate) error {
+	return insert(ctx, s.c, taxRate)
+}
+
+func (s *taxRateStore) Update(ctx context.Context, taxRate models.TaxRate) error {
+	return replace(ctx, s.c, bson.M{"tax_rate_id": taxRate.Tax_rate_id, "restaurant_id": taxRate.Restaurant_id}, taxRate)
+}
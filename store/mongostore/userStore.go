@@ -0,0 +1,80 @@
+package mongostore
+
+import (
+	"context"
+	"errors"
+
+	"github.com/vikas-gouda/go-restraunt-mangement/models"
+	"github.com/vikas-gouda/go-restraunt-mangement/store"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type userStore struct {
+	c *mongo.Collection
+	// bootstrap holds one document once the first user was created, its
+	// unique _id lets only one sign up win.
+	bootstrap *mongo.Collection
+}
+
+func (s *userStore) Count(ctx context.Context) (int64, error) {
+	return s.c.CountDocuments(ctx, bson.M{})
+}
+
+func (s *userStore) Get(ctx context.Context, userId string) (models.User, error) {
+	return findOne[models.User](ctx, s.c, bson.M{"user_id": userId})
+}
+
+func (s *userStore) GetByEmail(ctx context.Context, email string) (models.User, error) {
+	return findOne[models.User](ctx, s.c, bson.M{"email": email})
+}
+
+func (s *userStore) GetByPhone(ctx context.Context, phone string) (models.User, error) {
+	return findOne[models.User](ctx, s.c, bson.M{"phone": phone})
+}
+
+func (s *userStore) List(ctx context.Context, restaurantId string, start int, limit int) (int64, []models.User, error) {
+	filter := bson.M{"restaurant_id": restaurantId}
+
+	total, err := s.c.CountDocuments(ctx, filter)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	opt := options.Find().SetSort(byInsertion).SetSkip(int64(start)).SetLimit(int64(limit))
+	users, err := find[models.User](ctx, s.c, filter, opt)
+
+	return total, users, err
+}
+
+func (s *userStore) Create(ctx context.Context, user models.User) error {
+	return insert(ctx, s.c, user)
+}
+
+func (s *userStore) CreateFirst(ctx context.Context, user models.User) (bool, error) {
+	count, err := s.c.CountDocuments(ctx, bson.M{}, options.Count().SetLimit(1))
+	if err != nil || count > 0 {
+		return false, err
+	}
+
+	err = insert(ctx, s.bootstrap, bson.M{"_id": "first_user", "user_id": user.User_id, "created_at": user.Created_at})
+	if errors.Is(err, store.ErrDuplicate) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	if err := insert(ctx, s.c, user); err != nil {
+		s.bootstrap.DeleteOne(context.Background(), bson.M{"_id": "first_user"})
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (s *userStore) Update(ctx context.Context, user models.User) error {
+	return replace(ctx, s.c, bson.M{"user_id": user.User_id}, user)
+}
@@ -0,0 +1,245 @@
+// Package store defines the persistence boundary of the service. Handlers only
+// ever talk to these interfaces; mongostore backs them with MongoDB and
+// memstore keeps everything in process for local runs and handler tests.
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/vikas-gouda/go-restraunt-mangement/models"
+	"github.com/vikas-gouda/go-restraunt-mangement/money"
+)
+
+var (
+	ErrNotFound  = errors.New("store: not found")
+	ErrDuplicate = errors.New("store: duplicate key")
+	// ErrNoTransactions is returned for writes that must not half happen
+	// when the database can't run transactions.
+	ErrNoTransactions = errors.New("store: transactions are not supported")
+)
+
+// Store bundles one repository per aggregate so it can be handed to the
+// routers in one piece.
+type Store struct {
+	Restaurants RestaurantStore
+	Users       UserStore
+	Sessions    SessionStore
+	Foods       FoodStore
+	Menus       MenuStore
+	Tables      TableStore
+	Orders      OrderStore
+	OrderItems  OrderItemStore
+	Invoices    InvoiceStore
+	TaxRates    TaxRateStore
+	Promotions  PromotionStore
+	Payments    PaymentStore
+	CreditNotes CreditNoteStore
+	Shifts      ShiftStore
+	Printers    PrinterStore
+	Health      HealthStore
+	Idempotency IdempotencyStore
+}
+
+type IdempotencyStore interface {
+	// Reserve stores the record unless one with the same restaurant and key
+	// exists and has not expired. It reports whether it did, and otherwise
+	// returns the existing record.
+	Reserve(ctx context.Context, record models.IdempotencyRecord) (models.IdempotencyRecord, bool, error)
+	// Complete saves the response on a reserved record.
+	Complete(ctx context.Context, record models.IdempotencyRecord) error
+	// Release drops a reservation so the request can be tried again.
+	Release(ctx context.Context, restaurantId string, key string) error
+}
+
+// HealthStore answers the readiness probe.
+type HealthStore interface {
+	Ping(ctx context.Context) error
+	// CheckIndexes fails when an index the repositories rely on is missing.
+	CheckIndexes(ctx context.Context) error
+}
+
+type RestaurantStore interface {
+	List(ctx context.Context) ([]models.Restaurant, error)
+	Get(ctx context.Context, restaurantId string) (models.Restaurant, error)
+	GetByCode(ctx context.Context, code string) (models.Restaurant, error)
+	Create(ctx context.Context, restaurant models.Restaurant) error
+	Update(ctx context.Context, restaurant models.Restaurant) error
+}
+
+type UserStore interface {
+	Count(ctx context.Context) (int64, error)
+	Get(ctx context.Context, userId string) (models.User, error)
+	GetByEmail(ctx context.Context, email string) (models.User, error)
+	GetByPhone(ctx context.Context, phone string) (models.User, error)
+	List(ctx context.Context, restaurantId string, start int, limit int) (int64, []models.User, error)
+	Create(ctx context.Context, user models.User) error
+	// CreateFirst creates the user only when there is no user at all yet, and
+	// reports whether it did. Of two at the same time only one gets in.
+	CreateFirst(ctx context.Context, user models.User) (bool, error)
+	Update(ctx context.Context, user models.User) error
+}
+
+type SessionStore interface {
+	Create(ctx context.Context, session models.Session) error
+	// Active reports whether the session exists and has not expired yet.
+	Active(ctx context.Context, sessionId string) (bool, error)
+	// Rotate replaces the refresh token hash only when oldHash is still the
+	// current one and reports whether it did.
+	Rotate(ctx context.Context, sessionId string, oldHash string, newHash string, expiresAt time.Time) (bool, error)
+	List(ctx context.Context, userId string) ([]models.Session, error)
+	Revoke(ctx context.Context, userId string, sessionId string) (bool, error)
+	RevokeAll(ctx context.Context, userId string) (int64, error)
+}
+
+type FoodStore interface {
+	List(ctx context.Context, restaurantId string, start int, limit int) (int64, []models.Food, error)
+	Get(ctx context.Context, restaurantId string, foodId string) (models.Food, error)
+	Create(ctx context.Context, food models.Food) error
+	Update(ctx context.Context, food models.Food) error
+}
+
+type MenuStore interface {
+	List(ctx context.Context, restaurantId string) ([]models.Menu, error)
+	Get(ctx context.Context, restaurantId string, menuId string) (models.Menu, error)
+	Create(ctx context.Context, menu models.Menu) error
+	Update(ctx context.Context, menu models.Menu) error
+}
+
+type TableStore interface {
+	List(ctx context.Context, restaurantId string) ([]models.Table, error)
+	Get(ctx context.Context, restaurantId string, tableId string) (models.Table, error)
+	Create(ctx context.Context, table models.Table) error
+	Update(ctx context.Context, table models.Table) error
+}
+
+type OrderStore interface {
+	List(ctx context.Context, restaurantId string) ([]models.Order, error)
+	Get(ctx context.Context, restaurantId string, orderId string) (models.Order, error)
+	Create(ctx context.Context, order models.Order) error
+	// CreateWithItems writes the order together with its items; either all
+	// of them end up stored or none.
+	CreateWithItems(ctx context.Context, order models.Order, orderItems []models.OrderItem) error
+	Update(ctx context.Context, order models.Order) error
+	// Transition moves the order from transition.From to transition.To and
+	// records it in the history. It reports false, and changes nothing, when
+	// the order is no longer in transition.From.
+	Transition(ctx context.Context, restaurantId string, orderId string, transition models.OrderTransition) (bool, error)
+	// AddDiscount puts a discount on an order that is still open for changes,
+	// at billing version version and doesn't carry the same promotion yet,
+	// and moves the version on. It reports whether it did.
+	AddDiscount(ctx context.Context, restaurantId string, orderId string, version int, discount models.OrderDiscount) (bool, error)
+	// RemoveDiscount takes a discount off an order that is not closed and
+	// still at billing version version, and moves the version on. It
+	// reports whether it did.
+	RemoveDiscount(ctx context.Context, restaurantId string, orderId string, version int, discountId string) (bool, error)
+}
+
+type OrderItemStore interface {
+	List(ctx context.Context, restaurantId string) ([]models.OrderItem, error)
+	ListByOrder(ctx context.Context, restaurantId string, orderId string) ([]models.OrderItem, error)
+	Get(ctx context.Context, restaurantId string, orderItemId string) (models.OrderItem, error)
+	CreateMany(ctx context.Context, orderItems []models.OrderItem) error
+	Update(ctx context.Context, orderItem models.OrderItem) error
+	// Transition moves the item from one status to another and stamps the
+	// time. It reports false when the item is no longer in from.
+	Transition(ctx context.Context, restaurantId string, orderItemId string, from string, to string, at time.Time) (bool, error)
+}
+
+type TaxRateStore interface {
+	List(ctx context.Context, restaurantId string) ([]models.TaxRate, error)
+	Get(ctx context.Context, restaurantId string, taxRateId string) (models.TaxRate, error)
+	Create(ctx context.Context, taxRate models.TaxRate) error
+	Update(ctx context.Context, taxRate models.TaxRate) error
+}
+
+type PrinterStore interface {
+	List(ctx context.Context, restaurantId string) ([]models.Printer, error)
+	Get(ctx context.Context, restaurantId string, printerId string) (models.Printer, error)
+	Create(ctx context.Context, printer models.Printer) error
+	Update(ctx context.Context, printer models.Printer) error
+}
+
+type PromotionStore interface {
+	List(ctx context.Context, restaurantId string) ([]models.Promotion, error)
+	Get(ctx context.Context, restaurantId string, promotionId string) (models.Promotion, error)
+	GetByCode(ctx context.Context, restaurantId string, code string) (models.Promotion, error)
+	Create(ctx context.Context, promotion models.Promotion) error
+	Update(ctx context.Context, promotion models.Promotion) error
+	// Redeem counts one more use of the promotion, provided it is active at
+	// at and not used up. It reports whether it did.
+	Redeem(ctx context.Context, restaurantId string, promotionId string, at time.Time) (bool, error)
+	// Unredeem gives back a use taken by Redeem.
+	Unredeem(ctx context.Context, restaurantId string, promotionId string) error
+}
+
+type InvoiceStore interface {
+	List(ctx context.Context, restaurantId string) ([]models.Invoice, error)
+	ListByOrder(ctx context.Context, restaurantId string, orderId string) ([]models.Invoice, error)
+	Get(ctx context.Context, restaurantId string, invoiceId string) (models.Invoice, error)
+	Create(ctx context.Context, invoice models.Invoice) error
+	Update(ctx context.Context, invoice models.Invoice) error
+	// Replace voids the invoices in voidIds and stores invoices in their
+	// place, all for one order whose billing version is still version. The
+	// new invoices take the next numbers of numbering in one step with being
+	// stored, and are returned numbered. It reports false, and changes
+	// nothing, when the order was billed differently in the meantime or one
+	// of the invoices was paid or voided. Stores that can't do all of it in
+	// one step refuse with ErrNoTransactions rather than leave a gap.
+	Replace(ctx context.Context, restaurantId string, orderId string, version int, voidIds []string, at time.Time, invoices []models.Invoice, numbering models.InvoiceNumbering) ([]models.Invoice, bool, error)
+	// Void cancels an invoice that nothing was paid or credited on, keeping
+	// it and its number. It reports false when the invoice is settled or
+	// voided already.
+	Void(ctx context.Context, restaurantId string, invoiceId string, at time.Time, reason string, voidedBy string) (models.Invoice, bool, error)
+	// AddPaid adds amount to what was paid on an invoice that is not voided
+	// and returns the invoice as it is now. It reports false when the
+	// invoice is voided.
+	AddPaid(ctx context.Context, restaurantId string, invoiceId string, amount money.Money) (models.Invoice, bool, error)
+	// SetPaymentStatus stores the status and method worked out for paid,
+	// unless another payment changed what was paid in the meantime.
+	SetPaymentStatus(ctx context.Context, restaurantId string, invoiceId string, paid money.Money, status string, method string) error
+	// AddRefund takes refunded off what was paid and adds credited to what
+	// was credited, and returns the invoice as it is now.
+	AddRefund(ctx context.Context, restaurantId string, invoiceId string, refunded money.Money, credited money.Money) (models.Invoice, error)
+}
+
+type PaymentStore interface {
+	List(ctx context.Context, restaurantId string) ([]models.Payment, error)
+	ListByInvoice(ctx context.Context, restaurantId string, invoiceId string) ([]models.Payment, error)
+	Get(ctx context.Context, restaurantId string, paymentId string) (models.Payment, error)
+	// ListBetween lists the payments taken from from up to, not including, to.
+	ListBetween(ctx context.Context, restaurantId string, from time.Time, to time.Time) ([]models.Payment, error)
+	// Payments are never changed or deleted once taken, only the status the
+	// payment provider reports for them follows along.
+	Create(ctx context.Context, payment models.Payment) error
+	// SetProviderStatus records the status a provider reported for one of its
+	// transactions. It returns ErrNotFound for unknown transactions.
+	SetProviderStatus(ctx context.Context, provider string, transactionId string, status string) error
+	// Refund adds amount and tip to what was refunded of a payment, unless
+	// that would be more than was paid. It reports whether it did and
+	// returns the payment as it is now.
+	Refund(ctx context.Context, restaurantId string, paymentId string, amount money.Money, tip money.Money) (models.Payment, bool, error)
+}
+
+type CreditNoteStore interface {
+	// List lists the credit notes made from from up to, not including, to.
+	List(ctx context.Context, restaurantId string, from time.Time, to time.Time) ([]models.CreditNote, error)
+	ListByInvoice(ctx context.Context, restaurantId string, invoiceId string) ([]models.CreditNote, error)
+	Get(ctx context.Context, restaurantId string, creditNoteId string) (models.CreditNote, error)
+	// Credit notes are never changed or deleted.
+	Create(ctx context.Context, creditNote models.CreditNote) error
+}
+
+type ShiftStore interface {
+	// List lists the shifts that overlap from up to to, open ones included.
+	List(ctx context.Context, restaurantId string, from time.Time, to time.Time) ([]models.Shift, error)
+	Get(ctx context.Context, restaurantId string, shiftId string) (models.Shift, error)
+	// ClockIn opens a shift. It returns ErrDuplicate when the user has an
+	// open shift already.
+	ClockIn(ctx context.Context, shift models.Shift) error
+	// ClockOut closes the open shift of a user and returns it. It returns
+	// ErrNotFound when there is none.
+	ClockOut(ctx context.Context, restaurantId string, userId string, at time.Time) (models.Shift, error)
+	Update(ctx context.Context, shift models.Shift) error
+}
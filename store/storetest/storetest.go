@@ -0,0 +1,498 @@
+// Package storetest checks that a store implementation behaves the way the
+// handlers rely on. Every implementation runs the same tests.
+package storetest
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vikas-gouda/go-restraunt-mangement/models"
+	"github.com/vikas-gouda/go-restraunt-mangement/money"
+	"github.com/vikas-gouda/go-restraunt-mangement/store"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Idempotency checks that keys are reserved once per restaurant until they
+// expire, and can be reserved again after that or after a release.
+func Idempotency(t *testing.T, records store.IdempotencyStore) {
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	record := func(restaurantId string, key string, expiresAt time.Time) models.IdempotencyRecord {
+		return models.IdempotencyRecord{
+			ID:            primitive.NewObjectID(),
+			Key:           key,
+			Restaurant_id: restaurantId,
+			Request_hash:  primitive.NewObjectID().Hex(),
+			Created_at:    now,
+			Expires_at:    expiresAt,
+		}
+	}
+
+	for _, test := range []struct {
+		name     string
+		first    models.IdempotencyRecord
+		second   models.IdempotencyRecord
+		release  bool
+		reserved bool
+	}{
+		{"same key", record("r1", "k1", now.Add(time.Hour)), record("r1", "k1", now.Add(time.Hour)), false, false},
+		{"other restaurant", record("r1", "k2", now.Add(time.Hour)), record("r2", "k2", now.Add(time.Hour)), false, true},
+		{"expired", record("r1", "k3", now.Add(-time.Second)), record("r1", "k3", now.Add(time.Hour)), false, true},
+		{"released", record("r1", "k4", now.Add(time.Hour)), record("r1", "k4", now.Add(time.Hour)), true, true},
+	} {
+		if _, reserved, err := records.Reserve(ctx, test.first); err != nil || !reserved {
+			t.Fatalf("%s: first reserve = %v, %v", test.name, reserved, err)
+		}
+
+		if test.release {
+			if err := records.Release(ctx, test.first.Restaurant_id, test.first.Key); err != nil {
+				t.Fatalf("%s: release: %v", test.name, err)
+			}
+		}
+
+		existing, reserved, err := records.Reserve(ctx, test.second)
+		if err != nil {
+			t.Fatalf("%s: second reserve: %v", test.name, err)
+		}
+
+		if reserved != test.reserved {
+			t.Errorf("%s: second reserve = %v, want %v", test.name, reserved, test.reserved)
+		}
+
+		if want := test.second; !reserved {
+			want = test.first
+			if existing.Request_hash != want.Request_hash {
+				t.Errorf("%s: got back the record of %s, want the first one", test.name, existing.Request_hash)
+			}
+			continue
+		}
+
+		// What was reserved can be completed.
+		test.second.Status_code = 201
+		if err := records.Complete(ctx, test.second); err != nil {
+			t.Errorf("%s: complete: %v", test.name, err)
+		}
+
+		existing, reserved, _ = records.Reserve(ctx, record(test.second.Restaurant_id, test.second.Key, now.Add(time.Hour)))
+		if reserved || existing.Status_code != 201 || existing.Request_hash != test.second.Request_hash {
+			t.Errorf("%s: after completing got %+v, %v; want the completed record", test.name, existing, reserved)
+		}
+	}
+}
+
+// FirstUser checks that of many users created as the first one at the same
+// time exactly one is.
+func FirstUser(t *testing.T, users store.UserStore) {
+	ctx := context.Background()
+
+	const n = 10
+	created := make(chan bool, n)
+	for i := 0; i < n; i++ {
+		go func() {
+			id := primitive.NewObjectID()
+			ok, err := users.CreateFirst(ctx, models.User{ID: id, User_id: id.Hex(), Email: id.Hex() + "@example.com", Role: models.RoleOwner})
+			if err != nil {
+				t.Error(err)
+			}
+			created <- ok
+		}()
+	}
+
+	first := 0
+	for i := 0; i < n; i++ {
+		if <-created {
+			first++
+		}
+	}
+
+	if count, _ := users.Count(ctx); first != 1 || count != 1 {
+		t.Errorf("%d users were created as the first, %d stored; want 1", first, count)
+	}
+}
+
+// newOrder stores an open order of restaurantId.
+func newOrder(t *testing.T, s *store.Store, restaurantId string) models.Order {
+	t.Helper()
+
+	tableId := primitive.NewObjectID().Hex()
+	order := models.Order{ID: primitive.NewObjectID(), Table_id: &tableId, Status: models.OrderOpen, Discounts: []models.OrderDiscount{}, Restaurant_id: restaurantId}
+	order.Order_id = order.ID.Hex()
+	if err := s.Orders.Create(context.Background(), order); err != nil {
+		t.Fatal(err)
+	}
+
+	return order
+}
+
+// newInvoice is an unpaid invoice for order, not stored yet.
+func newInvoice(order models.Order) models.Invoice {
+	invoice := models.Invoice{
+		ID:             primitive.NewObjectID(),
+		Order_id:       &order.Order_id,
+		Payment_status: models.InvoicePending,
+		Split_mode:     models.SplitFull,
+		Restaurant_id:  order.Restaurant_id,
+	}
+	invoice.Invoice_id = invoice.ID.Hex()
+
+	return invoice
+}
+
+// BillingVersion checks that invoices and discounts only change an order at
+// the billing version they were worked out for, so two tills splitting or
+// merging the same order can't both go through.
+func BillingVersion(t *testing.T, s *store.Store) {
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+	numbering := models.InvoiceNumbering{Format: "{code}-{seq}", Digits: 6, Code: "TEST", Series: "billing"}
+
+	// billed is an order with one invoice at billing version 1.
+	billed := func() (models.Order, models.Invoice) {
+		order := newOrder(t, s, "r1")
+		invoices, replaced, err := s.Invoices.Replace(ctx, "r1", order.Order_id, 0, nil, now, []models.Invoice{newInvoice(order)}, numbering)
+		if err != nil || !replaced {
+			t.Fatalf("billing the order: %v, %v", replaced, err)
+		}
+
+		return order, invoices[0]
+	}
+
+	for _, test := range []struct {
+		name    string
+		change  func(order models.Order, invoice models.Invoice) (bool, error)
+		changed bool
+	}{
+		{
+			name: "split at the current version",
+			change: func(order models.Order, invoice models.Invoice) (bool, error) {
+				_, replaced, err := s.Invoices.Replace(ctx, "r1", order.Order_id, 1, []string{invoice.Invoice_id}, now, []models.Invoice{newInvoice(order), newInvoice(order)}, numbering)
+				return replaced, err
+			},
+			changed: true,
+		},
+		{
+			name: "split at a stale version",
+			change: func(order models.Order, invoice models.Invoice) (bool, error) {
+				_, replaced, err := s.Invoices.Replace(ctx, "r1", order.Order_id, 0, []string{invoice.Invoice_id}, now, []models.Invoice{newInvoice(order), newInvoice(order)}, numbering)
+				return replaced, err
+			},
+		},
+		{
+			name: "merge of a paid invoice",
+			change: func(order models.Order, invoice models.Invoice) (bool, error) {
+				if _, _, err := s.Invoices.AddPaid(ctx, "r1", invoice.Invoice_id, money.New(100, "USD")); err != nil {
+					return false, err
+				}
+
+				_, replaced, err := s.Invoices.Replace(ctx, "r1", order.Order_id, 1, []string{invoice.Invoice_id}, now, []models.Invoice{newInvoice(order)}, numbering)
+				return replaced, err
+			},
+		},
+		{
+			name: "merge in another restaurant",
+			change: func(order models.Order, invoice models.Invoice) (bool, error) {
+				_, replaced, err := s.Invoices.Replace(ctx, "r2", order.Order_id, 1, []string{invoice.Invoice_id}, now, []models.Invoice{newInvoice(order)}, numbering)
+				return replaced, err
+			},
+		},
+		{
+			name: "discount at the current version",
+			change: func(order models.Order, invoice models.Invoice) (bool, error) {
+				return s.Orders.AddDiscount(ctx, "r1", order.Order_id, 1, models.OrderDiscount{Discount_id: "d1"})
+			},
+			changed: true,
+		},
+		{
+			name: "discount at a stale version",
+			change: func(order models.Order, invoice models.Invoice) (bool, error) {
+				return s.Orders.AddDiscount(ctx, "r1", order.Order_id, 0, models.OrderDiscount{Discount_id: "d1"})
+			},
+		},
+		{
+			name: "discount removed at a stale version",
+			change: func(order models.Order, invoice models.Invoice) (bool, error) {
+				if added, err := s.Orders.AddDiscount(ctx, "r1", order.Order_id, 1, models.OrderDiscount{Discount_id: "d1"}); err != nil || !added {
+					return false, err
+				}
+
+				return s.Orders.RemoveDiscount(ctx, "r1", order.Order_id, 1, "d1")
+			},
+		},
+	} {
+		order, invoice := billed()
+
+		changed, err := test.change(order, invoice)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+
+		if changed != test.changed {
+			t.Errorf("%s: changed = %v, want %v", test.name, changed, test.changed)
+		}
+
+		invoices, err := s.Invoices.ListByOrder(ctx, "r1", order.Order_id)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		voided := 0
+		for _, stored := range invoices {
+			if stored.Voided() {
+				voided++
+			}
+		}
+
+		if !test.changed && (len(invoices) != 1 || voided != 0) {
+			t.Errorf("%s: order has %d invoices, %d voided, after a refused change", test.name, len(invoices), voided)
+		}
+	}
+
+	// Of two tills splitting the same order at once only one gets through.
+	order, invoice := billed()
+
+	const tills = 5
+	replaced := make(chan bool, tills)
+	for i := 0; i < tills; i++ {
+		go func() {
+			_, ok, err := s.Invoices.Replace(ctx, "r1", order.Order_id, 1, []string{invoice.Invoice_id}, now, []models.Invoice{newInvoice(order), newInvoice(order)}, numbering)
+			if err != nil {
+				t.Error(err)
+			}
+			replaced <- ok
+		}()
+	}
+
+	through := 0
+	for i := 0; i < tills; i++ {
+		if <-replaced {
+			through++
+		}
+	}
+
+	invoices, _ := s.Invoices.ListByOrder(ctx, "r1", order.Order_id)
+	if through != 1 || len(invoices) != 3 {
+		t.Errorf("%d of %d splits went through leaving %d invoices, want 1 and 3", through, tills, len(invoices))
+	}
+
+	if stored, _ := s.Orders.Get(ctx, "r1", order.Order_id); stored.Billing_version != 2 {
+		t.Errorf("billing version is %d after one split, want 2", stored.Billing_version)
+	}
+}
+
+// InvoiceNumbering checks that invoices are numbered without gaps or
+// duplicates per restaurant and series, even when stored at the same time
+// or when storing them is refused.
+func InvoiceNumbering(t *testing.T, s *store.Store) {
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	series := func(year int) models.InvoiceNumbering {
+		return models.InvoiceNumbering{Format: "{code}-{fy}-{seq}", Digits: 4, Code: "CAFE", Fiscal_year: year, Series: strconv.Itoa(year)}
+	}
+
+	for _, test := range []struct {
+		name       string
+		restaurant string
+		numbering  models.InvoiceNumbering
+		version    int
+		invoices   int
+		numbers    []string
+	}{
+		{"first invoice", "r1", series(2026), 0, 1, []string{"CAFE-2026-0001"}},
+		{"a split takes the next numbers", "r1", series(2026), 0, 3, []string{"CAFE-2026-0002", "CAFE-2026-0003", "CAFE-2026-0004"}},
+		{"refused at a stale version", "r1", series(2026), 1, 2, nil},
+		{"no gap after a refusal", "r1", series(2026), 0, 1, []string{"CAFE-2026-0005"}},
+		{"other restaurant counts apart", "r2", series(2026), 0, 1, []string{"CAFE-2026-0001"}},
+		{"new fiscal year starts over", "r1", series(2027), 0, 1, []string{"CAFE-2027-0001"}},
+	} {
+		order := newOrder(t, s, test.restaurant)
+
+		invoices := []models.Invoice{}
+		for i := 0; i < test.invoices; i++ {
+			invoices = append(invoices, newInvoice(order))
+		}
+
+		numbered, replaced, err := s.Invoices.Replace(ctx, test.restaurant, order.Order_id, test.version, nil, now, invoices, test.numbering)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+
+		if replaced != (test.numbers != nil) {
+			t.Errorf("%s: replaced = %v", test.name, replaced)
+			continue
+		}
+
+		numbers := []string{}
+		for _, invoice := range numbered {
+			numbers = append(numbers, invoice.Invoice_number)
+
+			stored, err := s.Invoices.Get(ctx, test.restaurant, invoice.Invoice_id)
+			if err != nil || stored.Invoice_number != invoice.Invoice_number || stored.Fiscal_year != test.numbering.Series {
+				t.Errorf("%s: stored %q in %q, want %q in %q: %v", test.name, stored.Invoice_number, stored.Fiscal_year, invoice.Invoice_number, test.numbering.Series, err)
+			}
+		}
+
+		if test.numbers != nil && strings.Join(numbers, " ") != strings.Join(test.numbers, " ") {
+			t.Errorf("%s: numbered %v, want %v", test.name, numbers, test.numbers)
+		}
+	}
+
+	// Tills invoicing at the same time share one counter.
+	const tills = 10
+	sequences := make(chan int64, tills)
+	for i := 0; i < tills; i++ {
+		go func() {
+			order := newOrder(t, s, "r3")
+			numbered, _, err := s.Invoices.Replace(ctx, "r3", order.Order_id, 0, nil, now, []models.Invoice{newInvoice(order)}, series(2026))
+			if err != nil || len(numbered) != 1 {
+				t.Error(err)
+				sequences <- 0
+				return
+			}
+			sequences <- numbered[0].Sequence
+		}()
+	}
+
+	taken := map[int64]bool{}
+	for i := 0; i < tills; i++ {
+		taken[<-sequences] = true
+	}
+
+	for seq := int64(1); seq <= tills; seq++ {
+		if !taken[seq] {
+			t.Errorf("invoicing at the same time took %v, want every number from 1 to %d once", taken, tills)
+			break
+		}
+	}
+}
+
+// Sessions checks that sessions end when they expire or are revoked, and
+// that a refresh token hash can only be rotated once.
+func Sessions(t *testing.T, sessions store.SessionStore) {
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	session := func(userId string, hash string, expiresAt time.Time) models.Session {
+		t.Helper()
+
+		id := primitive.NewObjectID()
+		s := models.Session{ID: id, Session_id: id.Hex(), User_id: userId, Refresh_token_hash: hash, Created_at: now, Last_seen_at: now, Expires_at: expiresAt}
+		if err := sessions.Create(ctx, s); err != nil {
+			t.Fatal(err)
+		}
+
+		return s
+	}
+
+	for _, test := range []struct {
+		name   string
+		change func(s models.Session) (bool, error)
+		done   bool
+		active bool
+	}{
+		{
+			name: "rotated from the current hash",
+			change: func(s models.Session) (bool, error) {
+				return sessions.Rotate(ctx, s.Session_id, "h1", "h2", now.Add(time.Hour))
+			},
+			done:   true,
+			active: true,
+		},
+		{
+			name: "rotated from a stale hash",
+			change: func(s models.Session) (bool, error) {
+				return sessions.Rotate(ctx, s.Session_id, "h0", "h2", now.Add(time.Hour))
+			},
+			active: true,
+		},
+		{
+			name:   "revoked",
+			change: func(s models.Session) (bool, error) { return sessions.Revoke(ctx, s.User_id, s.Session_id) },
+			done:   true,
+		},
+		{
+			name:   "revoked as another user",
+			change: func(s models.Session) (bool, error) { return sessions.Revoke(ctx, "u2", s.Session_id) },
+			active: true,
+		},
+		{
+			name: "all of the user's revoked",
+			change: func(s models.Session) (bool, error) {
+				revoked, err := sessions.RevokeAll(ctx, s.User_id)
+				return revoked > 0, err
+			},
+			done: true,
+		},
+	} {
+		s := session("u1", "h1", now.Add(time.Hour))
+
+		done, err := test.change(s)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+
+		active, err := sessions.Active(ctx, s.Session_id)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+
+		if done != test.done || active != test.active {
+			t.Errorf("%s: done %v and active %v, want %v and %v", test.name, done, active, test.done, test.active)
+		}
+	}
+
+	expired := session("u3", "h1", now.Add(-time.Second))
+	if active, _ := sessions.Active(ctx, expired.Session_id); active {
+		t.Error("an expired session is active")
+	}
+
+	if active, _ := sessions.Active(ctx, primitive.NewObjectID().Hex()); active {
+		t.Error("an unknown session is active")
+	}
+
+	live := session("u3", "h1", now.Add(time.Hour))
+	other := session("u4", "h1", now.Add(time.Hour))
+
+	if listed, err := sessions.List(ctx, "u3"); err != nil || len(listed) != 1 || listed[0].Session_id != live.Session_id {
+		t.Errorf("u3 has sessions %+v, want only the live one: %v", listed, err)
+	}
+
+	if revoked, _ := sessions.RevokeAll(ctx, "u3"); revoked != 2 {
+		t.Errorf("revoked %d sessions of u3, want both", revoked)
+	}
+
+	if active, _ := sessions.Active(ctx, other.Session_id); !active {
+		t.Error("revoking u3's sessions ended u4's")
+	}
+
+	// Two refreshes with the same token: only one of them rotates it.
+	raced := session("u5", "h1", now.Add(time.Hour))
+
+	const refreshes = 10
+	rotated := make(chan bool, refreshes)
+	for i := 0; i < refreshes; i++ {
+		go func(i int) {
+			ok, err := sessions.Rotate(ctx, raced.Session_id, "h1", "h2-"+strconv.Itoa(i), now.Add(time.Hour))
+			if err != nil {
+				t.Error(err)
+			}
+			rotated <- ok
+		}(i)
+	}
+
+	through := 0
+	for i := 0; i < refreshes; i++ {
+		if <-rotated {
+			through++
+		}
+	}
+
+	if through != 1 {
+		t.Errorf("%d of %d refreshes with the same token rotated it, want 1", through, refreshes)
+	}
+}
@@ -0,0 +1,29 @@
+// Package version holds the build information. The values are set at build
+// time, e.g.
+//
+//	go build -ldflags "-X github.com/vikas-gouda/go-restraunt-mangement/version.Commit=$(git rev-parse HEAD)"
+package version
+
+import "runtime"
+
+var (
+	Version   = "dev"
+	Commit    = "unknown"
+	BuildTime = "unknown"
+)
+
+type Info struct {
+	Version    string `json:"version"`
+	Commit     string `json:"commit"`
+	Build_time string `json:"build_time"`
+	Go_version string `json:"go_version"`
+}
+
+func Get() Info {
+	return Info{
+		Version:    Version,
+		Commit:     Commit,
+		Build_time: BuildTime,
+		Go_version: runtime.Version(),
+	}
+}